@@ -8,23 +8,47 @@ import (
 	"path/filepath"
 	goruntime "runtime"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx      context.Context
-	scanner  *scanner.Scanner
-	exporter *scanner.Exporter
+	ctx       context.Context
+	scanner   *scanner.Scanner
+	exporter  *scanner.Exporter
+	previewer *scanner.Previewer
+	sessions  *sessionStore
+
+	// 正在扫描的结果会话，扫描中的批量结果追加到该会话
+	activeMu      sync.Mutex
+	activeID      string
+	activeSession *resultSession
+
+	// 正在进行的导出，用于取消
+	exportMu     sync.Mutex
+	exportCancel context.CancelFunc
+
+	// 定时扫描调度器，关闭窗口后继续在后台运行
+	scheduler          *scanner.Scheduler
+	quitting           atomic.Bool
+	backgroundNotified atomic.Bool
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{
-		scanner:  scanner.NewScanner(),
-		exporter: scanner.NewExporter(),
+	a := &App{
+		scanner:   scanner.NewScanner(),
+		exporter:  scanner.NewExporter(),
+		previewer: scanner.NewPreviewer(""),
+		sessions:  newSessionStore(),
 	}
+	a.scheduler = scanner.NewScheduler(a.onScanAlert)
+	return a
 }
 
 // startup is called when the app starts. The context is saved
@@ -36,6 +60,29 @@ func (a *App) startup(ctx context.Context) {
 	a.scanner.SetProgressCallback(func(progress scanner.ScanProgress) {
 		wailsRuntime.EventsEmit(ctx, "scan-progress", progress)
 	})
+	// 扫描过程中分批推送找到的文件，同时追加到结果会话
+	a.scanner.SetBatchCallback(func(files []scanner.FileInfo) {
+		a.activeMu.Lock()
+		id, sess := a.activeID, a.activeSession
+		a.activeMu.Unlock()
+		if sess != nil {
+			sess.append(files)
+		}
+		wailsRuntime.EventsEmit(ctx, "scan-batch", ScanBatch{SessionID: id, Files: files})
+	})
+
+	// 推送导出进度
+	a.exporter.SetProgressCallback(func(progress scanner.ExportProgress) {
+		wailsRuntime.EventsEmit(ctx, "export-progress", progress)
+	})
+
+	// 清理30天未使用的预览缓存
+	go a.previewer.Prune(30 * 24 * time.Hour)
+	// 定期删除超过保留天数的隔离文件（程序可能长时间在后台运行）
+	go a.purgeQuarantineLoop(ctx)
+
+	// 启动定时扫描
+	a.scheduler.Start()
 }
 
 // DriveInfo 驱动器信息
@@ -107,14 +154,76 @@ func (a *App) SelectExportDirectory() (string, error) {
 	})
 }
 
-// ScanFiles 扫描文件
+// ScanBatch 扫描过程中推送的一批文件
+type ScanBatch struct {
+	SessionID string             `json:"sessionId"`
+	Files     []scanner.FileInfo `json:"files"`
+}
+
+// ScanFiles 扫描文件，文件列表保存在后端的结果会话中并通过 scan-batch 事件分批推送，
+// 返回值只包含统计信息和会话 ID，之后通过 GetPage 按页获取
 func (a *App) ScanFiles(options scanner.ScanOptions) (*scanner.ScanResult, error) {
-	return a.scanner.Scan(options)
+	id, sess := a.sessions.create()
+	a.activeMu.Lock()
+	a.activeID, a.activeSession = id, sess
+	a.activeMu.Unlock()
+	defer func() {
+		a.activeMu.Lock()
+		a.activeID, a.activeSession = "", nil
+		a.activeMu.Unlock()
+	}()
+
+	result, err := a.scanner.Scan(options)
+	if err != nil {
+		// 扫描失败时丢弃已推送过部分文件的会话
+		a.sessions.delete(id)
+		return nil, err
+	}
+	// 保存到扫描历史，用于之后比较两次扫描；保存失败不影响本次结果
+	if _, err := scanner.SaveScanHistory(options, result); err != nil {
+		wailsRuntime.LogErrorf(a.ctx, "保存扫描历史失败: %v", err)
+	}
+	sess.reset(result)
+	result.Files = nil
+	result.SessionID = id
+	return result, nil
+}
+
+// 导出路径预览最多返回的文件数
+const exportPreviewLimit = 100
+
+// beginExport 创建可以通过 CancelExport 取消的导出上下文，导出结束后调用返回的函数
+func (a *App) beginExport() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.exportMu.Lock()
+	a.exportCancel = cancel
+	a.exportMu.Unlock()
+	return ctx, func() {
+		a.exportMu.Lock()
+		a.exportCancel = nil
+		a.exportMu.Unlock()
+		cancel()
+	}
+}
+
+// selectExportFiles 用结果会话中 paths 对应的文件作为导出的文件列表
+func (a *App) selectExportFiles(sessionID string, paths []string, options *scanner.ExportOptions) error {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return err
+	}
+	options.Files = sess.lookup(paths)
+	return nil
 }
 
-// ExportFiles 导出文件
-func (a *App) ExportFiles(options scanner.ExportOptions) (*scanner.ExportResult, error) {
-	return a.exporter.Export(options)
+// ExportFiles 导出结果会话中选中的文件，进度通过 export-progress 事件推送
+func (a *App) ExportFiles(sessionID string, paths []string, options scanner.ExportOptions) (*scanner.ExportResult, error) {
+	if err := a.selectExportFiles(sessionID, paths, &options); err != nil {
+		return nil, err
+	}
+	ctx, done := a.beginExport()
+	defer done()
+	return a.exporter.Export(ctx, options)
 }
 
 // GetExportProgress 获取导出进度
@@ -122,13 +231,347 @@ func (a *App) GetExportProgress() scanner.ExportProgress {
 	return a.exporter.GetProgress()
 }
 
-// ExportAsZip 导出为压缩包
-func (a *App) ExportAsZip(options scanner.ExportOptions) (*scanner.ExportResult, error) {
-	return a.exporter.ExportAsZip(options)
+// ExportAsZip 把结果会话中选中的文件导出为压缩包，进度通过 export-progress 事件推送
+func (a *App) ExportAsZip(sessionID string, paths []string, options scanner.ExportOptions) (*scanner.ExportResult, error) {
+	if err := a.selectExportFiles(sessionID, paths, &options); err != nil {
+		return nil, err
+	}
+	ctx, done := a.beginExport()
+	defer done()
+	return a.exporter.ExportAsZip(ctx, options)
+}
+
+// GetResumableExport 获取中断后可以继续的上一次导出，没有时返回 nil
+func (a *App) GetResumableExport() (*scanner.ResumableExport, error) {
+	return scanner.GetResumableExport()
+}
+
+// ResumeLastExport 继续上一次中断的导出，已完整导出的文件直接跳过
+func (a *App) ResumeLastExport() (*scanner.ExportResult, error) {
+	ctx, done := a.beginExport()
+	defer done()
+	return a.exporter.ResumeLastExport(ctx)
+}
+
+// DiscardResumableExport 放弃继续上一次导出
+func (a *App) DiscardResumableExport() error {
+	return scanner.DiscardResumableExport()
+}
+
+// MoveFiles 把结果会话中选中的文件移动到目标目录，结果中的 JournalID 可用于 UndoOperation 撤销
+func (a *App) MoveFiles(sessionID string, paths []string, options scanner.ExportOptions) (*scanner.ExportResult, error) {
+	if err := a.selectExportFiles(sessionID, paths, &options); err != nil {
+		return nil, err
+	}
+	ctx, done := a.beginExport()
+	defer done()
+	return a.exporter.Move(ctx, options)
+}
+
+// ListOperations 列出可撤销的文件操作
+func (a *App) ListOperations() ([]scanner.Operation, error) {
+	return scanner.ListOperations()
+}
+
+// UndoOperation 撤销文件操作，把文件恢复到原位置（程序重启后也可以撤销）
+func (a *App) UndoOperation(journalID string) (*scanner.UndoResult, error) {
+	return scanner.UndoOperation(journalID)
+}
+
+// DeleteOperation 删除操作记录
+func (a *App) DeleteOperation(journalID string) error {
+	return scanner.DeleteOperation(journalID)
+}
+
+// QuarantineFiles 把结果会话中选中的文件移到隔离区，并从会话中移除已隔离的文件
+func (a *App) QuarantineFiles(sessionID string, paths []string, reason string) (*scanner.FileActionResult, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	files := sess.lookup(paths)
+	result, err := withWarning(scanner.QuarantineFiles(files, reason))
+	if err != nil {
+		return nil, err
+	}
+	// 保存清单失败时文件也已经移走，仍然从会话中移除
+	removeFromSession(sess, files, result.FailedFiles)
+	return result, nil
+}
+
+// MoveToTrash 把结果会话中选中的文件移到系统回收站，并从会话中移除
+func (a *App) MoveToTrash(sessionID string, paths []string) (*scanner.FileActionResult, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	files := sess.lookup(paths)
+	result := scanner.MoveToTrash(files)
+	removeFromSession(sess, files, result.FailedFiles)
+	return result, nil
+}
+
+// removeFromSession 从结果会话中移除处理成功的文件
+func removeFromSession(sess *resultSession, files []scanner.FileInfo, failed []string) {
+	skip := make(map[string]bool, len(failed))
+	for _, p := range failed {
+		skip[p] = true
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if !skip[f.Path] {
+			paths = append(paths, f.Path)
+		}
+	}
+	sess.remove(paths)
+}
+
+// ListQuarantine 列出隔离区中的文件
+func (a *App) ListQuarantine() ([]scanner.QuarantineEntry, error) {
+	return scanner.ListQuarantine()
+}
+
+// RestoreQuarantined 把隔离的文件恢复到原位置
+func (a *App) RestoreQuarantined(ids []string) (*scanner.FileActionResult, error) {
+	return withWarning(scanner.RestoreQuarantined(ids))
+}
+
+// PurgeQuarantined 永久删除隔离的文件
+func (a *App) PurgeQuarantined(ids []string) (*scanner.FileActionResult, error) {
+	return withWarning(scanner.PurgeQuarantined(ids))
+}
+
+// withWarning 文件已处理但保存隔离区清单失败时，把错误作为警告随结果返回给界面
+func withWarning(result *scanner.FileActionResult, err error) (*scanner.FileActionResult, error) {
+	if result != nil && err != nil {
+		result.Warning = err.Error()
+		err = nil
+	}
+	return result, err
+}
+
+// GetQuarantineRetention 获取隔离文件的保留天数
+func (a *App) GetQuarantineRetention() (int, error) {
+	return scanner.GetQuarantineRetention()
+}
+
+// SetQuarantineRetention 设置隔离文件的保留天数，0 表示不自动删除
+func (a *App) SetQuarantineRetention(days int) error {
+	return scanner.SetQuarantineRetention(days)
+}
+
+// PreviewExportPaths 预览结果会话中选中文件的导出路径（不写入文件），用于在导出前检查路径模板和重名文件
+func (a *App) PreviewExportPaths(sessionID string, paths []string, options scanner.ExportOptions) (*scanner.ExportPreview, error) {
+	if err := a.selectExportFiles(sessionID, paths, &options); err != nil {
+		return nil, err
+	}
+	return scanner.PreviewExportPaths(options, exportPreviewLimit)
+}
+
+// CancelExport 取消正在进行的导出，已写入一半的文件和压缩包会被删除
+func (a *App) CancelExport() {
+	a.exportMu.Lock()
+	defer a.exportMu.Unlock()
+	if a.exportCancel != nil {
+		a.exportCancel()
+	}
+}
+
+// FindSimilarFiles 在结果会话中查找内容相似的文件（近似重复的不同版本），paths 为空时分析会话中的全部文件
+func (a *App) FindSimilarFiles(sessionID string, paths []string, options scanner.SimilarityOptions) (*scanner.SimilarityResult, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var files []scanner.FileInfo
+	if len(paths) > 0 {
+		files = sess.lookup(paths)
+	} else {
+		files = sess.snapshot()
+	}
+	return scanner.FindSimilar(files, options)
+}
+
+// ClassifyFiles 按规则为结果会话中的文件重新分类，返回每个标签涉及的文件数
+func (a *App) ClassifyFiles(sessionID string, rules []scanner.ClassifyRule) (map[string]int, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	classifier, err := scanner.NewClassifier(rules)
+	if err != nil {
+		return nil, err
+	}
+	// 分类可能需要读取文件内容，在副本上进行，不阻塞翻页
+	files := sess.snapshot()
+	counts := classifier.ClassifyFiles(files)
+	sess.setLabels(files)
+	return counts, nil
+}
+
+// ImportClassifyRules 选择并加载规则文件
+func (a *App) ImportClassifyRules() ([]scanner.ClassifyRule, error) {
+	path, err := wailsRuntime.OpenFileDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+		Title:   "选择分类规则文件",
+		Filters: []wailsRuntime.FileFilter{{DisplayName: "规则文件 (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return scanner.LoadRules(path)
+}
+
+// ExportClassifyRules 保存规则到用户选择的文件，返回保存路径
+func (a *App) ExportClassifyRules(rules []scanner.ClassifyRule) (string, error) {
+	path, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "保存分类规则",
+		DefaultFilename: "docradar-rules.json",
+		Filters:         []wailsRuntime.FileFilter{{DisplayName: "规则文件 (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, scanner.SaveRules(path, rules)
+}
+
+// GetPreview 获取文档预览（内嵌缩略图或正文开头的文本）
+func (a *App) GetPreview(path string) (*scanner.Preview, error) {
+	return a.previewer.GetPreview(path)
+}
+
+// SaveCredential 保存远程存储（WebDAV、SFTP）的登录凭据
+func (a *App) SaveCredential(cred scanner.Credential) error {
+	return scanner.SaveCredential(cred)
+}
+
+// ListCredentials 列出已保存的凭据（不返回密码）
+func (a *App) ListCredentials() ([]scanner.Credential, error) {
+	return scanner.ListCredentials()
+}
+
+// DeleteCredential 删除指定地址的凭据
+func (a *App) DeleteCredential(url string) error {
+	return scanner.DeleteCredential(url)
+}
+
+// ProbeHostKey 获取 SFTP 服务器的主机公钥，首次连接或公钥变化时由界面让用户核对
+func (a *App) ProbeHostKey(url string) (*scanner.HostKeyInfo, error) {
+	return scanner.ProbeHostKey(url)
+}
+
+// TrustHostKey 记录用户确认的 SFTP 主机公钥指纹
+func (a *App) TrustHostKey(url, fingerprint string) error {
+	return scanner.TrustHostKey(url, fingerprint)
+}
+
+// ListScanHistory 列出已保存的扫描，最近的在前
+func (a *App) ListScanHistory() ([]scanner.ScanRecord, error) {
+	return scanner.ListScanHistory()
+}
+
+// DeleteScanHistory 删除已保存的扫描
+func (a *App) DeleteScanHistory(id string) error {
+	return scanner.DeleteScanHistory(id)
+}
+
+// CompareScans 比较两次扫描，返回新增、删除、修改和变为无效的文件
+func (a *App) CompareScans(fromID, toID string) (*scanner.ScanDiff, error) {
+	return scanner.CompareScans(fromID, toID)
+}
+
+// ExportScanDiff 比较两次扫描并把差异报告保存到用户选择的文件，返回保存路径
+func (a *App) ExportScanDiff(fromID, toID string) (string, error) {
+	diff, err := scanner.CompareScans(fromID, toID)
+	if err != nil {
+		return "", err
+	}
+	path, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "保存差异报告",
+		DefaultFilename: "docradar-diff-" + fromID + "-" + toID + ".csv",
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: "CSV 文件 (*.csv)", Pattern: "*.csv"},
+			{DisplayName: "JSON 文件 (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, scanner.ExportScanDiff(diff, path)
+}
+
+// GetReportColumns 返回报告中可以选择的列
+func (a *App) GetReportColumns() []scanner.ReportColumn {
+	return scanner.ReportColumns()
+}
+
+// ExportReport 把结果会话中符合过滤条件的文件（按当前排序）保存为报告，返回保存路径，
+// 格式由 options.Format 决定，为空时根据用户选择的文件扩展名决定
+func (a *App) ExportReport(sessionID string, filter FilterOptions, options scanner.ReportOptions) (string, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return "", err
+	}
+	result, err := sess.page(filter, 0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	filters := []wailsRuntime.FileFilter{
+		{DisplayName: "CSV 文件 (*.csv)", Pattern: "*.csv"},
+		{DisplayName: "Excel 工作簿 (*.xlsx)", Pattern: "*.xlsx"},
+		{DisplayName: "JSON 文件 (*.json)", Pattern: "*.json"},
+		{DisplayName: "HTML 报告 (*.html)", Pattern: "*.html"},
+	}
+	ext := options.Format
+	if ext == "" {
+		ext = scanner.ReportCSV
+	}
+	// 把选择的格式放在第一个，作为对话框的默认筛选
+	for i, f := range filters {
+		if f.Pattern == "*."+ext {
+			filters[0], filters[i] = filters[i], filters[0]
+		}
+	}
+	path, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "保存报告",
+		DefaultFilename: "docradar-report-" + time.Now().Format("20060102-150405") + "." + ext,
+		Filters:         filters,
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	// 以用户最终选择的扩展名为准，扩展名不是报告格式时使用选择的格式
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".xlsx", ".json", ".html":
+		options.Format = ""
+	}
+	return path, scanner.ExportReport(result.Files, options, path)
+}
+
+// ListScheduledScans 列出定时扫描
+func (a *App) ListScheduledScans() ([]scanner.ScheduledScan, error) {
+	return scanner.ListScheduledScans()
+}
+
+// SaveScheduledScan 新建或修改定时扫描
+func (a *App) SaveScheduledScan(sc scanner.ScheduledScan) (*scanner.ScheduledScan, error) {
+	return scanner.SaveScheduledScan(sc)
+}
+
+// DeleteScheduledScan 删除定时扫描
+func (a *App) DeleteScheduledScan(id string) error {
+	return scanner.DeleteScheduledScan(id)
+}
+
+// RunScheduledScan 立即在后台执行定时扫描，结果保存到扫描历史
+func (a *App) RunScheduledScan(id string) error {
+	return a.scheduler.RunNow(id)
 }
 
 // OpenFolder 打开文件所在文件夹
 func (a *App) OpenFolder(filePath string) error {
+	// 压缩包内的文件定位到压缩包本身
+	filePath = scanner.OuterPath(filePath)
 	dir := filepath.Dir(filePath)
 
 	var cmd *exec.Cmd
@@ -147,61 +590,87 @@ func (a *App) OpenFolder(filePath string) error {
 // FilterResult 过滤结果
 type FilterResult struct {
 	Files      []scanner.FileInfo `json:"files"`
-	TotalCount int                `json:"totalCount"`
-}
-
-// FilterFiles 过滤文件列表
-func (a *App) FilterFiles(files []scanner.FileInfo, filter FilterOptions) FilterResult {
-	var result []scanner.FileInfo
-
-	for _, file := range files {
-		// 按文件类型过滤
-		if len(filter.FileTypes) > 0 {
-			found := false
-			for _, t := range filter.FileTypes {
-				if file.FileType == t {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
+	TotalCount int                `json:"totalCount"` // 符合条件的文件总数
+	Page       int                `json:"page"`
+	PageSize   int                `json:"pageSize"`
+}
 
-		// 按有效性过滤
-		if filter.ValidOnly && !file.IsValid {
-			continue
-		}
-		if filter.InvalidOnly && file.IsValid {
-			continue
-		}
+// FilterFiles 返回结果会话中符合条件的全部文件路径（用于跨页全选），不传输文件详情
+func (a *App) FilterFiles(sessionID string, filter FilterOptions) ([]string, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return sess.paths(filter)
+}
 
-		// 按文件名搜索
-		if filter.SearchText != "" {
-			if !containsIgnoreCase(file.Name, filter.SearchText) {
-				continue
-			}
-		}
+// GetPage 按过滤和排序条件返回结果会话中的一页文件，page 从 1 开始，pageSize 不大于 0 时返回全部
+func (a *App) GetPage(sessionID string, filter FilterOptions, page, pageSize int) (*FilterResult, error) {
+	sess, err := a.sessions.get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return sess.page(filter, page, pageSize)
+}
+
+// ListSavedQueries 列出已保存的查询
+func (a *App) ListSavedQueries() ([]scanner.SavedQuery, error) {
+	return scanner.ListSavedQueries()
+}
+
+// SaveQuery 保存查询供之后的扫描复用
+func (a *App) SaveQuery(name, query string) error {
+	return scanner.SaveQuery(name, query)
+}
+
+// DeleteSavedQuery 删除已保存的查询
+func (a *App) DeleteSavedQuery(name string) error {
+	return scanner.DeleteSavedQuery(name)
+}
 
-		// 按文件大小过滤
-		if filter.MinSize > 0 && file.Size < filter.MinSize {
-			continue
+// matchFilter 判断文件是否符合过滤条件（文件名搜索和查询另行匹配）
+func matchFilter(file *scanner.FileInfo, filter FilterOptions) bool {
+	// 按文件类型过滤
+	if len(filter.FileTypes) > 0 {
+		found := false
+		for _, t := range filter.FileTypes {
+			if file.FileType == t {
+				found = true
+				break
+			}
 		}
-		if filter.MaxSize > 0 && file.Size > filter.MaxSize {
-			continue
+		if !found {
+			return false
 		}
+	}
 
-		result = append(result, file)
+	// 按有效性过滤
+	if filter.ValidOnly && !file.IsValid {
+		return false
+	}
+	if filter.InvalidOnly && file.IsValid {
+		return false
 	}
 
-	// 排序
-	sortFiles(result, filter.SortBy, filter.SortDesc)
+	// 按敏感信息类型过滤
+	if len(filter.FindingTypes) > 0 && !file.HasFindingType(filter.FindingTypes) {
+		return false
+	}
 
-	return FilterResult{
-		Files:      result,
-		TotalCount: len(result),
+	// 按标签过滤
+	if len(filter.Labels) > 0 && !file.HasLabel(filter.Labels) {
+		return false
 	}
+
+	// 按文件大小过滤
+	if filter.MinSize > 0 && file.Size < filter.MinSize {
+		return false
+	}
+	if filter.MaxSize > 0 && file.Size > filter.MaxSize {
+		return false
+	}
+
+	return true
 }
 
 // FilterOptions 过滤选项
@@ -209,68 +678,40 @@ type FilterOptions struct {
 	FileTypes   []string `json:"fileTypes"`
 	ValidOnly   bool     `json:"validOnly"`
 	InvalidOnly bool     `json:"invalidOnly"`
-	SearchText  string   `json:"searchText"`
+	SearchText  string   `json:"searchText"` // 文件名搜索，支持拼音和拼音首字母
+	SearchMode  string   `json:"searchMode"` // 空为包含匹配，fuzzy 为模糊匹配（按匹配程度排序）
 	MinSize     int64    `json:"minSize"`
 	MaxSize     int64    `json:"maxSize"`
-	SortBy      string   `json:"sortBy"`   // name, size, modTime, type
+	SortBy      string   `json:"sortBy"`   // name, size, modTime, type, path，为空时按扫描顺序
 	SortDesc    bool     `json:"sortDesc"` // 是否降序
-}
 
-// containsIgnoreCase 忽略大小写的字符串包含检查
-func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(s == substr ||
-			len(substr) == 0 ||
-			findIgnoreCase(s, substr) >= 0)
-}
+	FindingTypes []string `json:"findingTypes"` // 包含任一指定类型敏感信息的文件
+	Labels       []string `json:"labels"`       // 带有任一指定标签的文件
 
-func findIgnoreCase(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if equalIgnoreCase(s[i:i+len(substr)], substr) {
-			return i
-		}
-	}
-	return -1
+	Query string `json:"query"` // 查询表达式，如 type:pdf size>10MB -invalid，与其他条件同时满足
 }
 
-func equalIgnoreCase(a, b string) bool {
-	if len(a) != len(b) {
-		return false
+// sortView 按文件属性对下标排序，sortBy 为空时保持扫描顺序
+func sortView(files []scanner.FileInfo, view []int, sortBy string, desc bool) {
+	if sortBy == "" {
+		return
 	}
-	for i := 0; i < len(a); i++ {
-		ca, cb := a[i], b[i]
-		if ca >= 'A' && ca <= 'Z' {
-			ca += 'a' - 'A'
-		}
-		if cb >= 'A' && cb <= 'Z' {
-			cb += 'a' - 'A'
-		}
-		if ca != cb {
-			return false
+	sort.SliceStable(view, func(i, j int) bool {
+		a, b := &files[view[i]], &files[view[j]]
+		if desc {
+			a, b = b, a
 		}
-	}
-	return true
-}
-
-// sortFiles 对文件列表排序
-func sortFiles(files []scanner.FileInfo, sortBy string, desc bool) {
-	sort.Slice(files, func(i, j int) bool {
-		var less bool
 		switch sortBy {
-		case "name":
-			less = files[i].Name < files[j].Name
 		case "size":
-			less = files[i].Size < files[j].Size
+			return a.Size < b.Size
 		case "modTime":
-			less = files[i].ModTime.Before(files[j].ModTime)
+			return a.ModTime.Before(b.ModTime)
 		case "type":
-			less = files[i].FileType < files[j].FileType
+			return a.FileType < b.FileType
+		case "path":
+			return a.Path < b.Path
 		default:
-			less = files[i].Name < files[j].Name
-		}
-		if desc {
-			return !less
+			return a.Name < b.Name
 		}
-		return less
 	})
 }
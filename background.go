@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"doc-radar/scanner"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Windows 上借用 PowerShell 的应用 ID 发送通知（未安装的程序没有注册自己的应用 ID）
+const powershellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// Windows 通知脚本，标题和内容通过环境变量传入，避免转义问题
+const windowsToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:DOCRADAR_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:DOCRADAR_MESSAGE)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:DOCRADAR_APPID).Show([Windows.UI.Notifications.ToastNotification]::new($xml))
+`
+
+// sendNotification 使用系统自带的命令发送桌面通知
+func sendNotification(title, message string) error {
+	var cmd *exec.Cmd
+	switch goruntime.GOOS {
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-WindowStyle", "Hidden", "-Command", windowsToastScript)
+		cmd.Env = append(os.Environ(),
+			"DOCRADAR_TITLE="+title,
+			"DOCRADAR_MESSAGE="+message,
+			"DOCRADAR_APPID="+powershellAppID,
+		)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e",
+			"display notification "+appleScriptString(message)+" with title "+appleScriptString(title))
+	default: // linux
+		cmd = exec.Command("notify-send", "--app-name=DocRadar", title, message)
+	}
+	return cmd.Run()
+}
+
+// appleScriptString 转换为 AppleScript 字符串字面量
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// onScanAlert 定时扫描发现新问题时通知界面并发送桌面通知
+func (a *App) onScanAlert(alert scanner.ScanAlert) {
+	wailsRuntime.EventsEmit(a.ctx, "scan-alert", alert)
+	if err := sendNotification("DocRadar - "+alert.Name, alert.Summary()); err != nil {
+		wailsRuntime.LogErrorf(a.ctx, "无法发送桌面通知: %v", err)
+	}
+}
+
+// hasEnabledSchedules 是否有启用的定时扫描（有时关闭窗口只最小化，在后台继续运行）
+func (a *App) hasEnabledSchedules() bool {
+	schedules, err := scanner.ListScheduledScans()
+	if err != nil {
+		return false
+	}
+	for _, sc := range schedules {
+		if sc.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// beforeClose 有启用的定时扫描时关闭窗口只最小化，调度器继续在后台运行。
+// Wails v2 不支持托盘图标，最小化的窗口仍在任务栏（程序坞）中，可以随时恢复或在定时扫描设置中退出程序
+func (a *App) beforeClose(ctx context.Context) bool {
+	if a.quitting.Load() || !a.hasEnabledSchedules() {
+		return false
+	}
+	wailsRuntime.WindowMinimise(ctx)
+	if !a.backgroundNotified.Swap(true) {
+		sendNotification("DocRadar", "定时扫描将在后台继续运行，窗口已最小化到任务栏；要停止请在“定时扫描”中点击“退出程序”")
+	}
+	return true
+}
+
+// onSecondInstanceLaunch 程序在后台运行时再次启动，显示已有的窗口
+func (a *App) onSecondInstanceLaunch(options.SecondInstanceData) {
+	wailsRuntime.WindowUnminimise(a.ctx)
+	wailsRuntime.WindowShow(a.ctx)
+}
+
+// purgeQuarantineLoop 启动时和之后每小时删除超过保留天数的隔离文件
+func (a *App) purgeQuarantineLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if n, err := scanner.PurgeExpiredQuarantine(); err != nil {
+			wailsRuntime.LogErrorf(ctx, "无法清理隔离区: %v", err)
+		} else if n > 0 {
+			wailsRuntime.LogInfof(ctx, "已删除 %d 个过期的隔离文件", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdown 程序退出时停止调度器
+func (a *App) shutdown(ctx context.Context) {
+	a.scheduler.Stop()
+}
+
+// Quit 退出程序（停止后台的定时扫描）
+func (a *App) Quit() {
+	a.quitting.Store(true)
+	wailsRuntime.Quit(a.ctx)
+}
@@ -3,20 +3,102 @@
 import {scanner} from '../models';
 import {main} from '../models';
 
-export function ExportAsZip(arg1:scanner.ExportOptions):Promise<scanner.ExportResult>;
+export function CancelExport():Promise<void>;
 
-export function ExportFiles(arg1:scanner.ExportOptions):Promise<scanner.ExportResult>;
+export function ClassifyFiles(arg1:string,arg2:Array<scanner.ClassifyRule>):Promise<Record<string, number>>;
 
-export function FilterFiles(arg1:Array<scanner.FileInfo>,arg2:main.FilterOptions):Promise<main.FilterResult>;
+export function CompareScans(arg1:string,arg2:string):Promise<scanner.ScanDiff>;
+
+export function DeleteCredential(arg1:string):Promise<void>;
+
+export function DeleteOperation(arg1:string):Promise<void>;
+
+export function DeleteSavedQuery(arg1:string):Promise<void>;
+
+export function DeleteScanHistory(arg1:string):Promise<void>;
+
+export function DeleteScheduledScan(arg1:string):Promise<void>;
+
+export function DiscardResumableExport():Promise<void>;
+
+export function ExportAsZip(arg1:string,arg2:Array<string>,arg3:scanner.ExportOptions):Promise<scanner.ExportResult>;
+
+export function ExportClassifyRules(arg1:Array<scanner.ClassifyRule>):Promise<string>;
+
+export function ExportFiles(arg1:string,arg2:Array<string>,arg3:scanner.ExportOptions):Promise<scanner.ExportResult>;
+
+export function ExportReport(arg1:string,arg2:main.FilterOptions,arg3:scanner.ReportOptions):Promise<string>;
+
+export function ExportScanDiff(arg1:string,arg2:string):Promise<string>;
+
+export function FilterFiles(arg1:string,arg2:main.FilterOptions):Promise<Array<string>>;
+
+export function FindSimilarFiles(arg1:string,arg2:Array<string>,arg3:scanner.SimilarityOptions):Promise<scanner.SimilarityResult>;
 
 export function GetDrives():Promise<Array<main.DriveInfo>>;
 
 export function GetExportProgress():Promise<scanner.ExportProgress>;
 
+export function GetPage(arg1:string,arg2:main.FilterOptions,arg3:number,arg4:number):Promise<main.FilterResult>;
+
+export function GetPreview(arg1:string):Promise<scanner.Preview>;
+
+export function GetQuarantineRetention():Promise<number>;
+
+export function GetReportColumns():Promise<Array<scanner.ReportColumn>>;
+
+export function GetResumableExport():Promise<scanner.ResumableExport>;
+
+export function ImportClassifyRules():Promise<Array<scanner.ClassifyRule>>;
+
+export function ListCredentials():Promise<Array<scanner.Credential>>;
+
+export function ListOperations():Promise<Array<scanner.Operation>>;
+
+export function ListQuarantine():Promise<Array<scanner.QuarantineEntry>>;
+
+export function ListSavedQueries():Promise<Array<scanner.SavedQuery>>;
+
+export function ListScanHistory():Promise<Array<scanner.ScanRecord>>;
+
+export function ListScheduledScans():Promise<Array<scanner.ScheduledScan>>;
+
+export function MoveFiles(arg1:string,arg2:Array<string>,arg3:scanner.ExportOptions):Promise<scanner.ExportResult>;
+
+export function MoveToTrash(arg1:string,arg2:Array<string>):Promise<scanner.FileActionResult>;
+
 export function OpenFolder(arg1:string):Promise<void>;
 
+export function PreviewExportPaths(arg1:string,arg2:Array<string>,arg3:scanner.ExportOptions):Promise<scanner.ExportPreview>;
+
+export function ProbeHostKey(arg1:string):Promise<scanner.HostKeyInfo>;
+
+export function PurgeQuarantined(arg1:Array<string>):Promise<scanner.FileActionResult>;
+
+export function QuarantineFiles(arg1:string,arg2:Array<string>,arg3:string):Promise<scanner.FileActionResult>;
+
+export function Quit():Promise<void>;
+
+export function RestoreQuarantined(arg1:Array<string>):Promise<scanner.FileActionResult>;
+
+export function ResumeLastExport():Promise<scanner.ExportResult>;
+
+export function RunScheduledScan(arg1:string):Promise<void>;
+
+export function SaveCredential(arg1:scanner.Credential):Promise<void>;
+
+export function SaveQuery(arg1:string,arg2:string):Promise<void>;
+
+export function SaveScheduledScan(arg1:scanner.ScheduledScan):Promise<scanner.ScheduledScan>;
+
 export function ScanFiles(arg1:scanner.ScanOptions):Promise<scanner.ScanResult>;
 
 export function SelectDirectory():Promise<string>;
 
 export function SelectExportDirectory():Promise<string>;
+
+export function SetQuarantineRetention(arg1:number):Promise<void>;
+
+export function TrustHostKey(arg1:string,arg2:string):Promise<void>;
+
+export function UndoOperation(arg1:string):Promise<scanner.UndoResult>;
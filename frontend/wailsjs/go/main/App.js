@@ -2,18 +2,70 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function ExportAsZip(arg1) {
-  return window['go']['main']['App']['ExportAsZip'](arg1);
+export function CancelExport() {
+  return window['go']['main']['App']['CancelExport']();
 }
 
-export function ExportFiles(arg1) {
-  return window['go']['main']['App']['ExportFiles'](arg1);
+export function ClassifyFiles(arg1, arg2) {
+  return window['go']['main']['App']['ClassifyFiles'](arg1, arg2);
+}
+
+export function CompareScans(arg1, arg2) {
+  return window['go']['main']['App']['CompareScans'](arg1, arg2);
+}
+
+export function DeleteCredential(arg1) {
+  return window['go']['main']['App']['DeleteCredential'](arg1);
+}
+
+export function DeleteOperation(arg1) {
+  return window['go']['main']['App']['DeleteOperation'](arg1);
+}
+
+export function DeleteSavedQuery(arg1) {
+  return window['go']['main']['App']['DeleteSavedQuery'](arg1);
+}
+
+export function DeleteScanHistory(arg1) {
+  return window['go']['main']['App']['DeleteScanHistory'](arg1);
+}
+
+export function DeleteScheduledScan(arg1) {
+  return window['go']['main']['App']['DeleteScheduledScan'](arg1);
+}
+
+export function DiscardResumableExport() {
+  return window['go']['main']['App']['DiscardResumableExport']();
+}
+
+export function ExportAsZip(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportAsZip'](arg1, arg2, arg3);
+}
+
+export function ExportClassifyRules(arg1) {
+  return window['go']['main']['App']['ExportClassifyRules'](arg1);
+}
+
+export function ExportFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportFiles'](arg1, arg2, arg3);
+}
+
+export function ExportReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportReport'](arg1, arg2, arg3);
+}
+
+export function ExportScanDiff(arg1, arg2) {
+  return window['go']['main']['App']['ExportScanDiff'](arg1, arg2);
 }
 
 export function FilterFiles(arg1, arg2) {
   return window['go']['main']['App']['FilterFiles'](arg1, arg2);
 }
 
+export function FindSimilarFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['FindSimilarFiles'](arg1, arg2, arg3);
+}
+
 export function GetDrives() {
   return window['go']['main']['App']['GetDrives']();
 }
@@ -22,10 +74,110 @@ export function GetExportProgress() {
   return window['go']['main']['App']['GetExportProgress']();
 }
 
+export function GetPage(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetPage'](arg1, arg2, arg3, arg4);
+}
+
+export function GetPreview(arg1) {
+  return window['go']['main']['App']['GetPreview'](arg1);
+}
+
+export function GetQuarantineRetention() {
+  return window['go']['main']['App']['GetQuarantineRetention']();
+}
+
+export function GetReportColumns() {
+  return window['go']['main']['App']['GetReportColumns']();
+}
+
+export function GetResumableExport() {
+  return window['go']['main']['App']['GetResumableExport']();
+}
+
+export function ImportClassifyRules() {
+  return window['go']['main']['App']['ImportClassifyRules']();
+}
+
+export function ListCredentials() {
+  return window['go']['main']['App']['ListCredentials']();
+}
+
+export function ListOperations() {
+  return window['go']['main']['App']['ListOperations']();
+}
+
+export function ListQuarantine() {
+  return window['go']['main']['App']['ListQuarantine']();
+}
+
+export function ListSavedQueries() {
+  return window['go']['main']['App']['ListSavedQueries']();
+}
+
+export function ListScanHistory() {
+  return window['go']['main']['App']['ListScanHistory']();
+}
+
+export function ListScheduledScans() {
+  return window['go']['main']['App']['ListScheduledScans']();
+}
+
+export function MoveFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveFiles'](arg1, arg2, arg3);
+}
+
+export function MoveToTrash(arg1, arg2) {
+  return window['go']['main']['App']['MoveToTrash'](arg1, arg2);
+}
+
 export function OpenFolder(arg1) {
   return window['go']['main']['App']['OpenFolder'](arg1);
 }
 
+export function PreviewExportPaths(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewExportPaths'](arg1, arg2, arg3);
+}
+
+export function ProbeHostKey(arg1) {
+  return window['go']['main']['App']['ProbeHostKey'](arg1);
+}
+
+export function PurgeQuarantined(arg1) {
+  return window['go']['main']['App']['PurgeQuarantined'](arg1);
+}
+
+export function QuarantineFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['QuarantineFiles'](arg1, arg2, arg3);
+}
+
+export function Quit() {
+  return window['go']['main']['App']['Quit']();
+}
+
+export function RestoreQuarantined(arg1) {
+  return window['go']['main']['App']['RestoreQuarantined'](arg1);
+}
+
+export function ResumeLastExport() {
+  return window['go']['main']['App']['ResumeLastExport']();
+}
+
+export function RunScheduledScan(arg1) {
+  return window['go']['main']['App']['RunScheduledScan'](arg1);
+}
+
+export function SaveCredential(arg1) {
+  return window['go']['main']['App']['SaveCredential'](arg1);
+}
+
+export function SaveQuery(arg1, arg2) {
+  return window['go']['main']['App']['SaveQuery'](arg1, arg2);
+}
+
+export function SaveScheduledScan(arg1) {
+  return window['go']['main']['App']['SaveScheduledScan'](arg1);
+}
+
 export function ScanFiles(arg1) {
   return window['go']['main']['App']['ScanFiles'](arg1);
 }
@@ -37,3 +189,15 @@ export function SelectDirectory() {
 export function SelectExportDirectory() {
   return window['go']['main']['App']['SelectExportDirectory']();
 }
+
+export function SetQuarantineRetention(arg1) {
+  return window['go']['main']['App']['SetQuarantineRetention'](arg1);
+}
+
+export function TrustHostKey(arg1, arg2) {
+  return window['go']['main']['App']['TrustHostKey'](arg1, arg2);
+}
+
+export function UndoOperation(arg1) {
+  return window['go']['main']['App']['UndoOperation'](arg1);
+}
@@ -19,10 +19,14 @@ export namespace main {
 	    validOnly: boolean;
 	    invalidOnly: boolean;
 	    searchText: string;
+	    searchMode: string;
 	    minSize: number;
 	    maxSize: number;
 	    sortBy: string;
 	    sortDesc: boolean;
+	    findingTypes: string[];
+	    labels: string[];
+	    query: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new FilterOptions(source);
@@ -34,15 +38,21 @@ export namespace main {
 	        this.validOnly = source["validOnly"];
 	        this.invalidOnly = source["invalidOnly"];
 	        this.searchText = source["searchText"];
+	        this.searchMode = source["searchMode"];
 	        this.minSize = source["minSize"];
 	        this.maxSize = source["maxSize"];
 	        this.sortBy = source["sortBy"];
 	        this.sortDesc = source["sortDesc"];
+	        this.findingTypes = source["findingTypes"];
+	        this.labels = source["labels"];
+	        this.query = source["query"];
 	    }
 	}
 	export class FilterResult {
 	    files: scanner.FileInfo[];
 	    totalCount: number;
+	    page: number;
+	    pageSize: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new FilterResult(source);
@@ -52,6 +62,8 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.files = this.convertValues(source["files"], scanner.FileInfo);
 	        this.totalCount = source["totalCount"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -77,6 +89,130 @@ export namespace main {
 
 export namespace scanner {
 	
+	export class RuleCondition {
+	    field: string;
+	    op: string;
+	    value: string;
+	    negate: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleCondition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.op = source["op"];
+	        this.value = source["value"];
+	        this.negate = source["negate"];
+	    }
+	}
+	export class ClassifyRule {
+	    name: string;
+	    labels: string[];
+	    match: string;
+	    conditions: RuleCondition[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ClassifyRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.labels = source["labels"];
+	        this.match = source["match"];
+	        this.conditions = this.convertValues(source["conditions"], RuleCondition);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Credential {
+	    url: string;
+	    username: string;
+	    password?: string;
+	    keyFile?: string;
+	    hostKey?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Credential(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.keyFile = source["keyFile"];
+	        this.hostKey = source["hostKey"];
+	    }
+	}
+	export class CustomPattern {
+	    name: string;
+	    pattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CustomPattern(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.pattern = source["pattern"];
+	    }
+	}
+	export class DocMetadata {
+	    title?: string;
+	    author?: string;
+	    subject?: string;
+	    keywords?: string;
+	    pages?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.author = source["author"];
+	        this.subject = source["subject"];
+	        this.keywords = source["keywords"];
+	        this.pages = source["pages"];
+	    }
+	}
+	export class Finding {
+	    type: string;
+	    count: number;
+	    samples: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Finding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.count = source["count"];
+	        this.samples = source["samples"];
+	    }
+	}
 	export class FileInfo {
 	    path: string;
 	    name: string;
@@ -87,6 +223,12 @@ export namespace scanner {
 	    fileType: string;
 	    isValid: boolean;
 	    invalidReason?: string;
+	    findings?: Finding[];
+	    labels?: string[];
+	    metadata?: DocMetadata;
+	    root?: string;
+	    hash?: string;
+	    encrypted?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileInfo(source);
@@ -102,6 +244,12 @@ export namespace scanner {
 	        this.fileType = source["fileType"];
 	        this.isValid = source["isValid"];
 	        this.invalidReason = source["invalidReason"];
+	        this.findings = this.convertValues(source["findings"], Finding);
+	        this.labels = source["labels"];
+	        this.metadata = this.convertValues(source["metadata"], DocMetadata);
+	        this.root = source["root"];
+	        this.hash = source["hash"];
+	        this.encrypted = source["encrypted"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -126,7 +274,15 @@ export namespace scanner {
 	    destPath: string;
 	    files: FileInfo[];
 	    keepStructure: boolean;
+	    structureMode: string;
+	    baseDir: string;
 	    overwrite: boolean;
+	    findingTypes: string[];
+	    collisionPolicy: string;
+	    pathTemplate: string;
+	    verify: boolean;
+	    preserveMetadata: boolean;
+	    zipVolumeSize: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ExportOptions(source);
@@ -137,7 +293,67 @@ export namespace scanner {
 	        this.destPath = source["destPath"];
 	        this.files = this.convertValues(source["files"], FileInfo);
 	        this.keepStructure = source["keepStructure"];
+	        this.structureMode = source["structureMode"];
+	        this.baseDir = source["baseDir"];
 	        this.overwrite = source["overwrite"];
+	        this.findingTypes = source["findingTypes"];
+	        this.collisionPolicy = source["collisionPolicy"];
+	        this.pathTemplate = source["pathTemplate"];
+	        this.verify = source["verify"];
+	        this.preserveMetadata = source["preserveMetadata"];
+	        this.zipVolumeSize = source["zipVolumeSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExportPathPreview {
+	    path: string;
+	    dest: string;
+	    renamed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportPathPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.dest = source["dest"];
+	        this.renamed = source["renamed"];
+	    }
+	}
+	export class ExportPreview {
+	    items: ExportPathPreview[];
+	    total: number;
+	    renamed: number;
+	    skipped: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ExportPathPreview);
+	        this.total = source["total"];
+	        this.renamed = source["renamed"];
+	        this.skipped = source["skipped"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -164,6 +380,11 @@ export namespace scanner {
 	    failed: number;
 	    current: string;
 	    percent: number;
+	    bytesDone: number;
+	    bytesTotal: number;
+	    speed: number;
+	    eta: number;
+	    done: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new ExportProgress(source);
@@ -176,6 +397,43 @@ export namespace scanner {
 	        this.failed = source["failed"];
 	        this.current = source["current"];
 	        this.percent = source["percent"];
+	        this.bytesDone = source["bytesDone"];
+	        this.bytesTotal = source["bytesTotal"];
+	        this.speed = source["speed"];
+	        this.eta = source["eta"];
+	        this.done = source["done"];
+	    }
+	}
+	export class VerifySummary {
+	    verified: number;
+	    mismatched: string[];
+	    metadataFailed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new VerifySummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.verified = source["verified"];
+	        this.mismatched = source["mismatched"];
+	        this.metadataFailed = source["metadataFailed"];
+	    }
+	}
+	export class RenamedFile {
+	    path: string;
+	    from: string;
+	    to: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenamedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.from = source["from"];
+	        this.to = source["to"];
 	    }
 	}
 	export class ExportResult {
@@ -183,6 +441,15 @@ export namespace scanner {
 	    failed: number;
 	    failedFiles: string[];
 	    skippedFiles: string[];
+	    renamed: RenamedFile[];
+	    canceled: boolean;
+	    journalId: string;
+	    verify?: VerifySummary;
+	    resumed: number;
+	    manifestPath: string;
+	    manifestErr: string;
+	    checkpointErr: string;
+	    zipFiles: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ExportResult(source);
@@ -194,45 +461,194 @@ export namespace scanner {
 	        this.failed = source["failed"];
 	        this.failedFiles = source["failedFiles"];
 	        this.skippedFiles = source["skippedFiles"];
+	        this.renamed = this.convertValues(source["renamed"], RenamedFile);
+	        this.canceled = source["canceled"];
+	        this.journalId = source["journalId"];
+	        this.verify = this.convertValues(source["verify"], VerifySummary);
+	        this.resumed = source["resumed"];
+	        this.manifestPath = source["manifestPath"];
+	        this.manifestErr = source["manifestErr"];
+	        this.checkpointErr = source["checkpointErr"];
+	        this.zipFiles = source["zipFiles"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileActionResult {
+	    success: number;
+	    failed: number;
+	    failedFiles: string[];
+	    warning?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileActionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.failed = source["failed"];
+	        this.failedFiles = source["failedFiles"];
+	        this.warning = source["warning"];
 	    }
 	}
+	export class FileChange {
+	    old: FileInfo;
+	    new: FileInfo;
+	    changes: string[];
 	
-	export class ScanOptions {
-	    rootPath: string;
-	    includeTypes: string[];
-	    excludePaths: string[];
-	    validateFiles: boolean;
+	    static createFrom(source: any = {}) {
+	        return new FileChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.old = this.convertValues(source["old"], FileInfo);
+	        this.new = this.convertValues(source["new"], FileInfo);
+	        this.changes = source["changes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class HostKeyInfo {
+	    url: string;
+	    keyType: string;
+	    fingerprint: string;
+	    known: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ScanOptions(source);
+	        return new HostKeyInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.rootPath = source["rootPath"];
-	        this.includeTypes = source["includeTypes"];
-	        this.excludePaths = source["excludePaths"];
-	        this.validateFiles = source["validateFiles"];
+	        this.url = source["url"];
+	        this.keyType = source["keyType"];
+	        this.fingerprint = source["fingerprint"];
+	        this.known = source["known"];
 	    }
 	}
-	export class ScanResult {
-	    files: FileInfo[];
-	    totalCount: number;
-	    validCount: number;
-	    invalidCount: number;
-	    scanTime: number;
+	export class Operation {
+	    id: string;
+	    kind: string;
+	    // Go type: time
+	    time: any;
+	    destPath: string;
+	    count: number;
+	    undone: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ScanResult(source);
+	        return new Operation(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.files = this.convertValues(source["files"], FileInfo);
-	        this.totalCount = source["totalCount"];
-	        this.validCount = source["validCount"];
-	        this.invalidCount = source["invalidCount"];
-	        this.scanTime = source["scanTime"];
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.destPath = source["destPath"];
+	        this.count = source["count"];
+	        this.undone = source["undone"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Preview {
+	    kind: string;
+	    mimeType: string;
+	    dataUrl: string;
+	    text?: string;
+	    note?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.mimeType = source["mimeType"];
+	        this.dataUrl = source["dataUrl"];
+	        this.text = source["text"];
+	        this.note = source["note"];
+	    }
+	}
+	export class QuarantineEntry {
+	    id: string;
+	    originalPath: string;
+	    name: string;
+	    size: number;
+	    // Go type: time
+	    modTime: any;
+	    hash?: string;
+	    reason: string;
+	    // Go type: time
+	    quarantinedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuarantineEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.originalPath = source["originalPath"];
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
+	        this.hash = source["hash"];
+	        this.reason = source["reason"];
+	        this.quarantinedAt = this.convertValues(source["quarantinedAt"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -253,6 +669,552 @@ export namespace scanner {
 		    return a;
 		}
 	}
+	
+	export class ReportColumn {
+	    key: string;
+	    title: string;
+	    default: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportColumn(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.title = source["title"];
+	        this.default = source["default"];
+	    }
+	}
+	export class ReportOptions {
+	    format: string;
+	    columns: string[];
+	    title: string;
+	    filter: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.columns = source["columns"];
+	        this.title = source["title"];
+	        this.filter = source["filter"];
+	    }
+	}
+	export class ResumableExport {
+	    id: string;
+	    // Go type: time
+	    time: any;
+	    destPath: string;
+	    total: number;
+	    completed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResumableExport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.destPath = source["destPath"];
+	        this.total = source["total"];
+	        this.completed = source["completed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RootStats {
+	    root: string;
+	    totalCount: number;
+	    validCount: number;
+	    invalidCount: number;
+	    scannedDirs: number;
+	    scanTime: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RootStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.totalCount = source["totalCount"];
+	        this.validCount = source["validCount"];
+	        this.invalidCount = source["invalidCount"];
+	        this.scannedDirs = source["scannedDirs"];
+	        this.scanTime = source["scanTime"];
+	        this.error = source["error"];
+	    }
+	}
+	
+	export class SavedQuery {
+	    name: string;
+	    query: string;
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.query = source["query"];
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanOptions {
+	    rootPath: string;
+	    rootPaths: string[];
+	    backend: string;
+	    includeTypes: string[];
+	    excludePaths: string[];
+	    validateFiles: boolean;
+	    inspectContent: boolean;
+	    customPatterns: CustomPattern[];
+	    rules: ClassifyRule[];
+	    scanArchives: boolean;
+	    archiveDepth: number;
+	    computeHash: boolean;
+	    detectEncryption: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rootPath = source["rootPath"];
+	        this.rootPaths = source["rootPaths"];
+	        this.backend = source["backend"];
+	        this.includeTypes = source["includeTypes"];
+	        this.excludePaths = source["excludePaths"];
+	        this.validateFiles = source["validateFiles"];
+	        this.inspectContent = source["inspectContent"];
+	        this.customPatterns = this.convertValues(source["customPatterns"], CustomPattern);
+	        this.rules = this.convertValues(source["rules"], ClassifyRule);
+	        this.scanArchives = source["scanArchives"];
+	        this.archiveDepth = source["archiveDepth"];
+	        this.computeHash = source["computeHash"];
+	        this.detectEncryption = source["detectEncryption"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanRecord {
+	    id: string;
+	    // Go type: time
+	    time: any;
+	    options: ScanOptions;
+	    totalCount: number;
+	    validCount: number;
+	    invalidCount: number;
+	    scanTime: number;
+	    scheduleId?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.options = this.convertValues(source["options"], ScanOptions);
+	        this.totalCount = source["totalCount"];
+	        this.validCount = source["validCount"];
+	        this.invalidCount = source["invalidCount"];
+	        this.scanTime = source["scanTime"];
+	        this.scheduleId = source["scheduleId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanDiff {
+	    from: ScanRecord;
+	    to: ScanRecord;
+	    added: FileInfo[];
+	    removed: FileInfo[];
+	    modified: FileChange[];
+	    newlyInvalid: FileInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], ScanRecord);
+	        this.to = this.convertValues(source["to"], ScanRecord);
+	        this.added = this.convertValues(source["added"], FileInfo);
+	        this.removed = this.convertValues(source["removed"], FileInfo);
+	        this.modified = this.convertValues(source["modified"], FileChange);
+	        this.newlyInvalid = this.convertValues(source["newlyInvalid"], FileInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class ScanResult {
+	    files: FileInfo[];
+	    totalCount: number;
+	    validCount: number;
+	    invalidCount: number;
+	    scanTime: number;
+	    findingCounts?: Record<string, number>;
+	    labelCounts?: Record<string, number>;
+	    roots?: RootStats[];
+	    sessionId?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], FileInfo);
+	        this.totalCount = source["totalCount"];
+	        this.validCount = source["validCount"];
+	        this.invalidCount = source["invalidCount"];
+	        this.scanTime = source["scanTime"];
+	        this.findingCounts = source["findingCounts"];
+	        this.labelCounts = source["labelCounts"];
+	        this.roots = this.convertValues(source["roots"], RootStats);
+	        this.sessionId = source["sessionId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Schedule {
+	    every: string;
+	    time: string;
+	    weekday: number;
+	    day: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Schedule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.every = source["every"];
+	        this.time = source["time"];
+	        this.weekday = source["weekday"];
+	        this.day = source["day"];
+	    }
+	}
+	export class ScheduledScan {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    schedule: Schedule;
+	    options: ScanOptions;
+	    // Go type: time
+	    nextRun: any;
+	    // Go type: time
+	    lastRun: any;
+	    lastRecordId?: string;
+	    lastError?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduledScan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.schedule = this.convertValues(source["schedule"], Schedule);
+	        this.options = this.convertValues(source["options"], ScanOptions);
+	        this.nextRun = this.convertValues(source["nextRun"], null);
+	        this.lastRun = this.convertValues(source["lastRun"], null);
+	        this.lastRecordId = source["lastRecordId"];
+	        this.lastError = source["lastError"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SimilarFile {
+	    path: string;
+	    name: string;
+	    size: number;
+	    // Go type: time
+	    modTime: any;
+	    extension: string;
+	    fileType: string;
+	    isValid: boolean;
+	    invalidReason?: string;
+	    findings?: Finding[];
+	    labels?: string[];
+	    metadata?: DocMetadata;
+	    root?: string;
+	    hash?: string;
+	    encrypted?: boolean;
+	    similarity: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SimilarFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
+	        this.extension = source["extension"];
+	        this.fileType = source["fileType"];
+	        this.isValid = source["isValid"];
+	        this.invalidReason = source["invalidReason"];
+	        this.findings = this.convertValues(source["findings"], Finding);
+	        this.labels = source["labels"];
+	        this.metadata = this.convertValues(source["metadata"], DocMetadata);
+	        this.root = source["root"];
+	        this.hash = source["hash"];
+	        this.encrypted = source["encrypted"];
+	        this.similarity = source["similarity"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SimilarGroup {
+	    files: SimilarFile[];
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SimilarGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], SimilarFile);
+	        this.score = source["score"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SimilarityOptions {
+	    method: string;
+	    threshold: number;
+	    shingleSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SimilarityOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.method = source["method"];
+	        this.threshold = source["threshold"];
+	        this.shingleSize = source["shingleSize"];
+	    }
+	}
+	export class SimilarityResult {
+	    groups: SimilarGroup[];
+	    analyzed: number;
+	    skippedFiles: string[];
+	    scanTime: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SimilarityResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groups = this.convertValues(source["groups"], SimilarGroup);
+	        this.analyzed = source["analyzed"];
+	        this.skippedFiles = source["skippedFiles"];
+	        this.scanTime = source["scanTime"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UndoResult {
+	    restored: number;
+	    failed: number;
+	    failedFiles: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UndoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.restored = source["restored"];
+	        this.failed = source["failed"];
+	        this.failedFiles = source["failedFiles"];
+	    }
+	}
 
 }
 
@@ -25,6 +25,13 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
+		OnBeforeClose:    app.beforeClose,
+		// 定时扫描在后台运行时再次启动程序只显示已有的窗口
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               "doc-radar",
+			OnSecondInstanceLaunch: app.onSecondInstanceLaunch,
+		},
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,496 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ArchiveSeparator 压缩包内文件的路径分隔符，如 bundle.zip!/contracts/a.docx
+const ArchiveSeparator = "!/"
+
+// 压缩包类型
+const (
+	archiveZip   = "zip"
+	archiveTar   = "tar"
+	archiveTarGz = "tgz"
+)
+
+const (
+	defaultArchiveDepth = 2         // 默认进入的压缩包层数（最外层加一层嵌套）
+	maxMemoryEntrySize  = 64 << 20  // 小于此大小的嵌套条目直接读入内存，否则写入临时文件
+	maxNestedSize       = 4 << 30   // 嵌套压缩包最大 4GB
+	maxArchiveEntries   = 1_000_000 // 单个压缩包最多处理的条目数
+)
+
+// archiveEntry 压缩包中的文件条目
+type archiveEntry struct {
+	path    string    // 组合路径
+	name    string    // 文件名
+	size    int64     // 解压后大小
+	modTime time.Time // 修改时间
+	open    func() (io.ReadCloser, error)
+}
+
+// docReader 可随机读取的文档数据
+type docReader interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
+// fileReader 本地文件
+type fileReader struct {
+	*os.File
+	size int64
+}
+
+func (f *fileReader) Size() int64 { return f.size }
+
+// memReader 内存中的数据
+type memReader struct {
+	*bytes.Reader
+}
+
+func (m *memReader) Close() error { return nil }
+
+// sharedReader 共用的文档数据，关闭时不释放（由打开者负责）
+type sharedReader struct {
+	docReader
+}
+
+func (sharedReader) Close() error { return nil }
+
+// tempReader 临时文件，关闭时删除
+type tempReader struct {
+	*os.File
+	size int64
+}
+
+func (t *tempReader) Size() int64 { return t.size }
+
+func (t *tempReader) Close() error {
+	err := t.File.Close()
+	os.Remove(t.Name())
+	return err
+}
+
+// IsArchivePath 判断是否是压缩包内文件的组合路径
+func IsArchivePath(p string) bool {
+	return strings.Contains(p, ArchiveSeparator)
+}
+
+// OuterPath 返回组合路径中最外层压缩包的路径，普通路径原样返回
+func OuterPath(p string) string {
+	if i := strings.Index(p, ArchiveSeparator); i >= 0 {
+		return p[:i]
+	}
+	return p
+}
+
+// archiveDirPath 把组合路径转换为普通路径，压缩包作为一级目录（用于导出时保持目录结构）
+func archiveDirPath(p string) string {
+	return strings.ReplaceAll(p, ArchiveSeparator, "/")
+}
+
+// openSource 以流的方式打开文件，支持压缩包内的组合路径和已挂载的存储后端
+func openSource(p string) (io.ReadCloser, error) {
+	if IsArchivePath(p) {
+		rc, _, err := OpenArchiveEntry(p)
+		return rc, err
+	}
+	if m, name := findMount(p); m != nil {
+		return m.FS.Open(name)
+	}
+	return os.Open(p)
+}
+
+// openSourceSized 以流的方式打开文件并返回打开时的大小（扫描后文件可能已经变化）
+func openSourceSized(p string) (io.ReadCloser, int64, error) {
+	if IsArchivePath(p) {
+		return OpenArchiveEntry(p)
+	}
+	rc, err := openSource(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	if f, ok := rc.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		stat, err := f.Stat()
+		if err != nil {
+			rc.Close()
+			return nil, 0, fmt.Errorf("无法获取文件信息: %w", err)
+		}
+		return rc, stat.Size(), nil
+	}
+	return rc, -1, nil
+}
+
+// archiveKind 根据文件名判断压缩包类型
+func archiveKind(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return archiveZip
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGz
+	}
+	return ""
+}
+
+// openDocument 打开文档用于随机读取，支持压缩包内的组合路径和已挂载的存储后端
+func openDocument(p string) (docReader, error) {
+	if !IsArchivePath(p) {
+		if m, name := findMount(p); m != nil {
+			return openMounted(m, name)
+		}
+		file, err := os.Open(p)
+		if err != nil {
+			return nil, fmt.Errorf("无法打开文件: %w", err)
+		}
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("无法获取文件信息: %w", err)
+		}
+		return &fileReader{File: file, size: stat.Size()}, nil
+	}
+
+	rc, size, err := OpenArchiveEntry(p)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return materialize(rc, size)
+}
+
+// materialize 把顺序读取的数据转换为可随机读取的形式（小文件放内存，大文件写临时文件）
+func materialize(r io.Reader, size int64) (docReader, error) {
+	if size > maxNestedSize {
+		return nil, errors.New("压缩包内的文件过大")
+	}
+	if size >= 0 && size <= maxMemoryEntrySize {
+		data, err := io.ReadAll(io.LimitReader(r, size))
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩包内的文件: %w", err)
+		}
+		return &memReader{bytes.NewReader(data)}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "docradar-*")
+	if err != nil {
+		return nil, fmt.Errorf("无法创建临时文件: %w", err)
+	}
+	n, err := io.Copy(tmp, io.LimitReader(r, maxNestedSize))
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("无法读取压缩包内的文件: %w", err)
+	}
+	return &tempReader{File: tmp, size: n}, nil
+}
+
+// multiCloser 按逆序关闭多个资源
+type multiCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (m *multiCloser) Close() error {
+	var firstErr error
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		if err := m.closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// OpenArchiveEntry 以流的方式打开压缩包（可嵌套）中的文件，返回内容和解压后大小
+func OpenArchiveEntry(p string) (io.ReadCloser, int64, error) {
+	segments := strings.Split(p, ArchiveSeparator)
+	if len(segments) < 2 {
+		return nil, 0, fmt.Errorf("不是压缩包内的路径: %s", p)
+	}
+
+	container, err := openDocument(segments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	closers := []io.Closer{container}
+	fail := func(err error) (io.ReadCloser, int64, error) {
+		(&multiCloser{closers: closers}).Close()
+		return nil, 0, err
+	}
+
+	// 当前容器：zip 需要随机读取，tar 只需要顺序读取
+	var stream io.Reader
+	kind := archiveKind(segments[0])
+	for i, name := range segments[1:] {
+		last := i == len(segments)-2
+		if kind == "" {
+			return fail(fmt.Errorf("不支持的压缩包格式: %s", segments[i]))
+		}
+
+		var entry io.Reader
+		var size int64
+		switch kind {
+		case archiveZip:
+			if container == nil {
+				if container, err = materialize(stream, -1); err != nil {
+					return fail(err)
+				}
+				closers = append(closers, container)
+			}
+			zr, err := zip.NewReader(container, container.Size())
+			if err != nil {
+				return fail(fmt.Errorf("无法读取压缩包 %s: %w", segments[i], err))
+			}
+			f, err := findZipEntry(zr, name)
+			if err != nil {
+				return fail(err)
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return fail(fmt.Errorf("无法读取压缩包内的文件 %s: %w", name, err))
+			}
+			closers = append(closers, rc)
+			entry, size = rc, int64(f.UncompressedSize64)
+		default:
+			if stream == nil {
+				stream = io.NewSectionReader(container, 0, container.Size())
+			}
+			tr, closer, err := newTarReader(stream, kind)
+			if err != nil {
+				return fail(err)
+			}
+			if closer != nil {
+				closers = append(closers, closer)
+			}
+			hdr, err := findTarEntry(tr, name)
+			if err != nil {
+				return fail(err)
+			}
+			entry, size = tr, hdr.Size
+		}
+
+		if last {
+			return &multiCloser{Reader: entry, closers: closers}, size, nil
+		}
+
+		// 进入嵌套压缩包
+		kind = archiveKind(name)
+		container, stream = nil, entry
+		if kind == archiveZip {
+			if container, err = materialize(entry, size); err != nil {
+				return fail(err)
+			}
+			closers = append(closers, container)
+		}
+	}
+	return fail(fmt.Errorf("无效的压缩包路径: %s", p))
+}
+
+// findZipEntry 查找 zip 中的条目
+func findZipEntry(zr *zip.Reader, name string) (*zip.File, error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("压缩包中不存在 %s", name)
+}
+
+// newTarReader 创建 tar 读取器，tgz 需要先解压 gzip
+func newTarReader(r io.Reader, kind string) (*tar.Reader, io.Closer, error) {
+	if kind == archiveTarGz {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("无法解压 gzip: %w", err)
+		}
+		return tar.NewReader(gz), gz, nil
+	}
+	return tar.NewReader(r), nil, nil
+}
+
+// findTarEntry 顺序查找 tar 中的条目，找到时 tr 指向该条目的内容
+func findTarEntry(tr *tar.Reader, name string) (*tar.Header, error) {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("压缩包中不存在 %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("无法读取 tar: %w", err)
+		}
+		if cleanEntryName(hdr.Name) == name {
+			return hdr, nil
+		}
+	}
+}
+
+// cleanEntryName 规范化条目名称（tar 中可能带 ./ 前缀）
+func cleanEntryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// archiveWalker 压缩包遍历器
+type archiveWalker struct {
+	skip    func(p string) bool // 跳过的条目（包括嵌套压缩包），可以为空
+	visit   func(entry archiveEntry) error
+	stopErr error // visit 返回的错误，用于区分损坏的嵌套压缩包
+}
+
+// walkArchive 遍历压缩包中的文件，depth 为可以进入的压缩包层数（含最外层）
+// 对于 tar，entry.open 只在回调期间有效；损坏的嵌套压缩包会被跳过；skip 按组合路径排除条目
+func walkArchive(archivePath string, depth int, skip func(p string) bool, visit func(entry archiveEntry) error) error {
+	r, err := openDocument(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w := &archiveWalker{skip: skip}
+	w.visit = func(entry archiveEntry) error {
+		if err := visit(entry); err != nil {
+			w.stopErr = err
+			return err
+		}
+		return nil
+	}
+	if err := w.walk(r, nil, archiveKind(archivePath), archivePath, depth); err != nil {
+		if w.stopErr != nil {
+			return w.stopErr
+		}
+		return err
+	}
+	return nil
+}
+
+// walk 遍历已打开的压缩包，zip 使用 container，tar 使用 stream（为空时从 container 读取）
+func (w *archiveWalker) walk(container docReader, stream io.Reader, kind, archivePath string, depth int) error {
+	if depth <= 0 {
+		return nil
+	}
+
+	// 进入嵌套压缩包
+	descend := func(entry archiveEntry) error {
+		if depth <= 1 {
+			return nil
+		}
+		rc, err := entry.open()
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+
+		nestedKind := archiveKind(entry.name)
+		var nested docReader
+		var nestedStream io.Reader = rc
+		if nestedKind == archiveZip {
+			if nested, err = materialize(rc, entry.size); err != nil {
+				return nil
+			}
+			defer nested.Close()
+			nestedStream = nil
+		}
+		if err := w.walk(nested, nestedStream, nestedKind, entry.path, depth-1); err != nil && w.stopErr != nil {
+			return err
+		}
+		return nil
+	}
+
+	switch kind {
+	case archiveZip:
+		if container == nil {
+			var err error
+			if container, err = materialize(stream, -1); err != nil {
+				return err
+			}
+			defer container.Close()
+		}
+		zr, err := zip.NewReader(container, container.Size())
+		if err != nil {
+			return fmt.Errorf("无法读取压缩包 %s: %w", archivePath, err)
+		}
+		for i, f := range zr.File {
+			if i >= maxArchiveEntries {
+				break
+			}
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			f := f
+			entry := archiveEntry{
+				path:    archivePath + ArchiveSeparator + f.Name,
+				name:    path.Base(f.Name),
+				size:    int64(f.UncompressedSize64),
+				modTime: f.Modified,
+				open:    func() (io.ReadCloser, error) { return f.Open() },
+			}
+			if err := w.handle(entry, descend); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case archiveTar, archiveTarGz:
+		if stream == nil {
+			stream = io.NewSectionReader(container, 0, container.Size())
+		}
+		tr, closer, err := newTarReader(stream, kind)
+		if err != nil {
+			return err
+		}
+		if closer != nil {
+			defer closer.Close()
+		}
+		for i := 0; i < maxArchiveEntries; i++ {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("无法读取压缩包 %s: %w", archivePath, err)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			name := cleanEntryName(hdr.Name)
+			entry := archiveEntry{
+				path:    archivePath + ArchiveSeparator + name,
+				name:    path.Base(name),
+				size:    hdr.Size,
+				modTime: hdr.ModTime,
+				open:    func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+			}
+			if err := w.handle(entry, descend); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("不支持的压缩包格式: %s", archivePath)
+}
+
+// handle 处理一个条目：嵌套压缩包继续遍历，其他文件交给 visit
+func (w *archiveWalker) handle(entry archiveEntry, descend func(entry archiveEntry) error) error {
+	if w.skip != nil && w.skip(entry.path) {
+		return nil
+	}
+	if archiveKind(entry.name) != "" {
+		return descend(entry)
+	}
+	return w.visit(entry)
+}
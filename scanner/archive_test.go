@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// zipBytes 生成包含给定文件的 zip 数据
+func zipBytes(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tgzBytes 生成包含给定文件的 tar.gz 数据
+func tgzBytes(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(data)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gw.Close()
+	return buf.Bytes()
+}
+
+func TestScanArchiveEntries(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+	contacts := []byte("name,phone\n张三,13812345678\n")
+	encrypted := bytes.Replace(testPDF(0), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt 2 0 R"), 1)
+	archive := writeTestFile(t, dir, "bundle.tgz", tgzBytes(t, map[string][]byte{
+		"docs/contacts.csv":     contacts,
+		"docs/secret.pdf":       encrypted,
+		"docs/plain.pdf":        testPDF(128),
+		"node_modules/x.pdf":    testPDF(0),
+		"skipme/hidden.csv":     []byte("a,b\n"),
+		"docs/broken.pdf":       []byte("%PDF-1.4 truncated"),
+		"docs/nested/more.zip":  zipBytes(t, map[string][]byte{"report.csv": []byte("季度报告,1\n"), "skipme/b.csv": []byte("x\n")}),
+		"docs/skipme/inner.zip": zipBytes(t, map[string][]byte{"c.csv": []byte("x\n")}),
+	}))
+
+	result, err := NewScanner().Scan(ScanOptions{
+		RootPath:         dir,
+		ExcludePaths:     []string{"skipme"},
+		ValidateFiles:    true,
+		ComputeHash:      true,
+		ScanArchives:     true,
+		DetectEncryption: true,
+		InspectContent:   true,
+		Rules: []ClassifyRule{{
+			Name:       "报告",
+			Labels:     []string{"报告"},
+			Conditions: []RuleCondition{{Field: FieldContent, Op: OpContains, Value: "季度报告"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]FileInfo{}
+	for _, f := range result.Files {
+		got[strings.TrimPrefix(f.Path, archive+ArchiveSeparator)] = f
+	}
+	want := []string{"docs/contacts.csv", "docs/secret.pdf", "docs/plain.pdf", "docs/broken.pdf", "docs/nested/more.zip" + ArchiveSeparator + "report.csv"}
+	if len(got) != len(want) {
+		var names []string
+		for name := range got {
+			names = append(names, name)
+		}
+		t.Fatalf("scanned %v, want %v", names, want)
+	}
+	for _, name := range want {
+		if _, ok := got[name]; !ok {
+			t.Errorf("%s not found", name)
+		}
+	}
+
+	csv := got["docs/contacts.csv"]
+	sum := sha256.Sum256(contacts)
+	if csv.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("contacts.csv hash = %s", csv.Hash)
+	}
+	if len(csv.Findings) != 1 || csv.Findings[0].Type != FindingMobile {
+		t.Errorf("contacts.csv findings = %+v, want one mobile", csv.Findings)
+	}
+	if !got["docs/secret.pdf"].Encrypted || got["docs/plain.pdf"].Encrypted {
+		t.Errorf("encrypted: secret.pdf %v, plain.pdf %v", got["docs/secret.pdf"].Encrypted, got["docs/plain.pdf"].Encrypted)
+	}
+	if broken := got["docs/broken.pdf"]; broken.IsValid || broken.Encrypted {
+		t.Errorf("broken.pdf = %+v, want invalid", broken)
+	}
+	report := got["docs/nested/more.zip"+ArchiveSeparator+"report.csv"]
+	if len(report.Labels) != 1 || report.Labels[0] != "报告" {
+		t.Errorf("report.csv labels = %v", report.Labels)
+	}
+}
@@ -0,0 +1,268 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 导出检查点格式：每行一个 JSON，第一行是导出选项，之后每导出完成一个文件追加一行。
+// 只保留最近一次导出到文件夹的检查点，全部成功后删除；取消、失败或程序中途退出时保留，用于继续导出
+
+// 导出检查点版本
+const exportCheckpointVersion = 1
+
+// 导出中的文件使用的临时文件名后缀，完成后重命名为正式文件名
+const exportPartSuffix = ".part"
+
+// ResumableExport 可以继续的上一次导出
+type ResumableExport struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	DestPath  string    `json:"destPath"`
+	Total     int       `json:"total"`     // 要导出的文件数
+	Completed int       `json:"completed"` // 已完成的文件数
+}
+
+// checkpointLine 检查点中的一行：第一行为导出信息，之后为已完成的文件
+type checkpointLine struct {
+	Version int            `json:"version,omitempty"`
+	ID      string         `json:"id,omitempty"`
+	Time    time.Time      `json:"time,omitempty"`
+	Total   int            `json:"total,omitempty"`
+	Options *ExportOptions `json:"options,omitempty"`
+
+	Rel  string `json:"rel,omitempty"`  // 已完成文件的导出路径（相对于目标目录）
+	Size int64  `json:"size,omitempty"` // 导出的字节数
+	Hash string `json:"hash,omitempty"` // 开启校验时记录的 SHA-256
+}
+
+// exportCheckpoint 正在写入的检查点
+type exportCheckpoint struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *bufio.Writer
+}
+
+var checkpointMu sync.Mutex
+
+// checkpointPath 返回导出检查点文件路径
+func checkpointPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "export-checkpoint.jsonl"), nil
+}
+
+// exportPartPath 返回导出中的临时文件路径：与目标文件在同一目录（重命名不跨文件系统），
+// 隐藏文件名取目标文件名的哈希，不受文件名长度限制
+func exportPartPath(dst string) string {
+	sum := sha256.Sum256([]byte(filepath.Base(dst)))
+	return filepath.Join(filepath.Dir(dst), ".docradar-"+hex.EncodeToString(sum[:8])+exportPartSuffix)
+}
+
+// createCheckpoint 创建新的检查点，替换上一次导出的检查点
+func createCheckpoint(options ExportOptions, total int) (*exportCheckpoint, error) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+
+	path, err := checkpointPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建导出检查点: %w", err)
+	}
+	c := &exportCheckpoint{f: f, w: bufio.NewWriter(f)}
+	now := time.Now()
+	header := checkpointLine{
+		Version: exportCheckpointVersion,
+		ID:      now.Format("20060102-150405"),
+		Time:    now,
+		Total:   total,
+		Options: &options,
+	}
+	if err := c.append(header); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return c, nil
+}
+
+// append 追加一行并同步到磁盘
+func (c *exportCheckpoint) append(line checkpointLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.w.Write(data)
+	c.w.WriteByte('\n')
+	if err := c.w.Flush(); err != nil {
+		return fmt.Errorf("无法写入导出检查点: %w", err)
+	}
+	return c.f.Sync()
+}
+
+// record 记录一个已完成的文件，c 为 nil（检查点无法创建）时不记录
+func (c *exportCheckpoint) record(rel string, size int64, hash string) {
+	if c == nil {
+		return
+	}
+	// 写入失败只影响继续导出时是否需要重新校验，不中断导出
+	c.append(checkpointLine{Rel: rel, Size: size, Hash: hash})
+}
+
+// finish 关闭检查点，导出全部完成时删除
+func (c *exportCheckpoint) finish(complete bool) {
+	if c == nil {
+		return
+	}
+	c.f.Close()
+	if complete {
+		checkpointMu.Lock()
+		os.Remove(c.f.Name())
+		checkpointMu.Unlock()
+	}
+}
+
+// readCheckpoint 读取检查点，返回导出信息和已完成的文件，没有检查点时返回 nil
+func readCheckpoint() (*checkpointLine, map[string]checkpointLine, error) {
+	path, err := checkpointPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法读取导出检查点: %w", err)
+	}
+	defer f.Close()
+
+	// 第一行包含完整的文件列表，可能很长，使用 json.Decoder 而不是按行读取
+	dec := json.NewDecoder(f)
+	var header checkpointLine
+	if err := dec.Decode(&header); err != nil || header.Options == nil {
+		return nil, nil, errors.New("导出检查点格式错误")
+	}
+	if header.Version > exportCheckpointVersion {
+		return nil, nil, fmt.Errorf("不支持的导出检查点版本: %d", header.Version)
+	}
+	done := make(map[string]checkpointLine)
+	for {
+		var line checkpointLine
+		if err := dec.Decode(&line); err != nil {
+			// 读到结尾，或程序中途退出时最后一行不完整
+			break
+		}
+		if line.Rel != "" {
+			done[line.Rel] = line
+		}
+	}
+	return &header, done, nil
+}
+
+// GetResumableExport 获取可以继续的上一次导出，没有时返回 nil
+func GetResumableExport() (*ResumableExport, error) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+
+	header, done, err := readCheckpoint()
+	if err != nil || header == nil {
+		return nil, err
+	}
+	return &ResumableExport{
+		ID:        header.ID,
+		Time:      header.Time,
+		DestPath:  header.Options.DestPath,
+		Total:     header.Total,
+		Completed: len(done),
+	}, nil
+}
+
+// DiscardResumableExport 放弃继续上一次导出，删除检查点和目标目录中残留的临时文件
+func DiscardResumableExport() error {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+
+	header, _, err := readCheckpoint()
+	if err != nil || header == nil {
+		if path, perr := checkpointPath(); perr == nil {
+			os.Remove(path)
+		}
+		return err
+	}
+	if items, err := planExport(*header.Options, &ExportResult{}); err == nil {
+		if destRoot, err := filepath.Abs(header.Options.DestPath); err == nil {
+			for _, item := range items {
+				os.Remove(exportPartPath(filepath.Join(destRoot, filepath.FromSlash(item.rel))))
+			}
+		}
+	}
+	path, err := checkpointPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("无法删除导出检查点: %w", err)
+	}
+	return nil
+}
+
+// ResumeLastExport 继续上一次中断的导出：使用相同的选项重新导出，目标位置已完整导出的文件直接跳过（见 resumeComplete）
+func (e *Exporter) ResumeLastExport(ctx context.Context) (*ExportResult, error) {
+	checkpointMu.Lock()
+	header, done, err := readCheckpoint()
+	checkpointMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("没有可以继续的导出")
+	}
+	return e.export(ctx, *header.Options, done)
+}
+
+// resumeComplete 继续导出时判断目标文件是否已完整导出，返回记录到新检查点的哈希和大小。
+// 只有检查点中记录过的文件才按大小判断（临时文件重命名后才写入记录，大小一致的文件内容是完整的）；
+// 没有记录的文件（重命名后、写入检查点前程序退出，或目标位置原有同名文件）比较两边的哈希
+func resumeComplete(item exportItem, dst string, done map[string]checkpointLine, verify bool) (string, int64, bool) {
+	info, err := os.Stat(dst)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", 0, false
+	}
+	rec, ok := done[item.rel]
+	if ok && info.Size() != rec.Size {
+		return "", 0, false
+	}
+	if ok && !verify && rec.Hash == "" {
+		return "", info.Size(), true
+	}
+
+	dstHash, err := hashReader(func() (io.ReadCloser, error) { return os.Open(dst) })
+	if err != nil {
+		return "", 0, false
+	}
+	want := rec.Hash
+	if want == "" {
+		if want, err = hashReader(func() (io.ReadCloser, error) { return openSource(item.file.Path) }); err != nil {
+			return "", 0, false
+		}
+	}
+	return dstHash, info.Size(), dstHash == want
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestResumeComplete(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+	src := writeTestFile(t, dir, "src/a.pdf", []byte("source content"))
+	item := exportItem{file: FileInfo{Path: src, Size: int64(len("source content"))}, rel: "a.pdf"}
+	recorded := map[string]checkpointLine{"a.pdf": {Rel: "a.pdf", Size: int64(len("source content"))}}
+
+	tests := []struct {
+		name     string
+		dst      string // 目标文件内容，为空表示不存在
+		done     map[string]checkpointLine
+		verify   bool
+		want     bool
+		wantHash string
+	}{
+		{"missing", "", recorded, false, false, ""},
+		{"recorded same size", "source content", recorded, false, true, ""},
+		{"recorded wrong size", "source", recorded, false, false, ""},
+		{"recorded and verified", "source content", recorded, true, true, sha256Hex("source content")},
+		{"recorded and verified mismatch", "SOURCE CONTENT", recorded, true, false, ""},
+		{"recorded hash mismatch", "SOURCE CONTENT", map[string]checkpointLine{"a.pdf": {Rel: "a.pdf", Size: 14, Hash: sha256Hex("source content")}}, false, false, ""},
+		{"unrecorded same content", "source content", map[string]checkpointLine{}, false, true, sha256Hex("source content")},
+		// 没有记录时大小相同不能说明内容完整
+		{"unrecorded same size", "SOURCE CONTENT", map[string]checkpointLine{}, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "a.pdf")
+			if tt.dst != "" {
+				writeTestFile(t, filepath.Dir(dst), "a.pdf", []byte(tt.dst))
+			}
+			hash, size, ok := resumeComplete(item, dst, tt.done, tt.verify)
+			if ok != tt.want {
+				t.Fatalf("resumeComplete = %v, want %v", ok, tt.want)
+			}
+			if ok && (size != int64(len(tt.dst)) || hash != tt.wantHash) {
+				t.Errorf("resumeComplete = %q, %d; want %q, %d", hash, size, tt.wantHash, len(tt.dst))
+			}
+		})
+	}
+}
+
+func TestResumeLastExport(t *testing.T) {
+	src, dst, files := moveFixture(t, "a.pdf", "b.pdf", "c.pdf")
+	options := ExportOptions{DestPath: dst, Files: files}
+
+	// 模拟中断的导出：a 已完成并记录，b 已重命名但没来得及记录，c 的位置被其他文件占用
+	checkpoint, err := createCheckpoint(options, len(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dst, "a.pdf", []byte("content of a.pdf"))
+	checkpoint.record("a.pdf", files[0].Size, "")
+	writeTestFile(t, dst, "b.pdf", []byte("content of b.pdf"))
+	writeTestFile(t, dst, "c.pdf", []byte("CONTENT OF C.PDF"))
+	checkpoint.finish(false)
+
+	resumable, err := GetResumableExport()
+	if err != nil || resumable == nil || resumable.Total != 3 || resumable.Completed != 1 {
+		t.Fatalf("GetResumableExport = %+v, %v", resumable, err)
+	}
+	result, err := NewExporter().ResumeLastExport(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Resumed != 2 || len(result.SkippedFiles) != 1 || result.SkippedFiles[0] != filepath.Join(src, "c.pdf") {
+		t.Errorf("ResumeLastExport = resumed %d, skipped %v; want 2 and c.pdf", result.Resumed, result.SkippedFiles)
+	}
+	assertContent(t, filepath.Join(dst, "c.pdf"), "CONTENT OF C.PDF")
+	if resumable, _ := GetResumableExport(); resumable != nil {
+		t.Errorf("checkpoint kept after a complete export: %+v", resumable)
+	}
+}
+
+func TestExportWithoutCheckpoint(t *testing.T) {
+	_, dst, files := moveFixture(t, "a.pdf")
+	path, err := checkpointPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 检查点路径被目录占用，无法创建检查点
+	if err := os.MkdirAll(filepath.Join(path, "x"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewExporter().Export(context.Background(), ExportOptions{DestPath: dst, Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 1 || result.CheckpointErr == "" {
+		t.Errorf("Export = success %d, checkpointErr %q; want 1 and a warning", result.Success, result.CheckpointErr)
+	}
+	assertContent(t, filepath.Join(dst, "a.pdf"), "content of a.pdf")
+}
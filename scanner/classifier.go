@@ -0,0 +1,425 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 规则条件字段
+const (
+	FieldPath    = "path"    // 完整路径（分隔符统一为 /）
+	FieldName    = "name"    // 文件名
+	FieldExt     = "ext"     // 扩展名（如 .pdf）
+	FieldType    = "type"    // 文件类型（pdf, word, excel, ppt）
+	FieldSize    = "size"    // 文件大小，支持 KB/MB/GB 单位
+	FieldModTime = "modTime" // 修改时间，格式 2006-01-02
+	FieldValid   = "valid"   // 是否有效（true/false）
+	FieldPages   = "pages"   // 页数（需要提取元数据）
+	FieldAuthor  = "author"  // 作者（需要提取元数据）
+	FieldTitle   = "title"   // 标题（需要提取元数据）
+	FieldContent = "content" // 文档正文（需要提取文本）
+	FieldFinding = "finding" // 敏感信息类型
+)
+
+// 规则条件运算符
+const (
+	OpContains = "contains" // 包含（忽略大小写）
+	OpEquals   = "equals"   // 等于（忽略大小写）
+	OpMatches  = "matches"  // 正则匹配
+	OpGreater  = "gt"       // 大于
+	OpLess     = "lt"       // 小于
+)
+
+// 规则文件版本
+const rulesFileVersion = 1
+
+// RuleCondition 规则条件
+type RuleCondition struct {
+	Field  string `json:"field"`
+	Op     string `json:"op"`
+	Value  string `json:"value"`
+	Negate bool   `json:"negate"` // 条件取反
+}
+
+// ClassifyRule 分类规则：条件满足时为文件添加标签
+type ClassifyRule struct {
+	Name       string          `json:"name"`
+	Labels     []string        `json:"labels"`
+	Match      string          `json:"match"` // all（全部满足，默认）或 any（任一满足）
+	Conditions []RuleCondition `json:"conditions"`
+}
+
+// rulesFile 规则文件格式
+type rulesFile struct {
+	Version int            `json:"version"`
+	Rules   []ClassifyRule `json:"rules"`
+}
+
+// compiledCondition 预处理后的条件
+type compiledCondition struct {
+	RuleCondition
+	re     *regexp.Regexp
+	number float64
+	date   time.Time
+}
+
+// compiledRule 预处理后的规则
+type compiledRule struct {
+	ClassifyRule
+	conditions []compiledCondition
+}
+
+// Classifier 基于规则的文档分类器
+type Classifier struct {
+	rules        []compiledRule
+	needMetadata bool
+	needContent  bool
+}
+
+// NewClassifier 创建分类器，规则无效时返回错误
+func NewClassifier(rules []ClassifyRule) (*Classifier, error) {
+	c := &Classifier{}
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(i+1)
+		}
+		if len(rule.Labels) == 0 {
+			return nil, fmt.Errorf("规则 %s 未指定标签", name)
+		}
+		if len(rule.Conditions) == 0 {
+			return nil, fmt.Errorf("规则 %s 没有条件", name)
+		}
+		if rule.Match != "" && rule.Match != "all" && rule.Match != "any" {
+			return nil, fmt.Errorf("规则 %s 的匹配方式无效: %s", name, rule.Match)
+		}
+
+		compiled := compiledRule{ClassifyRule: rule}
+		for _, cond := range rule.Conditions {
+			cc, err := compileCondition(cond)
+			if err != nil {
+				return nil, fmt.Errorf("规则 %s: %w", name, err)
+			}
+			switch cond.Field {
+			case FieldPages, FieldAuthor, FieldTitle:
+				c.needMetadata = true
+			case FieldContent:
+				c.needContent = true
+			}
+			compiled.conditions = append(compiled.conditions, cc)
+		}
+		c.rules = append(c.rules, compiled)
+	}
+	return c, nil
+}
+
+// compileCondition 校验条件并预处理正则、数字和日期
+func compileCondition(cond RuleCondition) (compiledCondition, error) {
+	cc := compiledCondition{RuleCondition: cond}
+
+	switch cond.Field {
+	case FieldPath, FieldName, FieldExt, FieldType, FieldSize, FieldModTime, FieldValid,
+		FieldPages, FieldAuthor, FieldTitle, FieldContent, FieldFinding:
+	default:
+		return cc, fmt.Errorf("未知的条件字段: %s", cond.Field)
+	}
+
+	switch cond.Op {
+	case OpContains, OpEquals:
+	case OpMatches:
+		re, err := regexp.Compile("(?i)" + cond.Value)
+		if err != nil {
+			return cc, fmt.Errorf("无效的正则表达式 %s: %w", cond.Value, err)
+		}
+		cc.re = re
+	case OpGreater, OpLess:
+		switch cond.Field {
+		case FieldSize:
+			size, err := ParseSize(cond.Value)
+			if err != nil {
+				return cc, err
+			}
+			cc.number = float64(size)
+		case FieldPages:
+			n, err := strconv.ParseFloat(cond.Value, 64)
+			if err != nil {
+				return cc, fmt.Errorf("无效的数字: %s", cond.Value)
+			}
+			cc.number = n
+		case FieldModTime:
+			date, err := time.ParseInLocation("2006-01-02", cond.Value, time.Local)
+			if err != nil {
+				return cc, fmt.Errorf("无效的日期: %s", cond.Value)
+			}
+			cc.date = date
+		default:
+			return cc, fmt.Errorf("字段 %s 不支持比较运算", cond.Field)
+		}
+	default:
+		return cc, fmt.Errorf("未知的运算符: %s", cond.Op)
+	}
+
+	return cc, nil
+}
+
+// ParseSize 解析带单位的文件大小，如 10MB、512KB、1.5GB
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	scale := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(value, u.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			scale = u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的文件大小: %s", s)
+	}
+	return int64(n * scale), nil
+}
+
+// Classify 为文件计算标签，写入 file.Labels；需要时提取元数据和正文
+func (c *Classifier) Classify(file *FileInfo) {
+	if len(c.rules) == 0 {
+		return
+	}
+	c.classify(file, func() *DocMetadata {
+		metadata, _ := ExtractMetadata(file.Path)
+		return metadata
+	}, func() string {
+		text, _ := ExtractText(file.Path, 0)
+		return text
+	})
+}
+
+// needsDocument 规则是否需要读取文档内容（元数据或正文）
+func (c *Classifier) needsDocument() bool {
+	return c.needMetadata || c.needContent
+}
+
+// classifyDocument 使用已打开的文档和已提取的正文分类，doc 为 nil 时不提取元数据
+func (c *Classifier) classifyDocument(file *FileInfo, doc docReader, text func() string) {
+	if len(c.rules) == 0 {
+		return
+	}
+	c.classify(file, func() *DocMetadata {
+		if doc == nil {
+			return nil
+		}
+		metadata, _ := extractDocumentMetadata(doc)
+		return metadata
+	}, text)
+}
+
+// classify 计算标签，元数据和正文只在规则需要时获取一次
+func (c *Classifier) classify(file *FileInfo, metadata func() *DocMetadata, text func() string) {
+	if c.needMetadata && file.Metadata == nil {
+		file.Metadata = metadata()
+	}
+	var content *string
+	getContent := func() string {
+		if content == nil {
+			t := text()
+			content = &t
+		}
+		return *content
+	}
+
+	seen := make(map[string]bool, len(file.Labels))
+	for _, label := range file.Labels {
+		seen[label] = true
+	}
+	for _, rule := range c.rules {
+		if !rule.matches(file, getContent) {
+			continue
+		}
+		for _, label := range rule.Labels {
+			if !seen[label] {
+				seen[label] = true
+				file.Labels = append(file.Labels, label)
+			}
+		}
+	}
+}
+
+// ClassifyFiles 就地对文件列表重新分类（清除原有标签），返回每个标签涉及的文件数
+func (c *Classifier) ClassifyFiles(files []FileInfo) map[string]int {
+	for i := range files {
+		files[i].Labels = nil
+		c.Classify(&files[i])
+	}
+	return countLabels(files)
+}
+
+// matches 判断规则是否匹配
+func (r *compiledRule) matches(file *FileInfo, content func() string) bool {
+	matchAny := r.Match == "any"
+	for _, cond := range r.conditions {
+		ok := cond.matches(file, content)
+		if matchAny && ok {
+			return true
+		}
+		if !matchAny && !ok {
+			return false
+		}
+	}
+	return !matchAny
+}
+
+// matches 判断条件是否满足
+func (c *compiledCondition) matches(file *FileInfo, content func() string) bool {
+	return c.evaluate(file, content) != c.Negate
+}
+
+func (c *compiledCondition) evaluate(file *FileInfo, content func() string) bool {
+	switch c.Field {
+	case FieldSize:
+		return c.compareNumber(float64(file.Size), strconv.FormatInt(file.Size, 10))
+	case FieldPages:
+		pages := 0
+		if file.Metadata != nil {
+			pages = file.Metadata.Pages
+		}
+		return c.compareNumber(float64(pages), strconv.Itoa(pages))
+	case FieldModTime:
+		switch c.Op {
+		case OpGreater:
+			return file.ModTime.After(c.date)
+		case OpLess:
+			return file.ModTime.Before(c.date)
+		}
+		return c.compareText(file.ModTime.Format("2006-01-02"))
+	case FieldFinding:
+		for _, finding := range file.Findings {
+			if c.compareText(finding.Type) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var text string
+	switch c.Field {
+	case FieldPath:
+		text = strings.ReplaceAll(file.Path, "\\", "/")
+	case FieldName:
+		text = file.Name
+	case FieldExt:
+		text = file.Extension
+	case FieldType:
+		text = file.FileType
+	case FieldValid:
+		text = strconv.FormatBool(file.IsValid)
+	case FieldAuthor:
+		if file.Metadata != nil {
+			text = file.Metadata.Author
+		}
+	case FieldTitle:
+		if file.Metadata != nil {
+			text = file.Metadata.Title
+		}
+	case FieldContent:
+		text = content()
+	}
+	return c.compareText(text)
+}
+
+// compareNumber 数字比较，等于/包含/匹配按文本处理
+func (c *compiledCondition) compareNumber(value float64, text string) bool {
+	switch c.Op {
+	case OpGreater:
+		return value > c.number
+	case OpLess:
+		return value < c.number
+	}
+	return c.compareText(text)
+}
+
+// compareText 文本比较
+func (c *compiledCondition) compareText(text string) bool {
+	switch c.Op {
+	case OpContains:
+		return strings.Contains(strings.ToLower(text), strings.ToLower(c.Value))
+	case OpEquals:
+		return strings.EqualFold(text, c.Value)
+	case OpMatches:
+		return c.re.MatchString(text)
+	}
+	return false
+}
+
+// HasLabel 判断文件是否带有任一指定标签
+func (f FileInfo) HasLabel(labels []string) bool {
+	for _, have := range f.Labels {
+		for _, want := range labels {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countLabels 统计每个标签涉及的文件数
+func countLabels(files []FileInfo) map[string]int {
+	counts := make(map[string]int)
+	for _, file := range files {
+		for _, label := range file.Labels {
+			counts[label]++
+		}
+	}
+	return counts
+}
+
+// LoadRules 从文件加载分类规则
+func LoadRules(path string) ([]ClassifyRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取规则文件: %w", err)
+	}
+
+	var rf rulesFile
+	if err := json.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("规则文件格式错误: %w", err)
+	}
+	if rf.Version > rulesFileVersion {
+		return nil, fmt.Errorf("不支持的规则文件版本: %d", rf.Version)
+	}
+	if _, err := NewClassifier(rf.Rules); err != nil {
+		return nil, err
+	}
+	if rf.Rules == nil {
+		rf.Rules = make([]ClassifyRule, 0)
+	}
+	return rf.Rules, nil
+}
+
+// SaveRules 保存分类规则到文件
+func SaveRules(path string, rules []ClassifyRule) error {
+	if _, err := NewClassifier(rules); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(rulesFile{Version: rulesFileVersion, Rules: rules}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("无法保存规则文件: %w", err)
+	}
+	return nil
+}
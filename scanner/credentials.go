@@ -0,0 +1,258 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 凭据文件版本
+const credentialsFileVersion = 1
+
+// Credential 远程存储的登录凭据
+type Credential struct {
+	URL      string `json:"url"`                // 适用的地址，如 sftp://nas.local 或 webdav://admin@nas.local:5005
+	Username string `json:"username"`           // 用户名（地址中带用户名时以地址为准）
+	Password string `json:"password,omitempty"` // 密码（S3 为 Secret Key）
+	KeyFile  string `json:"keyFile,omitempty"`  // SFTP 私钥文件
+	HostKey  string `json:"hostKey,omitempty"`  // 已确认的 SFTP 主机公钥指纹
+
+	secret []byte // 无法解密的密码密文，写回凭据文件时原样保留
+}
+
+// storedCredential 凭据文件中的凭据，密码加密后保存在 Secret 中
+type storedCredential struct {
+	Credential
+	Secret []byte `json:"secret,omitempty"`
+}
+
+// credentialsFile 凭据文件格式
+type credentialsFile struct {
+	Version     int                `json:"version"`
+	Credentials []storedCredential `json:"credentials"`
+}
+
+var credentialsMu sync.Mutex
+
+// configDir 返回应用配置目录（不存在时创建）
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取配置目录: %w", err)
+	}
+	dir = filepath.Join(dir, "DocRadar")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("无法创建配置目录: %w", err)
+	}
+	return dir, nil
+}
+
+// credentialKey 把地址规范化为 scheme://[user@]host[:port]，用于匹配凭据
+func credentialKey(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("无效的远程地址: %s", rawURL)
+	}
+	key := strings.ToLower(u.Scheme) + "://"
+	if u.User != nil && u.User.Username() != "" {
+		key += u.User.Username() + "@"
+	}
+	return key + strings.ToLower(u.Host), nil
+}
+
+// credentialsPath 返回凭据文件路径
+func credentialsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials.json"), nil
+}
+
+// readCredentials 读取凭据文件并解密密码，文件不存在时返回空列表。
+// 无法解密的密码（如密钥文件丢失）按未保存密码处理，但保留原密文，保存其他凭据时不会把它覆盖为空密码
+func readCredentials() ([]Credential, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取凭据文件: %w", err)
+	}
+
+	var cf credentialsFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("凭据文件格式错误: %w", err)
+	}
+	if cf.Version > credentialsFileVersion {
+		return nil, fmt.Errorf("不支持的凭据文件版本: %d", cf.Version)
+	}
+
+	creds := make([]Credential, len(cf.Credentials))
+	for i, sc := range cf.Credentials {
+		creds[i] = sc.Credential
+		if plain, err := unprotectSecret(sc.Secret); err == nil {
+			creds[i].Password = string(plain)
+		} else {
+			creds[i].secret = sc.Secret
+		}
+	}
+	return creds, nil
+}
+
+// writeCredentials 加密密码后写入凭据文件，只有当前用户可读写
+func writeCredentials(creds []Credential) error {
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	cf := credentialsFile{Version: credentialsFileVersion, Credentials: make([]storedCredential, len(creds))}
+	for i, c := range creds {
+		secret := c.secret
+		if secret == nil {
+			var err error
+			if secret, err = protectSecret([]byte(c.Password)); err != nil {
+				return err
+			}
+		}
+		c.Password = ""
+		cf.Credentials[i] = storedCredential{Credential: c, Secret: secret}
+	}
+	data, err := json.MarshalIndent(cf, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("无法保存凭据: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法保存凭据: %w", err)
+	}
+	return nil
+}
+
+// ListCredentials 列出已保存的凭据（不包含密码）
+func ListCredentials() ([]Credential, error) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Credential, len(creds))
+	for i, c := range creds {
+		c.Password = ""
+		result[i] = c
+	}
+	return result, nil
+}
+
+// SaveCredential 保存凭据，同一地址的凭据会被替换（保留已记录的主机公钥）
+func SaveCredential(cred Credential) error {
+	key, err := credentialKey(cred.URL)
+	if err != nil {
+		return err
+	}
+	cred.URL = key
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	for i, c := range creds {
+		if c.URL == key {
+			if cred.HostKey == "" {
+				cred.HostKey = c.HostKey
+			}
+			creds[i] = cred
+			return writeCredentials(creds)
+		}
+	}
+	return writeCredentials(append(creds, cred))
+}
+
+// DeleteCredential 删除指定地址的凭据
+func DeleteCredential(rawURL string) error {
+	key, err := credentialKey(rawURL)
+	if err != nil {
+		return err
+	}
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	kept := creds[:0]
+	for _, c := range creds {
+		if c.URL != key {
+			kept = append(kept, c)
+		}
+	}
+	return writeCredentials(kept)
+}
+
+// findCredential 查找地址对应的凭据，优先匹配带用户名的地址
+func findCredential(u *url.URL) (Credential, bool) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return Credential{}, false
+	}
+
+	keys := []string{}
+	if key, err := credentialKey(u.String()); err == nil {
+		keys = append(keys, key)
+	}
+	keys = append(keys, strings.ToLower(u.Scheme)+"://"+strings.ToLower(u.Host))
+	for _, key := range keys {
+		for _, c := range creds {
+			if c.URL == key {
+				return c, true
+			}
+		}
+	}
+	return Credential{}, false
+}
+
+// recordHostKey 记录用户确认的 SFTP 主机公钥指纹
+func recordHostKey(rawURL, fingerprint string) error {
+	key, err := credentialKey(rawURL)
+	if err != nil {
+		return err
+	}
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	creds, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	for i, c := range creds {
+		if c.URL == key {
+			creds[i].HostKey = fingerprint
+			return writeCredentials(creds)
+		}
+	}
+	return writeCredentials(append(creds, Credential{URL: key, HostKey: fingerprint}))
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// PDF 中检查加密字典时读取的头部和尾部长度（线性化文件的 trailer 位于开头）
+const pdfEncryptScanSize = 4096
+
+// IsEncrypted 检测文档是否设置了打开密码，支持扫描结果中的任意路径（包括压缩包内的文件）
+func IsEncrypted(path string, fileType string) (bool, error) {
+	doc, err := openDocument(path)
+	if err != nil {
+		return false, err
+	}
+	defer doc.Close()
+	return DetectEncryption(doc, doc.Size(), fileType), nil
+}
+
+// DetectEncryption 检测可随机读取的文档数据是否加密
+func DetectEncryption(r io.ReaderAt, size int64, fileType string) bool {
+	header := make([]byte, 8)
+	if n, _ := r.ReadAt(header, 0); n < len(header) {
+		return false
+	}
+
+	switch {
+	case fileType == "pdf" && bytes.HasPrefix(header, pdfMagic):
+		return pdfEncrypted(r, size)
+	case bytes.HasPrefix(header, oleMagic):
+		// 加密的 OOXML 文档（docx、xlsx、pptx）也保存为复合文档
+		return oleEncrypted(r, size, fileType)
+	}
+	return false
+}
+
+// pdfEncrypted 检查 trailer 中是否引用了加密字典
+func pdfEncrypted(r io.ReaderAt, size int64) bool {
+	n := min(size, pdfEncryptScanSize)
+	head := make([]byte, n)
+	r.ReadAt(head, 0)
+	if bytes.Contains(head, []byte("/Encrypt")) {
+		return true
+	}
+	tail := make([]byte, n)
+	r.ReadAt(tail, size-n)
+	return bytes.Contains(tail, []byte("/Encrypt"))
+}
+
+// oleEncrypted 检查复合文档的加密标记
+func oleEncrypted(r io.ReaderAt, size int64, fileType string) bool {
+	ole, err := openOLE(r, size)
+	if err != nil {
+		return false
+	}
+	// 使用密码保护的 OOXML 文档和 PowerPoint 97-2003 的加密摘要
+	if ole.hasStream("EncryptionInfo") || ole.hasStream("EncryptedPackage") || ole.hasStream("EncryptedSummary") {
+		return true
+	}
+
+	switch fileType {
+	case "word":
+		// FIB 中 fEncrypted 标记位于偏移 0x0A 的标志字
+		fib, err := ole.readStream("WordDocument")
+		if err != nil || len(fib) < 0x0C {
+			return false
+		}
+		return binary.LittleEndian.Uint16(fib[0x0A:])&0x0100 != 0
+	case "excel":
+		stream, err := ole.readStream("Workbook")
+		if err != nil {
+			if stream, err = ole.readStream("Book"); err != nil {
+				return false
+			}
+		}
+		return biffHasFilePass(stream)
+	}
+	return false
+}
+
+// BIFF 记录类型
+const (
+	biffEOF      = 0x000A
+	biffFilePass = 0x002F
+)
+
+// biffHasFilePass 检查工作簿全局子流中是否有 FILEPASS 记录（位于第一个 EOF 之前）
+func biffHasFilePass(stream []byte) bool {
+	for off := 0; off+4 <= len(stream); {
+		recType := binary.LittleEndian.Uint16(stream[off:])
+		recLen := int(binary.LittleEndian.Uint16(stream[off+2:]))
+		switch recType {
+		case biffFilePass:
+			return true
+		case biffEOF:
+			return false
+		}
+		off += 4 + recLen
+	}
+	return false
+}
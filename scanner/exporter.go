@@ -1,7 +1,10 @@
 package scanner
 
 import (
-	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -14,10 +17,36 @@ import (
 
 // ExportOptions 导出选项
 type ExportOptions struct {
-	DestPath      string     `json:"destPath"`      // 目标路径
+	DestPath      string     `json:"destPath"`      // 目标路径，也可以是 s3://host/bucket/prefix
 	Files         []FileInfo `json:"files"`         // 要导出的文件列表
 	KeepStructure bool       `json:"keepStructure"` // 是否保持目录结构
+	StructureMode string     `json:"structureMode"` // 保持目录结构时路径的起点：root（扫描根路径，默认）、base（BaseDir）、full（完整路径）
+	BaseDir       string     `json:"baseDir"`       // StructureMode 为 base 时的起点目录
 	Overwrite     bool       `json:"overwrite"`     // 是否覆盖已存在的文件
+	FindingTypes  []string   `json:"findingTypes"`  // 只导出包含指定类型敏感信息的文件（为空时不过滤）
+
+	CollisionPolicy string `json:"collisionPolicy"` // 同一次导出中多个文件的导出路径相同时的处理方式，默认添加序号
+	PathTemplate    string `json:"pathTemplate"`    // 导出路径模板（如 {type}/{modYear}/{name}），设置后忽略 KeepStructure
+
+	Verify           bool `json:"verify"`           // 复制后重新读取目标文件并校验 SHA-256（导出到文件夹时）
+	PreserveMetadata bool `json:"preserveMetadata"` // 保留修改时间、访问时间、权限和扩展属性（导出到文件夹时）
+
+	ZipVolumeSize int64 `json:"zipVolumeSize"` // 打包导出时每个分卷的最大字节数，0 表示不分卷
+}
+
+// 导出路径冲突的处理方式
+const (
+	CollisionRename = "rename" // 添加序号：报告 (2).docx
+	CollisionParent = "parent" // 添加上级目录名：项目A_报告.docx
+	CollisionHash   = "hash"   // 添加源路径的短哈希：报告-1a2b3c4d.docx
+	CollisionSkip   = "skip"   // 跳过后面的同名文件
+)
+
+// RenamedFile 因导出路径冲突而改名的文件
+type RenamedFile struct {
+	Path string `json:"path"` // 源文件路径
+	From string `json:"from"` // 原来的导出路径（相对于目标目录）
+	To   string `json:"to"`   // 实际的导出路径
 }
 
 // ExportProgress 导出进度
@@ -27,115 +56,285 @@ type ExportProgress struct {
 	Failed    int     `json:"failed"`
 	Current   string  `json:"current"`
 	Percent   float64 `json:"percent"`
+
+	BytesDone  int64   `json:"bytesDone"`  // 已导出的字节数
+	BytesTotal int64   `json:"bytesTotal"` // 需要导出的总字节数
+	Speed      float64 `json:"speed"`      // 导出速度（字节/秒）
+	ETA        float64 `json:"eta"`        // 预计剩余时间（秒），无法估计时为 -1
+	Done       bool    `json:"done"`       // 导出已结束（完成或取消）
 }
 
 // ExportResult 导出结果
 type ExportResult struct {
-	Success      int      `json:"success"`
-	Failed       int      `json:"failed"`
-	FailedFiles  []string `json:"failedFiles"`
-	SkippedFiles []string `json:"skippedFiles"`
+	Success       int            `json:"success"`
+	Failed        int            `json:"failed"`
+	FailedFiles   []string       `json:"failedFiles"`
+	SkippedFiles  []string       `json:"skippedFiles"`
+	Renamed       []RenamedFile  `json:"renamed"`       // 因路径冲突而改名导出的文件
+	Canceled      bool           `json:"canceled"`      // 导出被取消（未处理的文件不计入成功或失败）
+	JournalID     string         `json:"journalId"`     // 移动文件时的操作日志 ID，用于撤销
+	Verify        *VerifySummary `json:"verify"`        // 校验和属性保留的结果，两者都未开启时为空
+	Resumed       int            `json:"resumed"`       // 继续导出时已完整导出而跳过的文件数
+	ManifestPath  string         `json:"manifestPath"`  // 导出清单的路径（S3 为对象键），写入失败时为空
+	ManifestErr   string         `json:"manifestErr"`   // 导出清单写入失败的原因
+	CheckpointErr string         `json:"checkpointErr"` // 无法创建导出检查点的原因，此时导出中断后不能继续
+	ZipFiles      []string       `json:"zipFiles"`      // 打包导出生成的压缩包，分卷时有多个
 }
 
+// ExportProgressCallback 导出进度回调函数类型
+type ExportProgressCallback func(progress ExportProgress)
+
+// 导出进度回调的最短间隔
+const exportProgressInterval = 200 * time.Millisecond
+
+// 复制文件时的缓冲区大小，每复制一块检查一次是否取消
+const exportBufferSize = 256 * 1024
+
 // Exporter 文件导出器
 type Exporter struct {
-	progress     ExportProgress
-	mu           sync.RWMutex
-	progressChan chan ExportProgress
+	progress         ExportProgress
+	mu               sync.RWMutex
+	progressCallback ExportProgressCallback
 }
 
 // NewExporter 创建新的导出器
 func NewExporter() *Exporter {
-	return &Exporter{
-		progressChan: make(chan ExportProgress, 100),
+	return &Exporter{}
+}
+
+// SetProgressCallback 设置导出进度回调
+func (e *Exporter) SetProgressCallback(callback ExportProgressCallback) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.progressCallback = callback
+}
+
+// exportTracker 统计一次导出的进度，按间隔更新导出器的进度并回调
+type exportTracker struct {
+	e          *Exporter
+	start      time.Time
+	total      int
+	bytesTotal int64
+
+	completed int64
+	failed    int64
+	bytesDone int64
+
+	mu       sync.Mutex
+	current  string
+	lastEmit time.Time
+	finished bool
+}
+
+// newTracker 开始统计导出进度
+func (e *Exporter) newTracker(items []exportItem) *exportTracker {
+	t := &exportTracker{e: e, start: time.Now(), total: len(items)}
+	for _, item := range items {
+		t.bytesTotal += item.file.Size
+	}
+	t.report(true)
+	return t
+}
+
+// addBytes 记录已导出的字节数
+func (t *exportTracker) addBytes(n int64) {
+	atomic.AddInt64(&t.bytesDone, n)
+	t.report(false)
+}
+
+// setCurrent 记录正在导出的文件
+func (t *exportTracker) setCurrent(name string) {
+	t.mu.Lock()
+	t.current = name
+	t.mu.Unlock()
+	t.report(false)
+}
+
+// fileDone 记录文件导出完成，copied 为已计入的字节数，按扫描结果中的大小修正总字节数
+func (t *exportTracker) fileDone(file FileInfo, copied int64, failed bool) {
+	atomic.AddInt64(&t.bytesDone, file.Size-copied)
+	atomic.AddInt64(&t.completed, 1)
+	if failed {
+		atomic.AddInt64(&t.failed, 1)
 	}
+	t.report(false)
+}
+
+// finish 导出结束，发送最终进度
+func (t *exportTracker) finish() {
+	t.mu.Lock()
+	t.current = ""
+	t.finished = true
+	t.mu.Unlock()
+	t.report(true)
 }
 
-// Export 导出文件
-func (e *Exporter) Export(options ExportOptions) (*ExportResult, error) {
+// report 更新进度，force 为 false 时按间隔节流
+func (t *exportTracker) report(force bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !force && time.Since(t.lastEmit) < exportProgressInterval {
+		return
+	}
+	t.lastEmit = time.Now()
+
+	completed := int(atomic.LoadInt64(&t.completed))
+	done := atomic.LoadInt64(&t.bytesDone)
+	progress := ExportProgress{
+		Total:      t.total,
+		Completed:  completed,
+		Failed:     int(atomic.LoadInt64(&t.failed)),
+		Current:    t.current,
+		Percent:    100,
+		BytesDone:  done,
+		BytesTotal: t.bytesTotal,
+		ETA:        -1,
+		Done:       t.finished,
+	}
+	// 百分比按字节计算，都是空文件时按文件数计算
+	if t.bytesTotal > 0 {
+		progress.Percent = float64(done) / float64(t.bytesTotal) * 100
+	} else if t.total > 0 {
+		progress.Percent = float64(completed) / float64(t.total) * 100
+	}
+	if elapsed := time.Since(t.start).Seconds(); elapsed > 0 {
+		progress.Speed = float64(done) / elapsed
+		if progress.Speed > 0 {
+			progress.ETA = float64(t.bytesTotal-done) / progress.Speed
+		}
+	}
+
+	t.e.mu.Lock()
+	t.e.progress = progress
+	callback := t.e.progressCallback
+	t.e.mu.Unlock()
+	if callback != nil {
+		callback(progress)
+	}
+}
+
+// Export 导出文件，ctx 取消时停止导出并删除正在写入的文件
+func (e *Exporter) Export(ctx context.Context, options ExportOptions) (*ExportResult, error) {
+	return e.export(ctx, options, nil)
+}
+
+// export 导出文件并写入检查点，done 不为空时为继续导出，跳过已完整导出的文件
+func (e *Exporter) export(ctx context.Context, options ExportOptions, done map[string]checkpointLine) (*ExportResult, error) {
+	if len(options.FindingTypes) > 0 {
+		options.Files = FilterByFindingTypes(options.Files, options.FindingTypes)
+	}
+
+	if IsS3Path(options.DestPath) {
+		return e.exportToS3(ctx, options)
+	}
+
 	// 确保目标目录存在
 	if err := os.MkdirAll(options.DestPath, 0755); err != nil {
 		return nil, fmt.Errorf("无法创建目标目录: %w", err)
 	}
+	// 使用绝对路径，Windows 上超过 260 个字符的路径才能自动转换为扩展长度路径（\\?\ 前缀）
+	destRoot, err := filepath.Abs(options.DestPath)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析目标目录: %w", err)
+	}
 
 	result := &ExportResult{
 		FailedFiles:  make([]string, 0),
 		SkippedFiles: make([]string, 0),
 	}
 
-	total := len(options.Files)
-	var completed int64
-	var failed int64
+	items, err := planExport(options, result)
+	if err != nil {
+		return nil, err
+	}
+	total := len(items)
+	tracker := e.newTracker(items)
+	manifest := newManifest(destRoot, items, result.SkippedFiles)
+
+	// 记录已完成的文件，中断后可以继续导出；无法创建检查点时照常导出，只是不能继续
+	checkpoint, err := createCheckpoint(options, total)
+	if err != nil {
+		result.CheckpointErr = err.Error()
+	}
+
+	// 校验或保留属性时在结果中汇总
+	if options.Verify || options.PreserveMetadata {
+		result.Verify = &VerifySummary{Mismatched: make([]string, 0), MetadataFailed: make([]string, 0)}
+	}
 
 	// 使用工作池并发复制文件
 	workerCount := 4
-	fileChan := make(chan FileInfo, total)
+	fileChan := make(chan exportItem, total)
 	var wg sync.WaitGroup
 
 	// 结果收集
-	resultChan := make(chan struct {
-		success bool
-		file    string
-		skipped bool
-	}, total)
+	resultChan := make(chan exportOutcome, total)
 
 	// 启动工作协程
 	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for file := range fileChan {
-				destPath := e.getDestPath(options, file)
+			for item := range fileChan {
+				// 已取消时不再处理剩余的文件
+				if ctx.Err() != nil {
+					continue
+				}
+				file := item.file
+				destPath := filepath.Join(destRoot, filepath.FromSlash(item.rel))
+				tracker.setCurrent(file.Name)
+
+				// 继续导出时跳过已完整导出的文件
+				if done != nil {
+					if hash, size, ok := resumeComplete(item, destPath, done, options.Verify); ok {
+						checkpoint.record(item.rel, size, hash)
+						resultChan <- exportOutcome{item: item, file: file.Path, hash: hash, resumed: true}
+						tracker.fileDone(file, 0, false)
+						continue
+					}
+				}
 
-				// 检查目标文件是否存在
-				if !options.Overwrite {
+				// 检查目标文件是否存在（继续导出时，上次导出的文件不完整则重新导出）
+				if _, ours := done[item.rel]; !options.Overwrite && !ours {
 					if _, err := os.Stat(destPath); err == nil {
-						resultChan <- struct {
-							success bool
-							file    string
-							skipped bool
-						}{true, file.Path, true}
-						atomic.AddInt64(&completed, 1)
+						resultChan <- exportOutcome{item: item, file: file.Path, skipped: true}
+						tracker.fileDone(file, 0, false)
 						continue
 					}
 				}
 
 				// 复制文件
-				err := e.copyFile(file.Path, destPath)
-				if err != nil {
-					resultChan <- struct {
-						success bool
-						file    string
-						skipped bool
-					}{false, file.Path, false}
-					atomic.AddInt64(&failed, 1)
-				} else {
-					resultChan <- struct {
-						success bool
-						file    string
-						skipped bool
-					}{true, file.Path, false}
+				opts := copyOptions{verify: options.Verify, preserve: options.PreserveMetadata, modTime: file.ModTime}
+				res, err := copyFile(ctx, file.Path, destPath, opts, tracker.addBytes)
+				if err != nil && ctx.Err() != nil {
+					// 被取消的文件已删除，不计入失败
+					continue
+				}
+				if err == nil {
+					// 只有开启校验时才记录哈希，继续导出时据此决定是否需要重新校验
+					hash := ""
+					if options.Verify {
+						hash = res.hash
+					}
+					checkpoint.record(item.rel, res.copied, hash)
 				}
-				atomic.AddInt64(&completed, 1)
-
-				// 更新进度
-				e.mu.Lock()
-				e.progress = ExportProgress{
-					Total:     total,
-					Completed: int(atomic.LoadInt64(&completed)),
-					Failed:    int(atomic.LoadInt64(&failed)),
-					Current:   file.Name,
-					Percent:   float64(atomic.LoadInt64(&completed)) / float64(total) * 100,
+				resultChan <- exportOutcome{
+					item:     item,
+					file:     file.Path,
+					hash:     res.hash,
+					err:      err,
+					verified: res.verified,
+					metaErr:  res.metaErr,
 				}
-				e.mu.Unlock()
+				tracker.fileDone(file, res.copied, err != nil)
 			}
 		}()
 	}
 
 	// 发送文件到工作池
 	go func() {
-		for _, file := range options.Files {
-			fileChan <- file
+		for _, item := range items {
+			fileChan <- item
 		}
 		close(fileChan)
 	}()
@@ -148,69 +347,294 @@ func (e *Exporter) Export(options ExportOptions) (*ExportResult, error) {
 
 	// 收集结果
 	for r := range resultChan {
-		if r.skipped {
+		switch {
+		case r.skipped:
 			result.SkippedFiles = append(result.SkippedFiles, r.file)
 			result.Success++
-		} else if r.success {
+			manifest.set(r.item.index, ManifestSkipped, "", nil)
+		case r.resumed:
+			result.Resumed++
 			result.Success++
-		} else {
+			manifest.set(r.item.index, ManifestResumed, r.hash, nil)
+		case r.err == nil:
+			result.Success++
+			manifest.set(r.item.index, ManifestExported, r.hash, nil)
+		default:
 			result.Failed++
 			result.FailedFiles = append(result.FailedFiles, r.file)
+			manifest.set(r.item.index, ManifestFailed, "", r.err)
+		}
+		if result.Verify == nil {
+			continue
+		}
+		if r.verified {
+			result.Verify.Verified++
+		}
+		if errors.Is(r.err, errVerifyMismatch) {
+			result.Verify.Mismatched = append(result.Verify.Mismatched, r.file)
+		}
+		if r.metaErr != nil {
+			result.Verify.MetadataFailed = append(result.Verify.MetadataFailed, r.file)
 		}
 	}
 
+	result.Canceled = ctx.Err() != nil
+	checkpoint.finish(!result.Canceled && result.Failed == 0)
+
+	// 导出清单写在目标目录中，写入失败不影响已导出的文件
+	manifestPath := localManifestPath(destRoot, manifest.Time)
+	if err := manifest.writeFile(manifestPath, result.Canceled); err != nil {
+		result.ManifestErr = err.Error()
+	} else {
+		result.ManifestPath = manifestPath
+	}
+	tracker.finish()
 	return result, nil
 }
 
-// getDestPath 获取目标路径
-func (e *Exporter) getDestPath(options ExportOptions, file FileInfo) string {
-	if options.KeepStructure {
-		// 保持目录结构
-		relPath := file.Path
-		// 尝试获取相对路径
-		for _, drive := range GetDrives() {
-			if strings.HasPrefix(file.Path, drive) {
-				relPath = strings.TrimPrefix(file.Path, drive)
-				break
+// exportOutcome 工作协程导出单个文件的结果
+type exportOutcome struct {
+	item     exportItem
+	file     string
+	hash     string
+	skipped  bool
+	resumed  bool
+	err      error
+	verified bool
+	metaErr  error
+}
+
+// exportItem 要导出的文件及其导出路径
+type exportItem struct {
+	file  FileInfo
+	rel   string // 相对于导出目标的路径
+	index int    // 在导出计划中的序号（导出清单中的位置）
+}
+
+// planExport 计算每个文件的导出路径（路径模板或目录结构）并按冲突策略处理路径相同的文件，
+// 改名和跳过的文件记录到 result 中。路径比较不区分大小写（Windows 和 macOS 的文件系统默认不区分）
+func planExport(options ExportOptions, result *ExportResult) ([]exportItem, error) {
+	var tmpl *PathTemplate
+	if strings.TrimSpace(options.PathTemplate) != "" {
+		var err error
+		if tmpl, err = ParsePathTemplate(options.PathTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	used := make(map[string]bool, len(options.Files))
+	taken := func(rel string) bool {
+		return used[strings.ToLower(filepath.ToSlash(rel))]
+	}
+
+	items := make([]exportItem, 0, len(options.Files))
+	for _, file := range options.Files {
+		var rel string
+		if tmpl != nil {
+			rel = tmpl.Render(file)
+		} else {
+			rel = exportRelPath(file, options)
+		}
+		if taken(rel) {
+			if options.CollisionPolicy == CollisionSkip {
+				result.SkippedFiles = append(result.SkippedFiles, file.Path)
+				continue
 			}
+			renamed := resolveCollision(rel, file, options.CollisionPolicy, taken)
+			result.Renamed = append(result.Renamed, RenamedFile{Path: file.Path, From: rel, To: renamed})
+			rel = renamed
 		}
-		return filepath.Join(options.DestPath, relPath)
+		used[strings.ToLower(filepath.ToSlash(rel))] = true
+		items = append(items, exportItem{file: file, rel: rel, index: len(items)})
 	}
+	return items, nil
+}
 
-	// 不保持目录结构，直接放到目标目录
-	return filepath.Join(options.DestPath, file.Name)
+// ExportPathPreview 导出路径预览中的一项
+type ExportPathPreview struct {
+	Path    string `json:"path"`    // 源文件路径
+	Dest    string `json:"dest"`    // 导出路径（相对于目标目录）
+	Renamed bool   `json:"renamed"` // 因路径冲突而改名
 }
 
-// copyFile 复制文件
-func (e *Exporter) copyFile(src, dst string) error {
+// ExportPreview 导出路径预览
+type ExportPreview struct {
+	Items   []ExportPathPreview `json:"items"`   // 前 limit 个文件的导出路径
+	Total   int                 `json:"total"`   // 会导出的文件数
+	Renamed int                 `json:"renamed"` // 因路径冲突而改名的文件数
+	Skipped int                 `json:"skipped"` // 因路径冲突而跳过的文件数
+}
+
+// PreviewExportPaths 不写入任何文件，计算导出时各文件的路径，limit 不大于 0 时返回全部
+func PreviewExportPaths(options ExportOptions, limit int) (*ExportPreview, error) {
+	if len(options.FindingTypes) > 0 {
+		options.Files = FilterByFindingTypes(options.Files, options.FindingTypes)
+	}
+	result := &ExportResult{}
+	items, err := planExport(options, result)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := make(map[string]bool, len(result.Renamed))
+	for _, r := range result.Renamed {
+		renamed[r.Path] = true
+	}
+	preview := &ExportPreview{
+		Items:   make([]ExportPathPreview, 0),
+		Total:   len(items),
+		Renamed: len(result.Renamed),
+		Skipped: len(result.SkippedFiles),
+	}
+	for _, item := range items {
+		if limit > 0 && len(preview.Items) >= limit {
+			break
+		}
+		preview.Items = append(preview.Items, ExportPathPreview{Path: item.file.Path, Dest: item.rel, Renamed: renamed[item.file.Path]})
+	}
+	return preview, nil
+}
+
+// resolveCollision 按冲突策略为文件生成未被占用的导出路径，无法按策略区分时添加序号
+func resolveCollision(rel string, file FileInfo, policy string, taken func(string) bool) string {
+	dir, name := "", rel
+	if i := strings.LastIndexAny(rel, `/\`); i >= 0 {
+		dir, name = rel[:i+1], rel[i+1:]
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	switch policy {
+	case CollisionParent:
+		if parent := parentDirName(file.Path); parent != "" {
+			if candidate := dir + parent + "_" + base + ext; !taken(candidate) {
+				return candidate
+			}
+		}
+	case CollisionHash:
+		sum := sha256.Sum256([]byte(file.Path))
+		if candidate := dir + base + "-" + hex.EncodeToString(sum[:4]) + ext; !taken(candidate) {
+			return candidate
+		}
+	}
+
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s%s (%d)%s", dir, base, n, ext); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// parentDirName 返回文件所在目录的名称，压缩包根目录下的文件返回压缩包的文件名
+func parentDirName(p string) string {
+	p = strings.ReplaceAll(p, ArchiveSeparator, "/")
+	parts := strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' })
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
+
+// copyFile 复制文件，按 opts 校验内容和保留属性。先写入同目录下的临时文件，全部完成后才重命名为目标文件，
+// 目标文件名存在即表示内容完整；失败、校验不一致或取消时删除临时文件
+func copyFile(ctx context.Context, src, dst string, opts copyOptions, progress func(n int64)) (copyResult, error) {
+	var res copyResult
 	// 确保目标目录存在
 	dstDir := filepath.Dir(dst)
 	if err := os.MkdirAll(dstDir, 0755); err != nil {
-		return err
+		return res, err
+	}
+
+	// 在读取之前记录源文件属性
+	var meta *sourceMetadata
+	if opts.preserve {
+		var err error
+		if meta, err = statMetadata(src, opts.modTime); err != nil {
+			return res, err
+		}
 	}
 
-	// 打开源文件
-	srcFile, err := os.Open(src)
+	// 打开源文件（可能位于压缩包内）
+	srcFile, err := openSource(src)
 	if err != nil {
-		return err
+		return res, err
 	}
 	defer srcFile.Close()
 
-	// 创建目标文件
-	dstFile, err := os.Create(dst)
+	// 创建临时文件
+	part := exportPartPath(dst)
+	dstFile, err := os.Create(part)
 	if err != nil {
-		return err
+		return res, err
+	}
+
+	// 复制内容并同步到磁盘，同时计算源内容的哈希（用于校验和导出清单）
+	h := sha256.New()
+	res.copied, err = copyWithContext(ctx, dstFile, io.TeeReader(srcFile, h), progress)
+	if err == nil {
+		err = dstFile.Sync()
+	}
+	if cerr := dstFile.Close(); err == nil {
+		err = cerr
 	}
-	defer dstFile.Close()
 
-	// 复制内容
-	_, err = io.Copy(dstFile, srcFile)
+	if err == nil {
+		res.hash = hex.EncodeToString(h.Sum(nil))
+	}
+
+	// 重新读取目标文件（而不是写入时的缓冲区）进行校验
+	if err == nil && opts.verify {
+		var dstHash string
+		dstHash, err = hashReader(func() (io.ReadCloser, error) { return os.Open(part) })
+		if err == nil && dstHash != res.hash {
+			err = fmt.Errorf("%w: %s", errVerifyMismatch, dst)
+		}
+		res.verified = err == nil
+	}
 	if err != nil {
-		return err
+		os.Remove(part)
+		return res, err
 	}
 
-	// 同步到磁盘
-	return dstFile.Sync()
+	if opts.preserve {
+		res.metaErr = preserveMetadata(src, part, meta)
+	}
+	if err := os.Rename(part, dst); err != nil {
+		os.Remove(part)
+		return res, err
+	}
+	return res, nil
+}
+
+// copyWithContext 分块复制数据，每块复制后报告进度并检查是否取消
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader, progress func(n int64)) (int64, error) {
+	buf := make([]byte, exportBufferSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, rerr := src.Read(buf)
+		if n > 0 {
+			w, werr := dst.Write(buf[:n])
+			written += int64(w)
+			if progress != nil {
+				progress(int64(w))
+			}
+			if werr != nil {
+				return written, werr
+			}
+			if w < n {
+				return written, io.ErrShortWrite
+			}
+		}
+		if rerr == io.EOF {
+			return written, nil
+		}
+		if rerr != nil {
+			return written, rerr
+		}
+	}
 }
 
 // GetProgress 获取当前进度
@@ -220,115 +644,99 @@ func (e *Exporter) GetProgress() ExportProgress {
 	return e.progress
 }
 
-// ExportAsZip 导出为压缩包
-func (e *Exporter) ExportAsZip(options ExportOptions) (*ExportResult, error) {
+// exportToS3 上传文件到 S3 存储桶
+func (e *Exporter) exportToS3(ctx context.Context, options ExportOptions) (*ExportResult, error) {
+	uploader, err := newS3Uploader(options.DestPath)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &ExportResult{
 		FailedFiles:  make([]string, 0),
 		SkippedFiles: make([]string, 0),
 	}
 
-	// 生成压缩包文件名
-	zipFileName := fmt.Sprintf("office-files-%s.zip", time.Now().Format("20060102-150405"))
-	zipPath := filepath.Join(options.DestPath, zipFileName)
-
-	// 确保目标目录存在
-	if err := os.MkdirAll(options.DestPath, 0755); err != nil {
-		return nil, fmt.Errorf("无法创建目标目录: %w", err)
-	}
-
-	// 创建压缩文件
-	zipFile, err := os.Create(zipPath)
+	items, err := planExport(options, result)
 	if err != nil {
-		return nil, fmt.Errorf("无法创建压缩文件: %w", err)
+		return nil, err
 	}
-	defer zipFile.Close()
+	tracker := e.newTracker(items)
+	manifest := newManifest(options.DestPath, items, result.SkippedFiles)
+	fileChan := make(chan exportItem)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	// 使用工作池并发上传
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range fileChan {
+				if ctx.Err() != nil {
+					continue
+				}
+				file := item.file
+				key := uploader.objectKey(item.rel)
+				tracker.setCurrent(file.Name)
+
+				// 检查目标对象是否存在
+				if !options.Overwrite && uploader.exists(ctx, key) {
+					mu.Lock()
+					result.Success++
+					result.SkippedFiles = append(result.SkippedFiles, file.Path)
+					manifest.set(item.index, ManifestSkipped, "", nil)
+					mu.Unlock()
+					tracker.fileDone(file, 0, false)
+					continue
+				}
 
-	total := len(options.Files)
+				// 分片上传时进度回调在多个协程中并发执行
+				var sent atomic.Int64
+				err := uploader.upload(ctx, file.Path, key, func(n int64) {
+					sent.Add(n)
+					tracker.addBytes(n)
+				})
+				if err != nil && ctx.Err() != nil {
+					// 取消时未完成的分片上传由 minio 中止，不计入失败
+					continue
+				}
 
-	// 初始化进度
-	e.mu.Lock()
-	e.progress = ExportProgress{
-		Total:     total,
-		Completed: 0,
-		Failed:    0,
-		Current:   "",
-		Percent:   0,
-	}
-	e.mu.Unlock()
-
-	for i, file := range options.Files {
-		// 更新进度
-		e.mu.Lock()
-		e.progress.Current = file.Name
-		e.progress.Completed = i
-		e.progress.Percent = float64(i) / float64(total) * 100
-		e.mu.Unlock()
-
-		// 确定压缩包内的路径
-		var zipEntryPath string
-		if options.KeepStructure {
-			// 保持目录结构
-			relPath := file.Path
-			for _, drive := range GetDrives() {
-				if strings.HasPrefix(file.Path, drive) {
-					relPath = strings.TrimPrefix(file.Path, drive)
-					break
+				mu.Lock()
+				if err != nil {
+					result.Failed++
+					result.FailedFiles = append(result.FailedFiles, file.Path)
+					manifest.set(item.index, ManifestFailed, "", err)
+				} else {
+					result.Success++
+					manifest.set(item.index, ManifestExported, "", nil)
 				}
-			}
-			zipEntryPath = strings.TrimPrefix(relPath, string(filepath.Separator))
-		} else {
-			zipEntryPath = file.Name
-		}
+				mu.Unlock()
 
-		// 添加文件到压缩包
-		err := e.addFileToZip(zipWriter, file.Path, zipEntryPath)
-		if err != nil {
-			result.Failed++
-			result.FailedFiles = append(result.FailedFiles, file.Path)
-		} else {
-			result.Success++
-		}
+				// 失败或大小与扫描结果不一致时修正已上传字节数
+				tracker.fileDone(file, sent.Load(), err != nil)
+			}
+		}()
 	}
 
-	// 完成进度
-	e.mu.Lock()
-	e.progress.Completed = total
-	e.progress.Percent = 100
-	e.mu.Unlock()
-
-	return result, nil
-}
-
-// addFileToZip 添加文件到压缩包
-func (e *Exporter) addFileToZip(zipWriter *zip.Writer, srcPath, zipEntryPath string) error {
-	srcFile, err := os.Open(srcPath)
-	if err != nil {
-		return err
+	for _, item := range items {
+		fileChan <- item
 	}
-	defer srcFile.Close()
+	close(fileChan)
+	wg.Wait()
 
-	// 获取文件信息
-	info, err := srcFile.Stat()
-	if err != nil {
-		return err
-	}
+	result.Canceled = ctx.Err() != nil
 
-	// 创建zip文件头
-	header, err := zip.FileInfoHeader(info)
-	if err != nil {
-		return err
+	// 导出清单上传到目标前缀下，取消后仍然上传，记录已上传的文件
+	key := uploader.objectKey(manifestName(manifest.Time))
+	data, err := manifest.marshal(result.Canceled)
+	if err == nil {
+		err = uploader.put(context.Background(), key, data)
 	}
-	header.Name = zipEntryPath
-	header.Method = zip.Deflate
-
-	writer, err := zipWriter.CreateHeader(header)
 	if err != nil {
-		return err
+		result.ManifestErr = err.Error()
+	} else {
+		result.ManifestPath = key
 	}
-
-	_, err = io.Copy(writer, srcFile)
-	return err
+	tracker.finish()
+	return result, nil
 }
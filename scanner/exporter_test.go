@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanExportCollisions(t *testing.T) {
+	hashed := func(path, ext string) string {
+		sum := sha256.Sum256([]byte(path))
+		return "报告-" + hex.EncodeToString(sum[:4]) + ext
+	}
+	files := []FileInfo{
+		{Path: "/a/项目A/报告.docx", Name: "报告.docx", FileType: "word"},
+		{Path: "/a/项目B/报告.docx", Name: "报告.docx", FileType: "word"},
+		{Path: "/a/项目C/报告.DOCX", Name: "报告.DOCX", FileType: "word"}, // 只有大小写不同
+		{Path: "/a/项目A/报告 (2).docx", Name: "报告 (2).docx", FileType: "word"},
+		{Path: "/a/归档.zip" + ArchiveSeparator + "报告.docx", Name: "报告.docx", FileType: "word"},
+		{Path: "/a/项目A/清单.pdf", Name: "清单.pdf", FileType: "pdf"},
+	}
+
+	tests := []struct {
+		policy  string
+		want    []string // 各文件的导出路径，空字符串表示跳过
+		renamed int
+	}{
+		{"", []string{"报告.docx", "报告 (2).docx", "报告 (3).DOCX", "报告 (2) (2).docx", "报告 (4).docx", "清单.pdf"}, 4},
+		{CollisionRename, []string{"报告.docx", "报告 (2).docx", "报告 (3).DOCX", "报告 (2) (2).docx", "报告 (4).docx", "清单.pdf"}, 4},
+		{CollisionParent, []string{"报告.docx", "项目B_报告.docx", "项目C_报告.DOCX", "报告 (2).docx", "归档.zip_报告.docx", "清单.pdf"}, 3},
+		{CollisionHash, []string{"报告.docx", hashed(files[1].Path, ".docx"), hashed(files[2].Path, ".DOCX"), "报告 (2).docx", hashed(files[4].Path, ".docx"), "清单.pdf"}, 3},
+		{CollisionSkip, []string{"报告.docx", "", "", "报告 (2).docx", "", "清单.pdf"}, 0},
+	}
+	for _, tt := range tests {
+		result := &ExportResult{}
+		items, err := planExport(ExportOptions{Files: files, CollisionPolicy: tt.policy}, result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest := make(map[string]string, len(items))
+		for i, item := range items {
+			if item.index != i {
+				t.Errorf("%s: %s has index %d, want %d", tt.policy, item.file.Path, item.index, i)
+			}
+			dest[item.file.Path] = filepath.ToSlash(item.rel)
+		}
+		for i, f := range files {
+			if tt.want[i] != "" && dest[f.Path] != tt.want[i] {
+				t.Errorf("%s: %s exported to %q, want %q", tt.policy, f.Path, dest[f.Path], tt.want[i])
+			}
+		}
+		if len(result.Renamed) != tt.renamed {
+			t.Errorf("%s: renamed %+v, want %d", tt.policy, result.Renamed, tt.renamed)
+		}
+		if tt.policy == CollisionSkip && !reflect.DeepEqual(result.SkippedFiles, []string{files[1].Path, files[2].Path, files[4].Path}) {
+			t.Errorf("skipped %v", result.SkippedFiles)
+		}
+
+		// 导出路径不区分大小写地互不相同
+		seen := map[string]string{}
+		for path, rel := range dest {
+			key := FoldText(rel)
+			if other, ok := seen[key]; ok {
+				t.Errorf("%s: %s and %s both exported to %s", tt.policy, path, other, rel)
+			}
+			seen[key] = path
+		}
+	}
+}
+
+func TestPlanExportParentFallback(t *testing.T) {
+	// 上级目录名相同时改用序号
+	files := []FileInfo{
+		{Path: "/x/项目A/报告.docx", Name: "报告.docx"},
+		{Path: "/y/项目A/报告.docx", Name: "报告.docx"},
+		{Path: "/z/项目A/报告.docx", Name: "报告.docx"},
+	}
+	result := &ExportResult{}
+	items, err := planExport(ExportOptions{Files: files, CollisionPolicy: CollisionParent}, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.rel)
+	}
+	if want := []string{"报告.docx", "项目A_报告.docx", "报告 (2).docx"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("planExport = %v, want %v", got, want)
+	}
+	if want := (RenamedFile{Path: files[2].Path, From: "报告.docx", To: "报告 (2).docx"}); len(result.Renamed) != 2 || result.Renamed[1] != want {
+		t.Errorf("renamed = %+v", result.Renamed)
+	}
+}
+
+func TestPlanExportTemplate(t *testing.T) {
+	files := []FileInfo{
+		{Path: "/a/1/报告.docx", Name: "报告.docx", FileType: "word"},
+		{Path: "/a/2/报告.docx", Name: "报告.docx", FileType: "word"},
+		{Path: "/a/2/报告.pdf", Name: "报告.pdf", FileType: "pdf"},
+	}
+	preview, err := PreviewExportPaths(ExportOptions{Files: files, PathTemplate: "{type}/{name}", KeepStructure: true}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Total != 3 || preview.Renamed != 1 || preview.Skipped != 0 || len(preview.Items) != 2 {
+		t.Fatalf("preview = %+v", preview)
+	}
+	if item := preview.Items[1]; filepath.ToSlash(item.Dest) != "word/报告 (2).docx" || !item.Renamed {
+		t.Errorf("second file = %+v", item)
+	}
+
+	if _, err := planExport(ExportOptions{Files: files, PathTemplate: "{unknown}/{name}"}, &ExportResult{}); err == nil {
+		t.Error("planExport accepted an unknown template variable")
+	}
+}
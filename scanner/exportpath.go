@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+// 保持目录结构时导出路径的起点
+const (
+	StructureRoot = "root" // 相对于文件所属的扫描根路径（默认）
+	StructureBase = "base" // 相对于 ExportOptions.BaseDir，不在其中的文件按完整路径导出
+	StructureFull = "full" // 完整路径，Windows 盘符和 UNC 服务器名作为一级目录
+)
+
+// 文件名的最大字节数（NTFS、ext4、APFS 都是 255）
+const maxNameBytes = 255
+
+// Windows 保留的设备名，不能用作文件名（带扩展名也不行）
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// exportRelPath 获取文件在导出目标中的相对路径（以 / 分隔），压缩包作为一级目录
+func exportRelPath(file FileInfo, options ExportOptions) string {
+	if !options.KeepStructure {
+		// 不保持目录结构，直接放到目标目录
+		return sanitizeName(file.Name)
+	}
+
+	p := archiveDirPath(file.Path)
+	rel, ok := "", false
+	switch options.StructureMode {
+	case StructureFull:
+	case StructureBase:
+		rel, ok = relativeTo(options.BaseDir, p)
+	default:
+		rel, ok = relativeTo(archiveDirPath(file.Root), p)
+	}
+	if !ok {
+		rel = fullRelPath(p)
+	}
+	return cleanRelPath(rel, file.Name)
+}
+
+// isPathSep 是否为路径分隔符（两种分隔符都接受）
+func isPathSep(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// cleanRelPath 去掉相对路径中的空段、. 和 ..，逐段处理无效的文件名，结果为空时使用 fallback
+func cleanRelPath(rel, fallback string) string {
+	var clean []string
+	for _, part := range strings.FieldsFunc(rel, isPathSep) {
+		if part == "." || part == ".." {
+			continue
+		}
+		clean = append(clean, sanitizeName(part))
+	}
+	if len(clean) == 0 {
+		return sanitizeName(fallback)
+	}
+	return strings.Join(clean, "/")
+}
+
+// relativeTo 返回 p 相对于 base 的路径，p 不在 base 之下时返回 false
+func relativeTo(base, p string) (string, bool) {
+	if base == "" || !rootContains(base, p) {
+		return "", false
+	}
+	rel := p[len(strings.TrimRight(base, `/\`)):]
+	return strings.TrimLeft(rel, `/\`), true
+}
+
+// fullRelPath 把完整路径转换为相对路径：远程地址以主机名、Windows 路径以盘符（或 UNC 的服务器名）作为一级目录
+func fullRelPath(p string) string {
+	if scheme := urlScheme(p); scheme != "" {
+		return strings.TrimPrefix(p[len(scheme)+len("://"):], "/")
+	}
+	// C:\docs\a.docx -> C/docs/a.docx
+	if len(p) >= 2 && p[1] == ':' && ('A' <= p[0] && p[0] <= 'Z' || 'a' <= p[0] && p[0] <= 'z') {
+		return strings.ToUpper(p[:1]) + "/" + strings.TrimLeft(p[2:], `/\`)
+	}
+	// \\server\share\a.docx -> server/share/a.docx，/home/a.docx -> home/a.docx
+	return strings.TrimLeft(p, `/\`)
+}
+
+// sanitizeName 处理导出时无法作为文件名的部分：Windows 上替换保留字符、去掉结尾的点和空格、
+// 避开设备名；超过 255 字节的文件名截断主文件名并保留扩展名
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '_'
+		}
+		if runtime.GOOS == "windows" && strings.ContainsRune(`<>:"|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if runtime.GOOS == "windows" {
+		name = strings.TrimRight(name, ". ")
+		base := name
+		if i := strings.Index(base, "."); i >= 0 {
+			base = base[:i]
+		}
+		if windowsReservedNames[strings.ToUpper(base)] {
+			name = "_" + name
+		}
+	}
+	if name == "" {
+		name = "_"
+	}
+
+	if len(name) > maxNameBytes {
+		ext := filepath.Ext(name)
+		if len(ext) > maxNameBytes/2 {
+			ext = ""
+		}
+		base := name[:maxNameBytes-len(ext)]
+		// 不截断多字节字符
+		for len(base) > 0 {
+			if r, size := utf8.DecodeLastRuneInString(base); r != utf8.RuneError || size > 1 {
+				break
+			}
+			base = base[:len(base)-1]
+		}
+		name = base + ext
+	}
+	return name
+}
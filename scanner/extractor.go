@@ -0,0 +1,796 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// 文本提取限制
+const (
+	defaultTextLimit = 1 << 20  // 默认最多提取 1MB 文本
+	maxEntrySize     = 32 << 20 // 压缩包内单个条目最多读取 32MB，防止解压炸弹
+	maxPDFSize       = 64 << 20 // PDF 最多读取 64MB 用于提取文本
+)
+
+// ErrUnsupportedFormat 不支持提取文本的格式
+var ErrUnsupportedFormat = errors.New("不支持提取该格式的文本")
+
+// ExtractText 提取文档中的纯文本内容，最多返回约 limit 字节（limit<=0 时使用默认值）
+func ExtractText(path string, limit int) (string, error) {
+	file, err := openDocument(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return extractDocumentText(file, path, limit)
+}
+
+// extractDocumentText 从已打开的文档中提取纯文本，path 只用于判断扩展名
+func extractDocumentText(file docReader, path string, limit int) (string, error) {
+	if limit <= 0 {
+		limit = defaultTextLimit
+	}
+
+	header := make([]byte, 8)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case bytes.HasPrefix(header, pdfMagic):
+		return extractPDFText(file, file.Size(), limit)
+	case bytes.HasPrefix(header, zipMagic):
+		zr, err := zip.NewReader(file, file.Size())
+		if err != nil {
+			return "", fmt.Errorf("无法读取压缩结构: %w", err)
+		}
+		return extractOOXMLText(zr, limit)
+	case bytes.HasPrefix(header, oleMagic):
+		return extractOLE2Text(file, file.Size(), limit)
+	case ext == ".csv":
+		data, err := io.ReadAll(io.NewSectionReader(file, 0, min(file.Size(), int64(limit))))
+		if err != nil {
+			return "", fmt.Errorf("无法读取文件内容: %w", err)
+		}
+		return strings.ToValidUTF8(string(data), ""), nil
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+// textBuilder 带长度上限的文本缓冲
+type textBuilder struct {
+	strings.Builder
+	limit int
+}
+
+// full 是否已达到上限
+func (b *textBuilder) full() bool {
+	return b.Len() >= b.limit
+}
+
+// add 追加文本（超过上限的部分被丢弃）
+func (b *textBuilder) add(s string) {
+	if b.full() {
+		return
+	}
+	if remain := b.limit - b.Len(); len(s) > remain {
+		s = strings.ToValidUTF8(s[:remain], "")
+	}
+	b.WriteString(s)
+}
+
+// newline 追加换行（避免连续空行）
+func (b *textBuilder) newline() {
+	s := b.String()
+	if len(s) > 0 && s[len(s)-1] != '\n' {
+		b.add("\n")
+	}
+}
+
+// extractOOXMLText 提取 OOXML（docx/xlsx/pptx）中的文本
+func extractOOXMLText(zr *zip.Reader, limit int) (string, error) {
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	b := &textBuilder{limit: limit}
+	switch {
+	case entries["word/document.xml"] != nil:
+		if err := extractWordXML(entries["word/document.xml"], b); err != nil {
+			return "", err
+		}
+	case entries["xl/workbook.xml"] != nil:
+		if err := extractWorkbookText(zr, entries, b); err != nil {
+			return "", err
+		}
+	case entries["ppt/presentation.xml"] != nil:
+		for _, f := range numberedEntries(zr, "ppt/slides/slide") {
+			if err := extractPlainXML(f, "t", b); err != nil {
+				return "", err
+			}
+			b.newline()
+			if b.full() {
+				break
+			}
+		}
+	default:
+		return "", ErrUnsupportedFormat
+	}
+
+	return b.String(), nil
+}
+
+// numberedEntries 按编号顺序返回形如 prefixN.xml 的条目
+func numberedEntries(zr *zip.Reader, prefix string) []*zip.File {
+	var files []*zip.File
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, prefix) && strings.HasSuffix(f.Name, ".xml") {
+			files = append(files, f)
+		}
+	}
+	number := func(name string) int {
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".xml"))
+		return n
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return number(files[i].Name) < number(files[j].Name)
+	})
+	return files
+}
+
+// openEntryDecoder 打开压缩包条目并创建 XML 解码器
+func openEntryDecoder(f *zip.File) (*xml.Decoder, io.Closer, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法读取 %s: %w", f.Name, err)
+	}
+	decoder := xml.NewDecoder(io.LimitReader(rc, maxEntrySize))
+	decoder.Strict = false
+	return decoder, rc, nil
+}
+
+// extractWordXML 提取 word/document.xml 中的段落文本
+func extractWordXML(f *zip.File, b *textBuilder) error {
+	decoder, closer, err := openEntryDecoder(f)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	inText := false
+	for !b.full() {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.add("\t")
+			case "br", "cr":
+				b.add("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.newline()
+			}
+		case xml.CharData:
+			if inText {
+				b.add(string(t))
+			}
+		}
+	}
+	return nil
+}
+
+// extractPlainXML 提取指定元素中的文本
+func extractPlainXML(f *zip.File, element string, b *textBuilder) error {
+	decoder, closer, err := openEntryDecoder(f)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	inText := false
+	for !b.full() {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == element {
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case element:
+				inText = false
+			case "p":
+				b.newline()
+			}
+		case xml.CharData:
+			if inText {
+				b.add(string(t))
+			}
+		}
+	}
+	return nil
+}
+
+// extractWorkbookText 按行提取工作簿中所有工作表的单元格文本
+func extractWorkbookText(zr *zip.Reader, entries map[string]*zip.File, b *textBuilder) error {
+	var sharedStrings []string
+	if f := entries["xl/sharedStrings.xml"]; f != nil {
+		var err error
+		if sharedStrings, err = readSharedStrings(f); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range numberedEntries(zr, "xl/worksheets/sheet") {
+		if err := extractSheetRows(f, sharedStrings, b); err != nil {
+			return err
+		}
+		if b.full() {
+			break
+		}
+	}
+	return nil
+}
+
+// readSharedStrings 读取共享字符串表
+func readSharedStrings(f *zip.File) ([]string, error) {
+	decoder, closer, err := openEntryDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	var result []string
+	var current strings.Builder
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				result = append(result, current.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+	return result, nil
+}
+
+// extractSheetRows 提取工作表中的单元格，单元格以制表符分隔，每行一行
+func extractSheetRows(f *zip.File, sharedStrings []string, b *textBuilder) error {
+	decoder, closer, err := openEntryDecoder(f)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	var cellType string
+	var value strings.Builder
+	inValue := false
+	cellsInRow := 0
+	for !b.full() {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				cellsInRow = 0
+			case "c":
+				cellType = ""
+				value.Reset()
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "t" {
+						cellType = attr.Value
+					}
+				}
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				text := value.String()
+				if cellType == "s" {
+					if idx, err := strconv.Atoi(text); err == nil && idx >= 0 && idx < len(sharedStrings) {
+						text = sharedStrings[idx]
+					}
+				}
+				if text != "" {
+					if cellsInRow > 0 {
+						b.add("\t")
+					}
+					b.add(text)
+					cellsInRow++
+				}
+			case "row":
+				if cellsInRow > 0 {
+					b.newline()
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+	return nil
+}
+
+// OLE2 文件中保存正文的数据流（Word、Excel、PowerPoint）
+var ole2ContentStreams = []string{"WordDocument", "Workbook", "Book", "PowerPoint Document"}
+
+// extractOLE2Text 从 Office 97-2003 文件中启发式地提取文本
+// 旧版 Office 的正文以 UTF-16LE 或单字节编码保存，这里直接扫描正文数据流中的连续可读片段
+func extractOLE2Text(file io.ReaderAt, size int64, limit int) (string, error) {
+	var data []byte
+	if ole, err := openOLE(file, size); err == nil {
+		for _, name := range ole2ContentStreams {
+			if stream, err := ole.readStream(name); err == nil {
+				data = append(data, stream...)
+			}
+		}
+	}
+	if data == nil {
+		// 目录结构损坏时退回扫描整个文件
+		var err error
+		data, err = io.ReadAll(io.LimitReader(io.NewSectionReader(file, 0, size), maxEntrySize))
+		if err != nil {
+			return "", fmt.Errorf("无法读取文件内容: %w", err)
+		}
+	}
+
+	b := &textBuilder{limit: limit}
+	for _, run := range utf16Runs(data, 4) {
+		b.add(run)
+		b.newline()
+	}
+	for _, run := range asciiRuns(data, 8) {
+		b.add(run)
+		b.newline()
+	}
+	return b.String(), nil
+}
+
+// utf16Runs 查找 UTF-16LE 编码的连续可读片段
+func utf16Runs(data []byte, minLen int) []string {
+	var runs []string
+	for offset := 0; offset < 2; offset++ {
+		var current []uint16
+		flush := func() {
+			if len(current) >= minLen {
+				text := string(utf16.Decode(current))
+				if hasNonASCIILetter(text) && !isRepetitive(text) {
+					runs = append(runs, text)
+				}
+			}
+			current = current[:0]
+		}
+		for i := offset; i+1 < len(data); i += 2 {
+			c := uint16(data[i]) | uint16(data[i+1])<<8
+			r := rune(c)
+			if (r >= 0x20 && r < 0xD800 && unicode.IsPrint(r)) || r == '\t' || r == '\r' {
+				current = append(current, c)
+				continue
+			}
+			flush()
+		}
+		flush()
+	}
+	return runs
+}
+
+// hasNonASCIILetter 判断文本中是否包含非 ASCII 字母（纯 ASCII 片段交给 asciiRuns 处理）
+func hasNonASCIILetter(s string) bool {
+	for _, r := range s {
+		if r >= 0x80 && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRepetitive 判断片段是否由同一字符重复组成（多为二进制填充数据被误识别）
+func isRepetitive(s string) bool {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	for _, n := range counts {
+		if n*2 > total {
+			return true
+		}
+	}
+	return false
+}
+
+// asciiRuns 查找单字节编码的连续可读片段
+func asciiRuns(data []byte, minLen int) []string {
+	var runs []string
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		printable := i < len(data) && ((data[i] >= 0x20 && data[i] <= 0x7E) || data[i] == '\t')
+		if printable {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minLen {
+			run := string(data[start:i])
+			if strings.IndexFunc(run, unicode.IsLetter) >= 0 {
+				runs = append(runs, run)
+			}
+		}
+		start = -1
+	}
+	return runs
+}
+
+// PDF 解析相关的正则表达式
+var (
+	pdfStreamPattern = regexp.MustCompile(`>>\s*stream\r?\n`)
+	pdfBFCharPattern = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>`)
+	pdfBFRangeLine   = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*(<[0-9A-Fa-f]+>|\[[^\]]*\])`)
+	pdfHexPattern    = regexp.MustCompile(`<([0-9A-Fa-f]+)>`)
+)
+
+// pdfCMap 合并后的 ToUnicode 映射（不区分字体，适用于大多数单一字体编码的文档）
+type pdfCMap struct {
+	codeBytes int
+	mapping   map[uint32]string
+}
+
+// extractPDFText 提取 PDF 文本
+// 解压所有 FlateDecode 内容流，并根据 Tj/TJ 文本操作符还原文字，CID 字体通过 ToUnicode 映射解码
+func extractPDFText(file io.ReaderAt, size int64, limit int) (string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, min(size, maxPDFSize)))
+	if err != nil {
+		return "", fmt.Errorf("无法读取文件内容: %w", err)
+	}
+
+	var contents [][]byte
+	cmap := &pdfCMap{mapping: make(map[uint32]string)}
+	for _, loc := range pdfStreamPattern.FindAllIndex(data, -1) {
+		// 流字典从所属对象的 obj 关键字开始
+		dictStart := max(0, loc[0]-4096)
+		if i := bytes.LastIndex(data[dictStart:loc[0]], []byte("obj")); i >= 0 {
+			dictStart += i
+		}
+		dict := data[dictStart:loc[0]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		raw := data[start : start+end]
+
+		var stream []byte
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			zr, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			stream, _ = io.ReadAll(io.LimitReader(zr, maxEntrySize))
+			zr.Close()
+		case bytes.Contains(dict, []byte("/Filter")):
+			// 其他编码（图片等）无法解析文本
+			continue
+		default:
+			stream = raw
+		}
+
+		if bytes.Contains(stream, []byte("begincmap")) {
+			cmap.parse(stream)
+			continue
+		}
+		if bytes.Contains(stream, []byte("Tj")) || bytes.Contains(stream, []byte("TJ")) {
+			contents = append(contents, stream)
+		}
+	}
+
+	b := &textBuilder{limit: limit}
+	for _, content := range contents {
+		parsePDFContent(content, cmap, b)
+		if b.full() {
+			break
+		}
+	}
+	return b.String(), nil
+}
+
+// parse 解析 ToUnicode CMap 中的 bfchar 和 bfrange
+func (c *pdfCMap) parse(stream []byte) {
+	for _, section := range splitSections(stream, "beginbfchar", "endbfchar") {
+		for _, m := range pdfBFCharPattern.FindAllSubmatch(section, -1) {
+			c.add(m[1], decodeUTF16Hex(m[2]))
+		}
+	}
+	for _, section := range splitSections(stream, "beginbfrange", "endbfrange") {
+		for _, m := range pdfBFRangeLine.FindAllSubmatch(section, -1) {
+			lo, hi := parseHexCode(m[1]), parseHexCode(m[2])
+			if hi < lo || hi-lo > 0xFFFF {
+				continue
+			}
+			c.setWidth(len(m[1]) / 2)
+			if m[3][0] == '[' {
+				targets := pdfHexPattern.FindAllSubmatch(m[3], -1)
+				for i, t := range targets {
+					c.mapping[lo+uint32(i)] = decodeUTF16Hex(t[1])
+				}
+				continue
+			}
+			base := []rune(decodeUTF16Hex(m[3][1 : len(m[3])-1]))
+			if len(base) == 0 {
+				continue
+			}
+			for code := lo; code <= hi; code++ {
+				runes := append([]rune{}, base...)
+				runes[len(runes)-1] += rune(code - lo)
+				c.mapping[code] = string(runes)
+			}
+		}
+	}
+}
+
+// add 添加单个映射
+func (c *pdfCMap) add(code []byte, text string) {
+	c.setWidth(len(code) / 2)
+	c.mapping[parseHexCode(code)] = text
+}
+
+// setWidth 记录编码宽度（字节数）
+func (c *pdfCMap) setWidth(width int) {
+	if width > c.codeBytes {
+		c.codeBytes = width
+	}
+}
+
+// decode 使用映射解码字符串
+func (c *pdfCMap) decode(raw []byte) string {
+	width := c.codeBytes
+	if width == 0 || len(c.mapping) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for i := 0; i+width <= len(raw); i += width {
+		var code uint32
+		for _, b := range raw[i : i+width] {
+			code = code<<8 | uint32(b)
+		}
+		if text, ok := c.mapping[code]; ok {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// splitSections 返回 begin/end 关键字之间的所有片段
+func splitSections(data []byte, begin, end string) [][]byte {
+	var sections [][]byte
+	for {
+		i := bytes.Index(data, []byte(begin))
+		if i < 0 {
+			return sections
+		}
+		data = data[i+len(begin):]
+		j := bytes.Index(data, []byte(end))
+		if j < 0 {
+			return sections
+		}
+		sections = append(sections, data[:j])
+		data = data[j+len(end):]
+	}
+}
+
+// parseHexCode 解析十六进制编码
+func parseHexCode(hex []byte) uint32 {
+	v, _ := strconv.ParseUint(string(hex), 16, 32)
+	return uint32(v)
+}
+
+// decodeUTF16Hex 将十六进制表示的 UTF-16BE 字符串解码为文本
+func decodeUTF16Hex(hex []byte) string {
+	raw := decodeHexString(hex)
+	units := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeHexString 解码 PDF 十六进制字符串（忽略空白，奇数长度补0）
+func decodeHexString(hex []byte) []byte {
+	var digits []byte
+	for _, c := range hex {
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// parsePDFContent 解析内容流中的文本操作符
+func parsePDFContent(content []byte, cmap *pdfCMap, b *textBuilder) {
+	// 收集当前文本对象中的字符串，遇到 Tj/TJ/'/" 时输出
+	var pending []string
+	emit := func(raw []byte, hex bool) {
+		if hex || cmap.codeBytes >= 2 {
+			if text := cmap.decode(raw); text != "" {
+				pending = append(pending, text)
+				return
+			}
+		}
+		if !hex {
+			pending = append(pending, latin1String(raw))
+		}
+	}
+
+	for i := 0; i < len(content) && !b.full(); i++ {
+		switch c := content[i]; c {
+		case '(':
+			raw, next := readPDFLiteral(content, i)
+			emit(raw, false)
+			i = next
+		case '<':
+			if i+1 < len(content) && content[i+1] == '<' {
+				i++
+				continue
+			}
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return
+			}
+			emit(decodeHexString(content[i+1:i+end]), true)
+			i += end
+		case 'T':
+			if i+1 < len(content) && (content[i+1] == 'j' || content[i+1] == 'J') {
+				for _, s := range pending {
+					b.add(s)
+				}
+				pending = pending[:0]
+				i++
+			} else if i+1 < len(content) && (content[i+1] == '*' || content[i+1] == 'd' || content[i+1] == 'D') {
+				b.newline()
+				i++
+			} else {
+				pending = pending[:0]
+			}
+		case '\'', '"':
+			b.newline()
+			for _, s := range pending {
+				b.add(s)
+			}
+			pending = pending[:0]
+		case 'E':
+			if i+1 < len(content) && content[i+1] == 'T' {
+				b.newline()
+				i++
+			}
+		}
+	}
+}
+
+// readPDFLiteral 读取 PDF 字面字符串（处理嵌套括号与转义），返回内容和结束位置
+func readPDFLiteral(content []byte, start int) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out, i
+			}
+			out = append(out, c)
+		case '\\':
+			if i+1 >= len(content) {
+				return out, i
+			}
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// 续行
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					j := 0
+					for ; j < 3 && i+j < len(content) && content[i+j] >= '0' && content[i+j] <= '7'; j++ {
+						v = v*8 + int(content[i+j]-'0')
+					}
+					out = append(out, byte(v))
+					i += j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(content)
+}
+
+// latin1String 按 Latin-1 解码字节（PDF 标准编码中的可打印部分与之兼容）
+func latin1String(raw []byte) string {
+	runes := make([]rune, 0, len(raw))
+	for _, b := range raw {
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
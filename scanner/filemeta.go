@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// copyOptions 复制文件时的可选操作
+type copyOptions struct {
+	verify   bool      // 复制后重新读取目标文件，与复制时计算的源文件 SHA-256 比较
+	preserve bool      // 保留修改时间、访问时间、权限和扩展属性
+	modTime  time.Time // 源文件不是本地文件（压缩包内、远程）时使用的修改时间
+}
+
+// copyResult 复制单个文件的结果
+type copyResult struct {
+	copied   int64
+	hash     string // 源内容的 SHA-256
+	verified bool
+	metaErr  error // 无法保留的属性，内容已复制完成，不算失败
+}
+
+// errVerifyMismatch 复制后的内容与源文件不一致
+var errVerifyMismatch = errors.New("复制后的文件校验失败")
+
+// VerifySummary 导出时的校验和属性保留结果
+type VerifySummary struct {
+	Verified       int      `json:"verified"`       // 校验通过的文件数
+	Mismatched     []string `json:"mismatched"`     // 校验不一致的文件（目标文件已删除，计入失败）
+	MetadataFailed []string `json:"metadataFailed"` // 无法保留修改时间、权限或扩展属性的文件（内容已导出）
+}
+
+// sourceMetadata 复制前记录的源文件属性（读取源文件会更新访问时间）
+type sourceMetadata struct {
+	local bool
+	mode  os.FileMode
+	atime time.Time
+	mtime time.Time
+}
+
+// statMetadata 记录源文件的属性，源文件不是本地文件时只使用 modTime
+func statMetadata(src string, modTime time.Time) (*sourceMetadata, error) {
+	if !isLocalPath(src) {
+		return &sourceMetadata{atime: modTime, mtime: modTime}, nil
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	return &sourceMetadata{local: true, mode: info.Mode().Perm(), atime: fileAtime(src, info), mtime: info.ModTime()}, nil
+}
+
+// preserveMetadata 把源文件的权限、访问和修改时间、扩展属性设置到目标文件，
+// 源文件不是本地文件时只设置修改时间。文件系统不支持的属性不算错误
+func preserveMetadata(src, dst string, meta *sourceMetadata) error {
+	var errs []error
+	if meta.local {
+		if err := copyXattrs(src, dst); err != nil {
+			errs = append(errs, fmt.Errorf("扩展属性: %w", err))
+		}
+		if err := os.Chmod(dst, meta.mode); err != nil {
+			errs = append(errs, fmt.Errorf("权限: %w", err))
+		}
+	}
+	// 最后设置时间，前面的操作可能会更新目标文件的时间
+	if !meta.mtime.IsZero() {
+		if err := os.Chtimes(dst, meta.atime, meta.mtime); err != nil {
+			errs = append(errs, fmt.Errorf("时间: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !windows
+
+package scanner
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileAtime 无法获取访问时间时使用修改时间
+func fileAtime(p string, info os.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+// copyXattrs 不支持扩展属性
+func copyXattrs(src, dst string) error {
+	return nil
+}
+
+// isCrossDevice 重命名是否因为源和目标不在同一个文件系统而失败
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
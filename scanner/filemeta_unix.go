@@ -0,0 +1,72 @@
+//go:build linux || darwin
+
+package scanner
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileAtime 返回文件的访问时间
+func fileAtime(p string, info os.FileInfo) time.Time {
+	var st unix.Stat_t
+	if err := unix.Stat(p, &st); err != nil {
+		return info.ModTime()
+	}
+	return time.Unix(st.Atim.Unix())
+}
+
+// copyXattrs 复制扩展属性。目标文件系统不支持扩展属性、或没有权限写入的系统属性（security.*、system.*）时跳过
+func copyXattrs(src, dst string) error {
+	size, err := unix.Listxattr(src, nil)
+	if err != nil || size == 0 {
+		if isXattrUnsupported(err) {
+			return nil
+		}
+		return err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(src, buf)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00") {
+		if name == "" {
+			continue
+		}
+		n, err := unix.Getxattr(src, name, nil)
+		if err != nil {
+			continue
+		}
+		value := make([]byte, n)
+		if n, err = unix.Getxattr(src, name, value); err != nil {
+			continue
+		}
+		if err := unix.Setxattr(dst, name, value[:n], 0); err != nil {
+			if isXattrUnsupported(err) {
+				return nil
+			}
+			if errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES) {
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isXattrUnsupported 文件系统不支持扩展属性
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP)
+}
+
+// isCrossDevice 重命名是否因为源和目标不在同一个文件系统而失败
+func isCrossDevice(err error) bool {
+	return errors.Is(err, unix.EXDEV)
+}
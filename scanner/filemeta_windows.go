@@ -0,0 +1,28 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileAtime 返回文件的访问时间
+func fileAtime(p string, info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.LastAccessTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
+
+// copyXattrs Windows 上没有扩展属性（备用数据流不复制）
+func copyXattrs(src, dst string) error {
+	return nil
+}
+
+// isCrossDevice 重命名是否因为源和目标不在同一个卷而失败
+func isCrossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
@@ -0,0 +1,263 @@
+package scanner
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 内置存储后端
+const (
+	BackendOS  = "os"  // 本地文件系统（默认）
+	BackendZip = "zip" // 把 zip 文件作为根目录
+)
+
+// Mount 挂载的文件系统
+type Mount struct {
+	FS     fs.FS  // 文件系统
+	Root   string // 开始扫描的目录（fs 路径，如 "."）
+	Prefix string // 结果路径前缀，FileInfo.Path 为 Prefix 加 fs 路径；为空表示本地目录
+	Dir    string // 本地目录（Prefix 为空时使用）
+	Closer io.Closer
+
+	refs int // 引用数（扫描中和持有扫描结果的一方各一个），由 mountsMu 保护
+}
+
+// BackendFunc 根据扫描根路径创建挂载
+type BackendFunc func(root string) (*Mount, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFunc{
+		BackendOS:  mountOS,
+		BackendZip: mountZip,
+	}
+
+	// 已挂载的非本地文件系统，用于扫描结束后按路径重新打开文件（提取、预览、导出）。
+	// 同一前缀可能被多次扫描挂载，按挂载顺序保存，查找时使用最新的挂载
+	mountsMu  sync.RWMutex
+	mounts    = map[string][]*Mount{}
+	memMounts int
+)
+
+// RegisterBackend 注册存储后端，name 同时作为根路径的 URL scheme（如 sftp://）
+func RegisterBackend(name string, fn BackendFunc) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = fn
+}
+
+// mountOS 挂载本地目录
+func mountOS(root string) (*Mount, error) {
+	return &Mount{FS: os.DirFS(root), Root: ".", Dir: root}, nil
+}
+
+// mountZip 挂载 zip 文件，结果路径与压缩包扫描相同（a.zip!/dir/b.docx）
+func mountZip(root string) (*Mount, error) {
+	r, err := openDocument(root)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("无法读取压缩包: %w", err)
+	}
+	return &Mount{FS: zr, Root: ".", Prefix: root + ArchiveSeparator, Closer: r}, nil
+}
+
+// urlScheme 返回根路径的 URL scheme，不是 URL 时返回空（Windows 盘符不算）
+func urlScheme(root string) string {
+	i := strings.Index(root, "://")
+	if i <= 1 {
+		return ""
+	}
+	return strings.ToLower(root[:i])
+}
+
+// mountRoot 根据扫描选项挂载 root 所在的文件系统
+func mountRoot(options ScanOptions, root string) (*Mount, error) {
+	if options.FS != nil {
+		mountsMu.Lock()
+		memMounts++
+		m := &Mount{FS: options.FS, Root: ".", Prefix: "mem://" + strconv.Itoa(memMounts) + "/"}
+		mountsMu.Unlock()
+		if root = strings.Trim(filepath.ToSlash(root), "/"); root != "" {
+			m.Root = root
+		}
+		return m, nil
+	}
+
+	name := options.Backend
+	if name == "" {
+		name = urlScheme(root)
+	}
+	if name == "" {
+		name = BackendOS
+	}
+
+	backendsMu.RLock()
+	fn, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的存储后端: %s", name)
+	}
+	return fn(root)
+}
+
+// path 把 fs 路径转换为结果路径
+func (m *Mount) path(name string) string {
+	if m.Prefix == "" {
+		return filepath.Join(m.Dir, filepath.FromSlash(name))
+	}
+	if name == "." {
+		return strings.TrimSuffix(m.Prefix, "/")
+	}
+	return m.Prefix + name
+}
+
+// register 登记挂载并增加一个引用，之后可以按结果路径打开其中的文件，直到最后一个引用被释放
+func (m *Mount) register() {
+	mountsMu.Lock()
+	defer mountsMu.Unlock()
+	m.refs++
+	if m.refs == 1 && m.shared() {
+		mounts[m.Prefix] = append(mounts[m.Prefix], m)
+	}
+}
+
+// shared 扫描结束后是否还需要通过挂载读取文件（本地文件和压缩包内的文件可以直接按路径打开）
+func (m *Mount) shared() bool {
+	return m.Prefix != "" && !strings.HasSuffix(m.Prefix, ArchiveSeparator)
+}
+
+// release 释放一个引用，没有引用时注销并关闭挂载（不影响同一前缀的其他挂载）
+func (m *Mount) release() {
+	mountsMu.Lock()
+	m.refs--
+	if m.refs > 0 {
+		mountsMu.Unlock()
+		return
+	}
+	list := mounts[m.Prefix]
+	for i, other := range list {
+		if other == m {
+			list = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(mounts, m.Prefix)
+	} else {
+		mounts[m.Prefix] = list
+	}
+	mountsMu.Unlock()
+
+	if m.Closer != nil {
+		m.Closer.Close()
+	}
+}
+
+// findMount 查找路径所在的挂载（最长前缀匹配），返回挂载和 fs 路径
+func findMount(p string) (*Mount, string) {
+	mountsMu.RLock()
+	defer mountsMu.RUnlock()
+
+	prefixes := make([]string, 0, len(mounts))
+	for prefix := range mounts {
+		if strings.HasPrefix(p, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return nil, ""
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	list := mounts[prefixes[0]]
+	return list[len(list)-1], strings.TrimPrefix(p, prefixes[0])
+}
+
+// openMounted 打开挂载中的文件用于随机读取，文件不支持 ReadAt 时读入内存或临时文件
+func openMounted(m *Mount, name string) (docReader, error) {
+	f, err := m.FS.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开文件: %w", err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("无法获取文件信息: %w", err)
+	}
+	if ra, ok := f.(io.ReaderAt); ok {
+		return &fsFileReader{File: f, ReaderAt: ra, size: stat.Size()}, nil
+	}
+	defer f.Close()
+	return materialize(f, stat.Size())
+}
+
+// fsFileReader 支持随机读取的 fs.File
+type fsFileReader struct {
+	fs.File
+	io.ReaderAt
+	size int64
+}
+
+func (f *fsFileReader) Size() int64 { return f.size }
+
+// statPath 获取文件信息，压缩包内的文件返回压缩包本身的信息
+func statPath(p string) (fs.FileInfo, error) {
+	p = OuterPath(p)
+	if m, name := findMount(p); m != nil {
+		return fs.Stat(m.FS, name)
+	}
+	return os.Stat(p)
+}
+
+// remoteDir 远程后端的目录，首次 ReadDir 时才列出子项
+type remoteDir struct {
+	name    string
+	info    fs.FileInfo
+	list    func() ([]fs.DirEntry, error)
+	entries []fs.DirEntry
+	listed  bool
+}
+
+func (d *remoteDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *remoteDir) Close() error               { return nil }
+
+func (d *remoteDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("是目录")}
+}
+
+// ReadDir 实现 fs.ReadDirFile
+func (d *remoteDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, err := d.list()
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.listed = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
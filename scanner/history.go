@@ -0,0 +1,444 @@
+package scanner
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 扫描历史文件版本
+const scanHistoryVersion = 1
+
+// 最多保留的扫描历史数，超出时删除最早的记录
+const maxScanHistory = 50
+
+// 文件变化的属性
+const (
+	ChangeSize    = "size"
+	ChangeModTime = "modTime"
+	ChangeHash    = "hash"
+)
+
+// ScanRecord 已保存的一次扫描
+type ScanRecord struct {
+	ID           string      `json:"id"`
+	Time         time.Time   `json:"time"` // 扫描完成时间
+	Options      ScanOptions `json:"options"`
+	TotalCount   int         `json:"totalCount"`
+	ValidCount   int         `json:"validCount"`
+	InvalidCount int         `json:"invalidCount"`
+	ScanTime     float64     `json:"scanTime"`
+	ScheduleID   string      `json:"scheduleId,omitempty"` // 由定时扫描产生时的定时扫描 ID
+}
+
+// historyIndex 扫描历史索引，避免列出历史时读取所有结果
+type historyIndex struct {
+	Version int          `json:"version"`
+	Records []ScanRecord `json:"records"`
+}
+
+// historyFile 单次扫描的完整结果（gzip 压缩的 JSON）
+type historyFile struct {
+	Version int        `json:"version"`
+	Record  ScanRecord `json:"record"`
+	Files   []FileInfo `json:"files"`
+}
+
+// FileChange 两次扫描之间发生变化的文件
+type FileChange struct {
+	Old     FileInfo `json:"old"`
+	New     FileInfo `json:"new"`
+	Changes []string `json:"changes"` // size、modTime、hash
+}
+
+// ScanDiff 两次扫描的差异
+type ScanDiff struct {
+	From         ScanRecord   `json:"from"`
+	To           ScanRecord   `json:"to"`
+	Added        []FileInfo   `json:"added"`
+	Removed      []FileInfo   `json:"removed"`
+	Modified     []FileChange `json:"modified"`
+	NewlyInvalid []FileInfo   `json:"newlyInvalid"` // 之前有效、现在无效的文件
+}
+
+var historyMu sync.Mutex
+
+// historyDir 返回扫描历史目录（不存在时创建）
+func historyDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "history")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("无法创建扫描历史目录: %w", err)
+	}
+	return dir, nil
+}
+
+// readHistoryIndex 读取扫描历史索引，文件不存在时返回空列表
+func readHistoryIndex(dir string) ([]ScanRecord, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取扫描历史: %w", err)
+	}
+	var index historyIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("扫描历史格式错误: %w", err)
+	}
+	if index.Version > scanHistoryVersion {
+		return nil, fmt.Errorf("不支持的扫描历史版本: %d", index.Version)
+	}
+	return index.Records, nil
+}
+
+// writeHistoryIndex 写入扫描历史索引
+func writeHistoryIndex(dir string, records []ScanRecord) error {
+	data, err := json.MarshalIndent(historyIndex{Version: scanHistoryVersion, Records: records}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "index.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("无法保存扫描历史: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法保存扫描历史: %w", err)
+	}
+	return nil
+}
+
+// historyPath 返回单次扫描结果文件的路径
+func historyPath(dir, id string) string {
+	return filepath.Join(dir, id+".json.gz")
+}
+
+// SaveScanHistory 保存扫描结果和扫描选项，返回历史记录
+func SaveScanHistory(options ScanOptions, result *ScanResult) (*ScanRecord, error) {
+	return saveScanHistory(options, result, "")
+}
+
+// saveScanHistory 保存扫描结果，scheduleID 为产生结果的定时扫描
+func saveScanHistory(options ScanOptions, result *ScanResult, scheduleID string) (*ScanRecord, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	dir, err := historyDir()
+	if err != nil {
+		return nil, err
+	}
+	records, err := readHistoryIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	id := now.Format("20060102-150405")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(historyPath(dir, id)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		id = now.Format("20060102-150405") + "-" + strconv.Itoa(n)
+	}
+
+	options.FS = nil
+	record := ScanRecord{
+		ID:           id,
+		Time:         now,
+		Options:      options,
+		TotalCount:   result.TotalCount,
+		ValidCount:   result.ValidCount,
+		InvalidCount: result.InvalidCount,
+		ScanTime:     result.ScanTime,
+		ScheduleID:   scheduleID,
+	}
+	if err := writeHistoryFile(historyPath(dir, id), historyFile{Version: scanHistoryVersion, Record: record, Files: result.Files}); err != nil {
+		return nil, err
+	}
+
+	records = append(records, record)
+	for len(records) > maxScanHistory {
+		os.Remove(historyPath(dir, records[0].ID))
+		records = records[1:]
+	}
+	if err := writeHistoryIndex(dir, records); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// writeHistoryFile 写入 gzip 压缩的扫描结果
+func writeHistoryFile(path string, hf historyFile) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("无法保存扫描历史: %w", err)
+	}
+	zw := gzip.NewWriter(f)
+	err = json.NewEncoder(zw).Encode(hf)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法保存扫描历史: %w", err)
+	}
+	return nil
+}
+
+// ListScanHistory 列出已保存的扫描，最近的在前
+func ListScanHistory() ([]ScanRecord, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	dir, err := historyDir()
+	if err != nil {
+		return nil, err
+	}
+	records, err := readHistoryIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.After(records[j].Time) })
+	return records, nil
+}
+
+// checkHistoryID 校验扫描记录 ID，防止访问历史目录之外的文件
+func checkHistoryID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("无效的扫描记录: %s", id)
+	}
+	return nil
+}
+
+// LoadScanHistory 读取已保存扫描的完整结果
+func LoadScanHistory(id string) (*ScanRecord, []FileInfo, error) {
+	if err := checkHistoryID(id); err != nil {
+		return nil, nil, err
+	}
+	dir, err := historyDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(historyPath(dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("扫描记录不存在: %s", id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法读取扫描记录: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("扫描记录已损坏: %w", err)
+	}
+	defer zr.Close()
+	var hf historyFile
+	if err := json.NewDecoder(zr).Decode(&hf); err != nil {
+		return nil, nil, fmt.Errorf("扫描记录已损坏: %w", err)
+	}
+	if hf.Version > scanHistoryVersion {
+		return nil, nil, fmt.Errorf("不支持的扫描历史版本: %d", hf.Version)
+	}
+	return &hf.Record, hf.Files, nil
+}
+
+// DeleteScanHistory 删除已保存的扫描
+func DeleteScanHistory(id string) error {
+	if err := checkHistoryID(id); err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	dir, err := historyDir()
+	if err != nil {
+		return err
+	}
+	records, err := readHistoryIndex(dir)
+	if err != nil {
+		return err
+	}
+	kept := records[:0]
+	for _, r := range records {
+		if r.ID != id {
+			kept = append(kept, r)
+		}
+	}
+	if err := writeHistoryIndex(dir, kept); err != nil {
+		return err
+	}
+	if err := os.Remove(historyPath(dir, id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("无法删除扫描记录: %w", err)
+	}
+	return nil
+}
+
+// CompareScans 比较两次已保存的扫描，from 为较早的扫描
+func CompareScans(fromID, toID string) (*ScanDiff, error) {
+	from, oldFiles, err := LoadScanHistory(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, newFiles, err := LoadScanHistory(toID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffFiles(oldFiles, newFiles)
+	diff.From, diff.To = *from, *to
+	return diff, nil
+}
+
+// DiffFiles 按路径比较两组文件；大小、修改时间或哈希（两次都计算了哈希时）不同视为已修改
+func DiffFiles(oldFiles, newFiles []FileInfo) *ScanDiff {
+	diff := &ScanDiff{}
+	old := make(map[string]*FileInfo, len(oldFiles))
+	for i := range oldFiles {
+		old[oldFiles[i].Path] = &oldFiles[i]
+	}
+
+	seen := make(map[string]bool, len(newFiles))
+	for _, f := range newFiles {
+		seen[f.Path] = true
+		prev, ok := old[f.Path]
+		if !ok {
+			diff.Added = append(diff.Added, f)
+			continue
+		}
+
+		var changes []string
+		if prev.Size != f.Size {
+			changes = append(changes, ChangeSize)
+		}
+		if !prev.ModTime.Equal(f.ModTime) {
+			changes = append(changes, ChangeModTime)
+		}
+		if prev.Hash != "" && f.Hash != "" && prev.Hash != f.Hash {
+			changes = append(changes, ChangeHash)
+		}
+		if len(changes) > 0 {
+			diff.Modified = append(diff.Modified, FileChange{Old: *prev, New: f, Changes: changes})
+		}
+		if prev.IsValid && !f.IsValid {
+			diff.NewlyInvalid = append(diff.NewlyInvalid, f)
+		}
+	}
+	for _, f := range oldFiles {
+		if !seen[f.Path] {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+	return diff
+}
+
+// ExportScanDiff 保存差异报告，扩展名为 .json 时保存为 JSON，否则保存为 CSV（可用 Excel 打开）
+func ExportScanDiff(diff *ScanDiff, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("无法创建报告文件: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(diff)
+	} else {
+		err = diff.WriteCSV(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("无法保存报告: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV 以 CSV 格式写出差异，带 UTF-8 BOM 以便 Excel 正确识别中文
+func (d *ScanDiff) WriteCSV(w io.Writer) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"变化", "路径", "原大小", "新大小", "原修改时间", "新修改时间", "说明"})
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05")
+	}
+	row := func(kind string, old, cur *FileInfo, note string) {
+		var record [7]string
+		record[0] = kind
+		if old != nil {
+			record[1], record[2], record[4] = old.Path, strconv.FormatInt(old.Size, 10), formatTime(old.ModTime)
+		}
+		if cur != nil {
+			record[1], record[3], record[5] = cur.Path, strconv.FormatInt(cur.Size, 10), formatTime(cur.ModTime)
+		}
+		record[6] = note
+		cw.Write(record[:])
+	}
+
+	changeNames := map[string]string{ChangeSize: "大小", ChangeModTime: "修改时间", ChangeHash: "内容"}
+	for i := range d.Added {
+		row("新增", nil, &d.Added[i], "")
+	}
+	for i := range d.Removed {
+		row("删除", &d.Removed[i], nil, "")
+	}
+	for i := range d.Modified {
+		c := &d.Modified[i]
+		names := make([]string, len(c.Changes))
+		for j, change := range c.Changes {
+			names[j] = changeNames[change]
+		}
+		row("修改", &c.Old, &c.New, strings.Join(names, "、")+"变化")
+	}
+	for i := range d.NewlyInvalid {
+		row("变为无效", nil, &d.NewlyInvalid[i], d.NewlyInvalid[i].InvalidReason)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// hashReader 计算 open 打开的内容的 SHA-256
+func hashReader(open func() (io.ReadCloser, error)) (string, error) {
+	rc, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
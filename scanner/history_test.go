@@ -0,0 +1,175 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffFiles(t *testing.T) {
+	mod := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	file := func(path string, size int64, hash string, valid bool) FileInfo {
+		return FileInfo{Path: path, Name: path, Size: size, ModTime: mod, Hash: hash, IsValid: valid}
+	}
+
+	tests := []struct {
+		name    string
+		old     FileInfo
+		cur     FileInfo
+		changes []string // nil 表示没有修改
+		invalid bool     // 是否出现在 NewlyInvalid 中
+	}{
+		{"unchanged", file("a", 10, "h1", true), file("a", 10, "h1", true), nil, false},
+		{"size", file("a", 10, "", true), file("a", 12, "", true), []string{ChangeSize}, false},
+		{"modtime", file("a", 10, "", true), FileInfo{Path: "a", Name: "a", Size: 10, ModTime: mod.Add(time.Second), IsValid: true}, []string{ChangeModTime}, false},
+		// 同一时刻在不同时区表示时不算修改
+		{"same instant", file("a", 10, "", true), FileInfo{Path: "a", Name: "a", Size: 10, ModTime: mod.In(time.FixedZone("CST", 8*3600)), IsValid: true}, nil, false},
+		{"hash", file("a", 10, "h1", true), file("a", 10, "h2", true), []string{ChangeHash}, false},
+		{"hash only once", file("a", 10, "", true), file("a", 10, "h2", true), nil, false},
+		{"size and hash", file("a", 10, "h1", true), file("a", 11, "h2", true), []string{ChangeSize, ChangeHash}, false},
+		{"became invalid", file("a", 10, "h1", true), file("a", 10, "h1", false), nil, true},
+		{"modified and invalid", file("a", 10, "h1", true), file("a", 3, "h2", false), []string{ChangeSize, ChangeHash}, true},
+		{"still invalid", file("a", 10, "", false), file("a", 10, "", false), nil, false},
+		{"became valid", file("a", 10, "", false), file("a", 10, "", true), nil, false},
+	}
+	for _, tt := range tests {
+		diff := DiffFiles([]FileInfo{tt.old}, []FileInfo{tt.cur})
+		if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+			t.Errorf("%s: added %v, removed %v", tt.name, diff.Added, diff.Removed)
+		}
+		var changes []string
+		if len(diff.Modified) == 1 {
+			changes = diff.Modified[0].Changes
+			if diff.Modified[0].Old.Size != tt.old.Size || diff.Modified[0].New.Size != tt.cur.Size {
+				t.Errorf("%s: change = %+v", tt.name, diff.Modified[0])
+			}
+		} else if len(diff.Modified) > 1 {
+			t.Errorf("%s: modified %+v", tt.name, diff.Modified)
+		}
+		if !reflect.DeepEqual(changes, tt.changes) {
+			t.Errorf("%s: changes = %v, want %v", tt.name, changes, tt.changes)
+		}
+		if (len(diff.NewlyInvalid) == 1) != tt.invalid {
+			t.Errorf("%s: newly invalid = %v, want %v", tt.name, diff.NewlyInvalid, tt.invalid)
+		}
+	}
+}
+
+func TestDiffFilesAddedRemoved(t *testing.T) {
+	mod := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	oldFiles := []FileInfo{
+		{Path: "/d/a.pdf", Size: 1, ModTime: mod, IsValid: true},
+		{Path: "/d/b.pdf", Size: 2, ModTime: mod, IsValid: true},
+		{Path: "/d/c.pdf", Size: 3, ModTime: mod, IsValid: true},
+	}
+	newFiles := []FileInfo{
+		{Path: "/d/c.pdf", Size: 30, ModTime: mod, IsValid: false, InvalidReason: "文件头损坏"},
+		{Path: "/d/a.pdf", Size: 1, ModTime: mod, IsValid: true},
+		{Path: "/d/B.pdf", Size: 2, ModTime: mod, IsValid: true}, // 路径区分大小写
+		{Path: "/d/e.pdf", Size: 5, ModTime: mod, IsValid: true},
+	}
+	diff := DiffFiles(oldFiles, newFiles)
+
+	paths := func(files []FileInfo) []string {
+		var p []string
+		for _, f := range files {
+			p = append(p, f.Path)
+		}
+		return p
+	}
+	if got := paths(diff.Added); !reflect.DeepEqual(got, []string{"/d/B.pdf", "/d/e.pdf"}) {
+		t.Errorf("added = %v", got)
+	}
+	if got := paths(diff.Removed); !reflect.DeepEqual(got, []string{"/d/b.pdf"}) {
+		t.Errorf("removed = %v", got)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].New.Path != "/d/c.pdf" {
+		t.Errorf("modified = %+v", diff.Modified)
+	}
+	if got := paths(diff.NewlyInvalid); !reflect.DeepEqual(got, []string{"/d/c.pdf"}) {
+		t.Errorf("newly invalid = %v", got)
+	}
+
+	// CSV 报告：BOM、表头和每项变化一行
+	var buf bytes.Buffer
+	if err := diff.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte("\ufeff")) {
+		t.Error("CSV report without a BOM")
+	}
+	records, err := csv.NewReader(bytes.NewReader(data[3:])).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, r := range records[1:] {
+		kinds = append(kinds, r[0])
+	}
+	if want := []string{"新增", "新增", "删除", "修改", "变为无效"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("CSV rows = %v, want %v", kinds, want)
+	}
+	if modified := records[4]; modified[2] != "3" || modified[3] != "30" || modified[6] != "大小变化" {
+		t.Errorf("modified row = %v", modified)
+	}
+	if invalid := records[5]; invalid[6] != "文件头损坏" {
+		t.Errorf("newly invalid row = %v", invalid)
+	}
+}
+
+func TestCompareScans(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.pdf", testPDF(0))
+	b := writeTestFile(t, dir, "b.pdf", testPDF(4))
+	options := ScanOptions{RootPath: dir, ValidateFiles: true, ComputeHash: true}
+
+	scan := func() *ScanRecord {
+		t.Helper()
+		result, err := NewScanner().Scan(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer result.Release()
+		record, err := SaveScanHistory(options, result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+	first := scan()
+	unchanged := scan()
+	writeTestFile(t, dir, "b.pdf", []byte("%PDF-1.4 truncated"))
+	c := writeTestFile(t, dir, "c.pdf", testPDF(0))
+	changed := scan()
+
+	// 保存到历史记录再读出后，没有变化的文件不能因为时间精度等原因被当作已修改
+	diff, err := CompareScans(first.ID, unchanged.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Added)+len(diff.Removed)+len(diff.Modified)+len(diff.NewlyInvalid) != 0 {
+		t.Errorf("unchanged scans differ: %+v", diff)
+	}
+
+	diff, err = CompareScans(first.ID, changed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.From.ID != first.ID || diff.To.ID != changed.ID {
+		t.Errorf("diff between %s and %s", diff.From.ID, diff.To.ID)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Path != c || len(diff.Removed) != 0 {
+		t.Errorf("added %v, removed %v", diff.Added, diff.Removed)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].New.Path != b || len(diff.NewlyInvalid) != 1 {
+		t.Errorf("modified %+v, newly invalid %v", diff.Modified, diff.NewlyInvalid)
+	}
+
+	if _, err := CompareScans(first.ID, "missing"); err == nil {
+		t.Error("CompareScans with a missing record succeeded")
+	}
+}
@@ -0,0 +1,397 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 操作日志格式：每行一个 JSON，第一行是操作信息。每移动一个文件先追加一行 {"pending":true} 的意图记录，
+// 移动完成后再追加一行不带 pending 的完成记录，撤销完成后追加 {"undone":true}。逐行追加并同步到磁盘，
+// 程序在移动过程中退出时，撤销会根据两个位置上的文件判断意图记录对应的移动是否已经发生。
+
+// 操作日志版本
+const journalVersion = 1
+
+// 操作类型
+const OperationMove = "move"
+
+// Operation 可撤销的文件操作
+type Operation struct {
+	ID       string    `json:"id"`
+	Kind     string    `json:"kind"` // 操作类型，目前只有 move
+	Time     time.Time `json:"time"`
+	DestPath string    `json:"destPath"` // 目标目录
+	Count    int       `json:"count"`    // 已执行的文件数（读取时统计）
+	Undone   bool      `json:"undone"`   // 已撤销
+}
+
+// journalLine 操作日志中的一行
+type journalLine struct {
+	Version int        `json:"version,omitempty"`
+	Op      *Operation `json:"op,omitempty"`
+	From    string     `json:"from,omitempty"`    // 原路径
+	To      string     `json:"to,omitempty"`      // 移动后的路径
+	Pending bool       `json:"pending,omitempty"` // 意图记录：即将移动，还没有确认完成
+	Undone  bool       `json:"undone,omitempty"`
+}
+
+// UndoResult 撤销操作的结果
+type UndoResult struct {
+	Restored    int      `json:"restored"`
+	Failed      int      `json:"failed"`
+	FailedFiles []string `json:"failedFiles"` // 无法恢复的原路径
+}
+
+var journalMu sync.Mutex
+
+// journalDir 返回操作日志目录
+func journalDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "journals")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("无法创建操作日志目录: %w", err)
+	}
+	return dir, nil
+}
+
+// journalPath 返回操作日志文件路径
+func journalPath(dir, id string) string {
+	return filepath.Join(dir, id+".jsonl")
+}
+
+// checkJournalID 校验操作 ID，防止访问日志目录之外的文件
+func checkJournalID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("无效的操作记录: %s", id)
+	}
+	return nil
+}
+
+// journal 正在写入的操作日志
+type journal struct {
+	mu sync.Mutex
+	f  *os.File
+	op Operation
+}
+
+// createJournal 创建操作日志并写入操作信息
+func createJournal(kind, destPath string) (*journal, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	dir, err := journalDir()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	id := now.Format("20060102-150405")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(journalPath(dir, id)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		id = now.Format("20060102-150405") + "-" + strconv.Itoa(n)
+	}
+
+	f, err := os.OpenFile(journalPath(dir, id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建操作日志: %w", err)
+	}
+	j := &journal{f: f, op: Operation{ID: id, Kind: kind, Time: now, DestPath: destPath}}
+	if err := j.append(journalLine{Version: journalVersion, Op: &j.op}); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return j, nil
+}
+
+// append 追加一行并同步到磁盘
+func (j *journal) append(line journalLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("无法写入操作日志: %w", err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("无法写入操作日志: %w", err)
+	}
+	return nil
+}
+
+// begin 在移动之前记录意图，之后必须调用 commit 确认
+func (j *journal) begin(from, to string) error {
+	return j.append(journalLine{From: from, To: to, Pending: true})
+}
+
+// commit 确认 begin 记录的移动已完成
+func (j *journal) commit(from, to string) error {
+	return j.append(journalLine{From: from, To: to})
+}
+
+// close 关闭操作日志，没有记录任何文件时删除日志
+func (j *journal) close(empty bool) {
+	j.f.Close()
+	if empty {
+		os.Remove(j.f.Name())
+	}
+}
+
+// readJournal 读取操作日志，返回操作信息和记录的移动（按执行顺序），没有完成记录的移动 Pending 为 true
+func readJournal(path string) (*Operation, []journalLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, errors.New("操作记录不存在")
+		}
+		return nil, nil, fmt.Errorf("无法读取操作日志: %w", err)
+	}
+	defer f.Close()
+
+	var op *Operation
+	var moves []journalLine
+	pending := map[journalLine]int{} // 未确认的意图记录在 moves 中的位置
+	undone := false
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var line journalLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			// 程序中途退出时最后一行可能不完整，忽略
+			continue
+		}
+		switch {
+		case line.Op != nil:
+			if line.Version > journalVersion {
+				return nil, nil, fmt.Errorf("不支持的操作日志版本: %d", line.Version)
+			}
+			op = line.Op
+		case line.Undone:
+			undone = true
+		case line.From != "" && line.To != "" && line.Pending:
+			pending[journalLine{From: line.From, To: line.To}] = len(moves)
+			moves = append(moves, line)
+		case line.From != "" && line.To != "":
+			key := journalLine{From: line.From, To: line.To}
+			if i, ok := pending[key]; ok {
+				moves[i].Pending = false
+				delete(pending, key)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("无法读取操作日志: %w", err)
+	}
+	if op == nil {
+		return nil, nil, errors.New("操作日志格式错误")
+	}
+	op.Count = len(moves)
+	op.Undone = undone
+	return op, moves, nil
+}
+
+// ListOperations 列出可撤销的文件操作，最近的在前
+func ListOperations() ([]Operation, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	dir, err := journalDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取操作日志目录: %w", err)
+	}
+	ops := make([]Operation, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		op, _, err := readJournal(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		ops = append(ops, *op)
+	}
+	sort.Slice(ops, func(i, k int) bool { return ops[i].Time.After(ops[k].Time) })
+	return ops, nil
+}
+
+// DeleteOperation 删除操作记录（删除后无法撤销）
+func DeleteOperation(id string) error {
+	if err := checkJournalID(id); err != nil {
+		return err
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	dir, err := journalDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(journalPath(dir, id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("无法删除操作记录: %w", err)
+	}
+	return nil
+}
+
+// UndoOperation 按相反顺序把移动过的文件移回原位置，并删除移动时创建的空目录。
+// 原位置已有文件或移动后的文件已不存在时跳过该文件并计入失败；已恢复的文件再次撤销时直接计入成功，
+// 因此部分失败后可以处理冲突再重新撤销。没有完成记录的移动（移动时程序退出）由 undoPending 处理
+func UndoOperation(id string) (*UndoResult, error) {
+	if err := checkJournalID(id); err != nil {
+		return nil, err
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	dir, err := journalDir()
+	if err != nil {
+		return nil, err
+	}
+	path := journalPath(dir, id)
+	op, moves, err := readJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	if op.Undone {
+		return nil, errors.New("操作已撤销")
+	}
+
+	result := &UndoResult{FailedFiles: make([]string, 0)}
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := moves[i]
+		if m.Pending {
+			restored, err := undoPending(m)
+			if err != nil {
+				result.Failed++
+				result.FailedFiles = append(result.FailedFiles, m.From)
+				continue
+			}
+			if restored {
+				result.Restored++
+				removeEmptyDirs(filepath.Dir(m.To), op.DestPath)
+			}
+			continue
+		}
+		_, fromErr := os.Lstat(m.From)
+		_, toErr := os.Lstat(m.To)
+		switch {
+		case errors.Is(toErr, os.ErrNotExist) && fromErr == nil:
+			// 已经恢复过
+			result.Restored++
+			continue
+		case toErr != nil, fromErr == nil:
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, m.From)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(m.From), 0755); err != nil {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, m.From)
+			continue
+		}
+		if err := moveFile(m.To, m.From); err != nil {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, m.From)
+			continue
+		}
+		result.Restored++
+		removeEmptyDirs(filepath.Dir(m.To), op.DestPath)
+	}
+
+	if result.Failed == 0 {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("无法更新操作日志: %w", err)
+		}
+		j := &journal{f: f}
+		err = j.append(journalLine{Undone: true})
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// undoPending 恢复没有完成记录的移动。移动可能没有开始、已经完成，或者跨文件系统复制后还没删除源文件：
+// 只有源文件时无需处理；只有目标文件时移回；两处都有时，目标与源内容相同则说明是复制出的副本，删除副本。
+// restored 表示是否恢复了文件（移动没有发生时为 false）
+func undoPending(m journalLine) (restored bool, err error) {
+	// 复制中断时留下的临时文件
+	os.Remove(exportPartPath(m.To))
+
+	_, fromErr := os.Lstat(m.From)
+	_, toErr := os.Lstat(m.To)
+	switch {
+	case fromErr == nil && errors.Is(toErr, os.ErrNotExist):
+		return false, nil
+	case errors.Is(fromErr, os.ErrNotExist) && toErr == nil:
+		if err := os.MkdirAll(filepath.Dir(m.From), 0755); err != nil {
+			return false, err
+		}
+		return true, moveFile(m.To, m.From)
+	case fromErr == nil && toErr == nil:
+		same, err := sameContent(m.From, m.To)
+		if err != nil {
+			return false, err
+		}
+		if !same {
+			return false, fmt.Errorf("原位置和移动后的位置都有文件且内容不同: %s", m.From)
+		}
+		return true, os.Remove(m.To)
+	}
+	return false, fmt.Errorf("文件不存在: %s", m.From)
+}
+
+// sameContent 比较两个本地文件的大小和 SHA-256
+func sameContent(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if ai.Size() != bi.Size() {
+		return false, nil
+	}
+	ah, err := hashReader(func() (io.ReadCloser, error) { return os.Open(a) })
+	if err != nil {
+		return false, err
+	}
+	bh, err := hashReader(func() (io.ReadCloser, error) { return os.Open(b) })
+	if err != nil {
+		return false, err
+	}
+	return ah == bh, nil
+}
+
+// removeEmptyDirs 从 dir 开始向上删除空目录，直到 root（不删除 root 本身）
+func removeEmptyDirs(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && rootContains(root, dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
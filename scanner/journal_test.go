@@ -0,0 +1,172 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// moveFixture 创建待移动的源文件，返回源目录、目标目录和扫描结果
+func moveFixture(t *testing.T, names ...string) (string, string, []FileInfo) {
+	t.Helper()
+	isolateConfig(t)
+	base := t.TempDir()
+	src := filepath.Join(base, "src")
+	dst := filepath.Join(base, "dst")
+	var files []FileInfo
+	for _, name := range names {
+		p := writeTestFile(t, src, name, []byte("content of "+name))
+		files = append(files, FileInfo{Path: p, Name: filepath.Base(p), Size: int64(len("content of " + name)), Root: src})
+	}
+	return src, dst, files
+}
+
+func assertContent(t *testing.T, p, want string) {
+	t.Helper()
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Errorf("%s: %v", p, err)
+		return
+	}
+	if string(data) != want {
+		t.Errorf("%s = %q, want %q", p, data, want)
+	}
+}
+
+func assertMissing(t *testing.T, p string) {
+	t.Helper()
+	if _, err := os.Lstat(p); !os.IsNotExist(err) {
+		t.Errorf("%s exists (err = %v), want missing", p, err)
+	}
+}
+
+func TestMoveAndUndo(t *testing.T) {
+	src, dst, files := moveFixture(t, "a.pdf", "sub/b.docx", "c.xlsx")
+	writeTestFile(t, dst, "c.xlsx", []byte("already there"))
+
+	result, err := NewExporter().Move(context.Background(), ExportOptions{DestPath: dst, Files: files, KeepStructure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 2 || result.Failed != 0 || len(result.SkippedFiles) != 1 {
+		t.Fatalf("Move = success %d, failed %d, skipped %v; want 2, 0, [c.xlsx]", result.Success, result.Failed, result.SkippedFiles)
+	}
+	assertContent(t, filepath.Join(dst, "sub", "b.docx"), "content of sub/b.docx")
+	assertMissing(t, filepath.Join(src, "a.pdf"))
+	assertContent(t, filepath.Join(src, "c.xlsx"), "content of c.xlsx")
+
+	ops, err := ListOperations()
+	if err != nil || len(ops) != 1 || ops[0].ID != result.JournalID || ops[0].Count != 2 {
+		t.Fatalf("ListOperations = %+v, %v", ops, err)
+	}
+
+	undo, err := UndoOperation(result.JournalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Restored != 2 || undo.Failed != 0 {
+		t.Errorf("UndoOperation = %+v, want 2 restored", undo)
+	}
+	assertContent(t, filepath.Join(src, "a.pdf"), "content of a.pdf")
+	assertContent(t, filepath.Join(src, "sub", "b.docx"), "content of sub/b.docx")
+	assertMissing(t, filepath.Join(dst, "sub"))
+	assertContent(t, filepath.Join(dst, "c.xlsx"), "already there")
+
+	if _, err := UndoOperation(result.JournalID); err == nil {
+		t.Error("second undo succeeded")
+	}
+}
+
+func TestMoveSkipOnlyJournalIsRemoved(t *testing.T) {
+	_, dst, files := moveFixture(t, "a.pdf")
+	writeTestFile(t, dst, "a.pdf", []byte("already there"))
+
+	result, err := NewExporter().Move(context.Background(), ExportOptions{DestPath: dst, Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 0 || len(result.SkippedFiles) != 1 || result.JournalID != "" {
+		t.Errorf("Move = %+v, want only a skipped file and no journal", result)
+	}
+	if ops, _ := ListOperations(); len(ops) != 0 {
+		t.Errorf("ListOperations = %+v, want none", ops)
+	}
+}
+
+func TestUndoPendingMoves(t *testing.T) {
+	isolateConfig(t)
+	base := t.TempDir()
+	from := func(name string) string { return filepath.Join(base, "src", name) }
+	to := func(name string) string { return filepath.Join(base, "dst", name) }
+
+	// 模拟程序在移动过程中退出：只有意图记录，没有完成记录
+	writeTestFile(t, base, "src/done.pdf", []byte("done"))
+	writeTestFile(t, base, "src/renamed.pdf", []byte("renamed"))
+	writeTestFile(t, base, "src/copied.pdf", []byte("copied"))
+	writeTestFile(t, base, "src/untouched.pdf", []byte("untouched"))
+	writeTestFile(t, base, "src/conflict.pdf", []byte("original"))
+
+	j, err := createJournal(OperationMove, filepath.Join(base, "dst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := func(name string, move func()) {
+		if err := j.begin(from(name), to(name)); err != nil {
+			t.Fatal(err)
+		}
+		if move != nil {
+			move()
+		}
+	}
+	step("done.pdf", func() {
+		if _, err := moveFileContext(context.Background(), from("done.pdf"), to("done.pdf"), nil); err != nil {
+			t.Fatal(err)
+		}
+		j.commit(from("done.pdf"), to("done.pdf"))
+	})
+	step("renamed.pdf", func() { moveFile(from("renamed.pdf"), to("renamed.pdf")) })
+	step("copied.pdf", func() {
+		// 跨文件系统复制完成但源文件还没删除
+		writeTestFile(t, base, "dst/copied.pdf", []byte("copied"))
+	})
+	step("conflict.pdf", func() { writeTestFile(t, base, "dst/conflict.pdf", []byte("someone else")) })
+	step("untouched.pdf", func() {
+		writeTestFile(t, base, "dst/"+filepath.Base(exportPartPath(to("untouched.pdf"))), []byte("unt"))
+	})
+	j.f.Close()
+
+	ops, err := ListOperations()
+	if err != nil || len(ops) != 1 || ops[0].Count != 5 {
+		t.Fatalf("ListOperations = %+v, %v", ops, err)
+	}
+	undo, err := UndoOperation(j.op.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Restored != 3 || undo.Failed != 1 || len(undo.FailedFiles) != 1 || undo.FailedFiles[0] != from("conflict.pdf") {
+		t.Errorf("UndoOperation = %+v, want 3 restored and conflict.pdf failed", undo)
+	}
+	for _, name := range []string{"done.pdf", "renamed.pdf", "copied.pdf", "untouched.pdf"} {
+		assertContent(t, from(name), name[:len(name)-4])
+		assertMissing(t, to(name))
+	}
+	assertMissing(t, exportPartPath(to("untouched.pdf")))
+	assertContent(t, to("conflict.pdf"), "someone else")
+}
+
+func TestMoveRenameErrorDoesNotCopy(t *testing.T) {
+	base := t.TempDir()
+	src := writeTestFile(t, base, "a.pdf", []byte("data"))
+	// 目标是非空目录：重命名失败但不是跨文件系统，不应退回到复制
+	writeTestFile(t, base, "dst/a.pdf/keep.txt", []byte("keep"))
+
+	if _, err := moveFileContext(context.Background(), src, filepath.Join(base, "dst", "a.pdf"), nil); err == nil {
+		t.Fatal("move onto a directory succeeded")
+	}
+	assertContent(t, src, "data")
+	assertContent(t, filepath.Join(base, "dst", "a.pdf", "keep.txt"), "keep")
+	if isCrossDevice(nil) {
+		t.Error("isCrossDevice(nil) = true")
+	}
+}
@@ -0,0 +1,158 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 导出清单版本
+const exportManifestVersion = 1
+
+// 导出清单中文件的状态
+const (
+	ManifestExported = "exported" // 已导出
+	ManifestResumed  = "resumed"  // 继续导出时已完整导出而跳过
+	ManifestSkipped  = "skipped"  // 目标已存在或路径冲突而跳过
+	ManifestFailed   = "failed"   // 导出失败
+	ManifestCanceled = "canceled" // 导出被取消，未处理
+)
+
+// ManifestEntry 导出清单中的一个文件
+type ManifestEntry struct {
+	Source  string    `json:"source"`           // 源文件路径
+	Dest    string    `json:"dest,omitempty"`   // 导出路径（相对于目标目录或压缩包），路径冲突跳过时为空
+	Size    int64     `json:"size"`             // 扫描时的文件大小
+	ModTime time.Time `json:"modTime"`          // 扫描时的修改时间
+	Hash    string    `json:"hash,omitempty"`   // 导出内容的 SHA-256
+	Status  string    `json:"status"`           // 导出状态
+	Error   string    `json:"error,omitempty"`  // 失败原因
+	Volume  string    `json:"volume,omitempty"` // 打包导出分卷时所在的压缩包
+}
+
+// ManifestSummary 导出清单的统计
+type ManifestSummary struct {
+	Total    int   `json:"total"`
+	Exported int   `json:"exported"`
+	Resumed  int   `json:"resumed"`
+	Skipped  int   `json:"skipped"`
+	Failed   int   `json:"failed"`
+	Bytes    int64 `json:"bytes"` // 已导出文件的总大小
+	Canceled bool  `json:"canceled"`
+}
+
+// ExportManifest 每次导出时写在导出结果旁边的清单，记录导出了哪些文件
+type ExportManifest struct {
+	Version  int             `json:"version"`
+	Time     time.Time       `json:"time"`
+	DestPath string          `json:"destPath"`
+	Archive  string          `json:"archive,omitempty"` // 打包导出时的压缩包文件名
+	Summary  ManifestSummary `json:"summary"`
+	Files    []ManifestEntry `json:"files"`
+}
+
+// newManifest 按导出计划创建清单，计划中的文件初始状态为已取消，路径冲突跳过的文件记为跳过
+func newManifest(destPath string, items []exportItem, collisionSkipped []string) *ExportManifest {
+	m := &ExportManifest{
+		Version:  exportManifestVersion,
+		Time:     time.Now(),
+		DestPath: destPath,
+		Files:    make([]ManifestEntry, 0, len(items)+len(collisionSkipped)),
+	}
+	for _, item := range items {
+		m.Files = append(m.Files, ManifestEntry{
+			Source:  item.file.Path,
+			Dest:    item.rel,
+			Size:    item.file.Size,
+			ModTime: item.file.ModTime,
+			Hash:    item.file.Hash,
+			Status:  ManifestCanceled,
+		})
+	}
+	for _, p := range collisionSkipped {
+		m.Files = append(m.Files, ManifestEntry{Source: p, Status: ManifestSkipped})
+	}
+	return m
+}
+
+// set 记录计划中第 i 个文件的导出结果，hash 为空时保留扫描时计算的哈希
+func (m *ExportManifest) set(i int, status, hash string, err error) {
+	entry := &m.Files[i]
+	entry.Status = status
+	if hash != "" {
+		entry.Hash = hash
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+}
+
+// summarize 统计各状态的文件数
+func (m *ExportManifest) summarize(canceled bool) {
+	s := ManifestSummary{Total: len(m.Files), Canceled: canceled}
+	for _, entry := range m.Files {
+		switch entry.Status {
+		case ManifestExported:
+			s.Exported++
+			s.Bytes += entry.Size
+		case ManifestResumed:
+			s.Resumed++
+			s.Bytes += entry.Size
+		case ManifestSkipped:
+			s.Skipped++
+		case ManifestFailed:
+			s.Failed++
+		}
+	}
+	m.Summary = s
+}
+
+// manifestName 返回导出清单的文件名
+func manifestName(t time.Time) string {
+	return "docradar-manifest-" + t.Format("20060102-150405") + ".json"
+}
+
+// localManifestPath 返回目标目录中的清单路径，同一秒内多次导出到同一目录时添加序号
+func localManifestPath(dir string, t time.Time) string {
+	p := filepath.Join(dir, manifestName(t))
+	for n := 2; ; n++ {
+		if _, err := os.Stat(p); errors.Is(err, os.ErrNotExist) {
+			return p
+		}
+		p = filepath.Join(dir, "docradar-manifest-"+t.Format("20060102-150405")+"-"+strconv.Itoa(n)+".json")
+	}
+}
+
+// marshal 统计后编码为 JSON
+func (m *ExportManifest) marshal(canceled bool) ([]byte, error) {
+	m.summarize(canceled)
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// writeFile 把清单写入本地文件
+func (m *ExportManifest) writeFile(path string, canceled bool) error {
+	data, err := m.marshal(canceled)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法写入导出清单: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法写入导出清单: %w", err)
+	}
+	return nil
+}
+
+// zipManifestPath 返回压缩包旁边的清单路径：office-files-xxx.zip → office-files-xxx.manifest.json
+func zipManifestPath(zipPath string) string {
+	return strings.TrimSuffix(zipPath, ".zip") + ".manifest.json"
+}
@@ -0,0 +1,393 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// DocMetadata 文档元数据
+type DocMetadata struct {
+	Title    string `json:"title,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Keywords string `json:"keywords,omitempty"`
+	Pages    int    `json:"pages,omitempty"` // 页数（演示文稿为幻灯片数）
+}
+
+// SummaryInformation 中的属性ID
+const (
+	pidCodepage  = 0x01
+	pidTitle     = 0x02
+	pidSubject   = 0x03
+	pidAuthor    = 0x04
+	pidKeywords  = 0x05
+	pidPageCount = 0x0E
+)
+
+// 属性值类型
+const (
+	vtI2     = 0x02
+	vtI4     = 0x03
+	vtLPSTR  = 0x1E
+	vtLPWSTR = 0x1F
+)
+
+// PDF 元数据相关的正则表达式
+var (
+	pdfPagePattern  = regexp.MustCompile(`/Type\s*/Page[^s]`)
+	pdfCountPattern = regexp.MustCompile(`/Type\s*/Pages[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages`)
+	pdfInfoPattern  = regexp.MustCompile(`/(Title|Author|Subject|Keywords)\s*([(<])`)
+)
+
+// ExtractMetadata 提取文档的标题、作者、页数等元数据
+func ExtractMetadata(path string) (*DocMetadata, error) {
+	file, err := openDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return extractDocumentMetadata(file)
+}
+
+// extractDocumentMetadata 从已打开的文档中提取元数据
+func extractDocumentMetadata(file docReader) (*DocMetadata, error) {
+	header := make([]byte, 8)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, pdfMagic):
+		return extractPDFMetadata(file, file.Size())
+	case bytes.HasPrefix(header, zipMagic):
+		zr, err := zip.NewReader(file, file.Size())
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩结构: %w", err)
+		}
+		return extractOOXMLMetadata(zr)
+	case bytes.HasPrefix(header, oleMagic):
+		ole, err := openOLE(file, file.Size())
+		if err != nil {
+			return nil, err
+		}
+		return extractOLE2Metadata(ole)
+	}
+
+	return nil, ErrUnsupportedFormat
+}
+
+// extractPDFMetadata 提取 PDF 的文档信息字典和页数
+func extractPDFMetadata(file io.ReaderAt, size int64) (*DocMetadata, error) {
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, min(size, maxPDFSize)))
+	if err != nil {
+		return nil, fmt.Errorf("无法读取文件内容: %w", err)
+	}
+
+	meta := &DocMetadata{}
+	meta.Pages = countPDFPages(data)
+	if meta.Pages == 0 {
+		// PDF 1.5+ 可能把页面对象放在压缩的对象流中
+		meta.Pages = countPDFPages(inflatePDFObjectStreams(data))
+	}
+
+	for _, loc := range pdfInfoPattern.FindAllSubmatchIndex(data, -1) {
+		key := string(data[loc[2]:loc[3]])
+		var raw []byte
+		if data[loc[4]] == '(' {
+			raw, _ = readPDFLiteral(data, loc[4])
+		} else {
+			end := bytes.IndexByte(data[loc[4]:], '>')
+			if end < 0 {
+				continue
+			}
+			raw = decodeHexString(data[loc[4]+1 : loc[4]+end])
+		}
+		value := strings.TrimSpace(decodePDFString(raw))
+		if value == "" {
+			continue
+		}
+		switch key {
+		case "Title":
+			meta.Title = value
+		case "Author":
+			meta.Author = value
+		case "Subject":
+			meta.Subject = value
+		case "Keywords":
+			meta.Keywords = value
+		}
+	}
+
+	return meta, nil
+}
+
+// countPDFPages 统计页面对象数量，找不到时使用页面树根节点的 /Count
+func countPDFPages(data []byte) int {
+	if pages := len(pdfPagePattern.FindAllIndex(data, -1)); pages > 0 {
+		return pages
+	}
+	count := 0
+	for _, m := range pdfCountPattern.FindAllSubmatch(data, -1) {
+		digits := m[1]
+		if len(digits) == 0 {
+			digits = m[2]
+		}
+		if n, err := strconv.Atoi(string(digits)); err == nil && n > count {
+			count = n
+		}
+	}
+	return count
+}
+
+// inflatePDFObjectStreams 解压所有对象流（/Type /ObjStm）并拼接
+func inflatePDFObjectStreams(data []byte) []byte {
+	var out bytes.Buffer
+	for _, loc := range pdfStreamPattern.FindAllIndex(data, -1) {
+		dictStart := max(0, loc[0]-4096)
+		if i := bytes.LastIndex(data[dictStart:loc[0]], []byte("obj")); i >= 0 {
+			dictStart += i
+		}
+		dict := data[dictStart:loc[0]]
+		if !bytes.Contains(dict, []byte("/ObjStm")) || !bytes.Contains(dict, []byte("/FlateDecode")) {
+			continue
+		}
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+end]))
+		if err != nil {
+			continue
+		}
+		io.Copy(&out, io.LimitReader(zr, maxEntrySize))
+		zr.Close()
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// decodePDFString 解码 PDF 文本字符串（UTF-16BE 带 BOM 或 PDFDocEncoding）
+func decodePDFString(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	if utf8.Valid(raw) {
+		return string(raw)
+	}
+	return latin1String(raw)
+}
+
+// extractOOXMLMetadata 提取 docProps/core.xml 和 docProps/app.xml 中的元数据
+func extractOOXMLMetadata(zr *zip.Reader) (*DocMetadata, error) {
+	meta := &DocMetadata{}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "docProps/core.xml":
+			values, err := readXMLValues(f)
+			if err != nil {
+				return nil, err
+			}
+			meta.Title = values["title"]
+			meta.Author = values["creator"]
+			meta.Subject = values["subject"]
+			meta.Keywords = values["keywords"]
+		case "docProps/app.xml":
+			values, err := readXMLValues(f)
+			if err != nil {
+				return nil, err
+			}
+			if pages, err := strconv.Atoi(values["Pages"]); err == nil {
+				meta.Pages = pages
+			} else if slides, err := strconv.Atoi(values["Slides"]); err == nil {
+				meta.Pages = slides
+			}
+		}
+	}
+	return meta, nil
+}
+
+// readXMLValues 读取简单 XML 文档中各元素（按本地名称）的文本
+func readXMLValues(f *zip.File) (map[string]string, error) {
+	decoder, closer, err := openEntryDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	values := make(map[string]string)
+	var current string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			current = t.Name.Local
+		case xml.EndElement:
+			current = ""
+		case xml.CharData:
+			if current != "" {
+				values[current] += strings.TrimSpace(string(t))
+			}
+		}
+	}
+	return values, nil
+}
+
+// extractOLE2Metadata 提取 Office 97-2003 文件 SummaryInformation 中的元数据
+func extractOLE2Metadata(ole *oleFile) (*DocMetadata, error) {
+	data, err := ole.readStream("\x05SummaryInformation")
+	if err != nil {
+		return nil, err
+	}
+	props, err := parsePropertySet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &DocMetadata{
+		Title:    props.stringValue(pidTitle),
+		Author:   props.stringValue(pidAuthor),
+		Subject:  props.stringValue(pidSubject),
+		Keywords: props.stringValue(pidKeywords),
+		Pages:    props.intValue(pidPageCount),
+	}
+
+	// PowerPoint 的幻灯片数保存在 DocumentSummaryInformation 中
+	if meta.Pages == 0 {
+		if docData, err := ole.readStream("\x05DocumentSummaryInformation"); err == nil {
+			if docProps, err := parsePropertySet(docData); err == nil {
+				meta.Pages = docProps.intValue(0x07)
+			}
+		}
+	}
+	return meta, nil
+}
+
+// propertySet 属性集中的属性（原始值，包含类型标记）
+type propertySet struct {
+	codepage int
+	values   map[uint32][]byte
+}
+
+// parsePropertySet 解析属性集流的第一个节
+func parsePropertySet(data []byte) (*propertySet, error) {
+	if len(data) < 48 || binary.LittleEndian.Uint16(data[0:2]) != 0xFFFE {
+		return nil, fmt.Errorf("属性集格式异常")
+	}
+	sectionOffset := int(binary.LittleEndian.Uint32(data[44:48]))
+	if sectionOffset+8 > len(data) {
+		return nil, fmt.Errorf("属性集格式异常")
+	}
+	section := data[sectionOffset:]
+	sectionSize := int(binary.LittleEndian.Uint32(section[0:4]))
+	if sectionSize > len(section) || sectionSize < 8 {
+		sectionSize = len(section)
+	}
+	section = section[:sectionSize]
+	count := int(binary.LittleEndian.Uint32(section[4:8]))
+
+	props := &propertySet{values: make(map[uint32][]byte)}
+	for i := 0; i < count && 8+8*i+8 <= len(section); i++ {
+		id := binary.LittleEndian.Uint32(section[8+8*i:])
+		offset := int(binary.LittleEndian.Uint32(section[12+8*i:]))
+		if offset+4 > len(section) {
+			continue
+		}
+		props.values[id] = section[offset:]
+	}
+	if v, ok := props.values[pidCodepage]; ok && binary.LittleEndian.Uint16(v) == vtI2 && len(v) >= 6 {
+		props.codepage = int(binary.LittleEndian.Uint16(v[4:6]))
+	}
+	return props, nil
+}
+
+// intValue 读取整数属性
+func (p *propertySet) intValue(id uint32) int {
+	v, ok := p.values[id]
+	if !ok || len(v) < 8 {
+		return 0
+	}
+	switch binary.LittleEndian.Uint16(v) {
+	case vtI4:
+		return int(int32(binary.LittleEndian.Uint32(v[4:8])))
+	case vtI2:
+		return int(int16(binary.LittleEndian.Uint16(v[4:6])))
+	}
+	return 0
+}
+
+// stringValue 读取字符串属性，按属性集的代码页解码
+func (p *propertySet) stringValue(id uint32) string {
+	v, ok := p.values[id]
+	if !ok || len(v) < 8 {
+		return ""
+	}
+	length := int(binary.LittleEndian.Uint32(v[4:8]))
+	switch binary.LittleEndian.Uint16(v) {
+	case vtLPSTR:
+		if length > len(v)-8 {
+			return ""
+		}
+		raw := bytes.TrimRight(v[8:8+length], "\x00")
+		return strings.TrimSpace(decodeCodepage(raw, p.codepage))
+	case vtLPWSTR:
+		if 2*length > len(v)-8 {
+			return ""
+		}
+		units := make([]uint16, 0, length)
+		for i := 0; i < length; i++ {
+			u := binary.LittleEndian.Uint16(v[8+2*i:])
+			if u == 0 {
+				break
+			}
+			units = append(units, u)
+		}
+		return strings.TrimSpace(string(utf16.Decode(units)))
+	}
+	return ""
+}
+
+// decodeCodepage 按 Windows 代码页解码字符串
+func decodeCodepage(raw []byte, codepage int) string {
+	var enc encoding.Encoding
+	switch codepage {
+	case 65001:
+		return string(raw)
+	case 936:
+		enc = simplifiedchinese.GBK
+	case 950:
+		enc = traditionalchinese.Big5
+	case 1252:
+		enc = charmap.Windows1252
+	default:
+		if utf8.Valid(raw) {
+			return string(raw)
+		}
+		enc = simplifiedchinese.GBK
+	}
+	decoded, err := enc.NewDecoder().Bytes(raw)
+	if err != nil {
+		return string(raw)
+	}
+	return string(decoded)
+}
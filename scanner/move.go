@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var errS3MoveUnsupported = errors.New("不支持把文件移动到 S3，请使用导出")
+
+// Move 把文件移动到目标目录，导出路径和冲突处理与 Export 相同。同一文件系统内直接重命名，
+// 跨文件系统时先复制、校验 SHA-256 后再删除源文件。每个移动都写入操作日志，可以用 UndoOperation 撤销。
+// 移动不会覆盖目标位置已有的文件（覆盖后无法撤销），压缩包内和远程存储中的文件不能移动
+func (e *Exporter) Move(ctx context.Context, options ExportOptions) (*ExportResult, error) {
+	if len(options.FindingTypes) > 0 {
+		options.Files = FilterByFindingTypes(options.Files, options.FindingTypes)
+	}
+	if IsS3Path(options.DestPath) {
+		return nil, errS3MoveUnsupported
+	}
+
+	if err := os.MkdirAll(options.DestPath, 0755); err != nil {
+		return nil, fmt.Errorf("无法创建目标目录: %w", err)
+	}
+	destRoot, err := filepath.Abs(options.DestPath)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析目标目录: %w", err)
+	}
+
+	result := &ExportResult{
+		FailedFiles:  make([]string, 0),
+		SkippedFiles: make([]string, 0),
+	}
+	items, err := planExport(options, result)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := createJournal(OperationMove, destRoot)
+	if err != nil {
+		return nil, err
+	}
+	result.JournalID = j.op.ID
+	moved := 0 // 已移动（可能需要撤销）的文件数，没有时删除操作日志
+	defer func() { j.close(moved == 0) }()
+
+	tracker := e.newTracker(items)
+	defer tracker.finish()
+
+	// 逐个移动，操作日志中的顺序即移动顺序，撤销时倒序恢复
+	for _, item := range items {
+		if ctx.Err() != nil {
+			result.Canceled = true
+			break
+		}
+		file := item.file
+		tracker.setCurrent(file.Name)
+
+		if !isLocalPath(file.Path) {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			tracker.fileDone(file, 0, true)
+			continue
+		}
+		src, err := filepath.Abs(file.Path)
+		if err != nil {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			tracker.fileDone(file, 0, true)
+			continue
+		}
+		dst := filepath.Join(destRoot, filepath.FromSlash(item.rel))
+
+		// 目标已存在（包括源文件本身已在目标位置）时跳过，不计入成功
+		if _, err := os.Lstat(dst); err == nil {
+			result.SkippedFiles = append(result.SkippedFiles, file.Path)
+			tracker.fileDone(file, 0, false)
+			continue
+		}
+
+		// 先写意图记录再移动，程序在移动过程中退出时撤销也能找到这个文件
+		if err := j.begin(src, dst); err != nil {
+			return result, err
+		}
+		copied, err := moveFileContext(ctx, src, dst, tracker.addBytes)
+		if err != nil && ctx.Err() != nil {
+			// 被取消的文件保留在原位置，撤销时按意图记录检查
+			result.Canceled = true
+			break
+		}
+		if err != nil {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			tracker.fileDone(file, copied, true)
+			continue
+		}
+
+		// 写不了完成记录时把文件移回去，意图记录在撤销时会被识别为未移动
+		if err := j.commit(src, dst); err != nil {
+			if moveFile(dst, src) != nil {
+				moved++
+			}
+			return result, err
+		}
+		moved++
+		result.Success++
+		tracker.fileDone(file, copied, false)
+	}
+
+	if moved == 0 {
+		result.JournalID = ""
+	}
+	return result, nil
+}
+
+// isLocalPath 是否为本地文件（不在压缩包内、不是远程地址或已挂载的存储后端）
+func isLocalPath(p string) bool {
+	if IsArchivePath(p) || urlScheme(p) != "" {
+		return false
+	}
+	m, _ := findMount(p)
+	return m == nil
+}
+
+// moveFile 移动单个文件，不报告进度
+func moveFile(src, dst string) error {
+	_, err := moveFileContext(context.Background(), src, dst, nil)
+	return err
+}
+
+// moveFileContext 移动单个文件，返回复制的字节数（直接重命名时为 0）。
+// 只有跨文件系统无法重命名时（Linux、macOS 上为 EXDEV，Windows 上为 ERROR_NOT_SAME_DEVICE）
+// 才改为复制、校验、保留属性后删除源文件；删除失败时删除副本，源文件保持不变。其他重命名错误直接返回
+func moveFileContext(ctx context.Context, src, dst string, progress func(n int64)) (int64, error) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return 0, fmt.Errorf("不是普通文件: %s", src)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(src, dst); !isCrossDevice(err) {
+		return 0, err
+	}
+
+	res, err := copyFile(ctx, src, dst, copyOptions{verify: true, preserve: true}, progress)
+	if err != nil {
+		return res.copied, err
+	}
+	if err := os.Remove(src); err != nil {
+		os.Remove(dst)
+		return res.copied, fmt.Errorf("无法删除源文件: %w", err)
+	}
+	return res.copied, nil
+}
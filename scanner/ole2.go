@@ -0,0 +1,236 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// OLE2（复合文档）扇区标记
+const (
+	oleMaxRegSector = 0xFFFFFFFA // 大于此值的扇区号为特殊标记（空闲、链尾、FAT、DIFAT）
+
+	oleDirEntrySize  = 128
+	oleStreamType    = 2
+	oleRootEntryType = 5
+)
+
+var errOLEChain = errors.New("OLE2扇区链异常")
+
+// oleDirEntry 复合文档目录项
+type oleDirEntry struct {
+	name        string
+	objectType  byte
+	startSector uint32
+	size        uint64
+}
+
+// oleFile 只读的复合文档解析器，只实现按名称读取流所需的部分
+type oleFile struct {
+	r              io.ReaderAt
+	size           int64
+	sectorSize     int
+	miniSectorSize int
+	miniCutoff     uint64
+	fat            []uint32
+	miniFAT        []uint32
+	entries        []oleDirEntry
+	miniStream     []byte
+}
+
+// openOLE 解析复合文档头部、FAT 和目录
+func openOLE(r io.ReaderAt, size int64) (*oleFile, error) {
+	header := make([]byte, 512)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("无法读取OLE2头部: %w", err)
+	}
+	if string(header[:8]) != string(oleMagic) {
+		return nil, errors.New("OLE2签名不匹配")
+	}
+
+	sectorShift := binary.LittleEndian.Uint16(header[30:32])
+	miniShift := binary.LittleEndian.Uint16(header[32:34])
+	if sectorShift != 9 && sectorShift != 12 {
+		return nil, errors.New("OLE2扇区大小异常")
+	}
+
+	f := &oleFile{
+		r:              r,
+		size:           size,
+		sectorSize:     1 << sectorShift,
+		miniSectorSize: 1 << miniShift,
+		miniCutoff:     uint64(binary.LittleEndian.Uint32(header[56:60])),
+	}
+
+	// 读取 DIFAT：头部中的 109 项，以及后续的 DIFAT 扇区
+	var fatSectors []uint32
+	for i := 0; i < 109; i++ {
+		sector := binary.LittleEndian.Uint32(header[76+4*i:])
+		if sector <= oleMaxRegSector {
+			fatSectors = append(fatSectors, sector)
+		}
+	}
+	difat := binary.LittleEndian.Uint32(header[68:72])
+	perSector := f.sectorSize/4 - 1
+	for guard := 0; difat <= oleMaxRegSector && guard < 1<<16; guard++ {
+		data, err := f.readSector(difat)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < perSector; i++ {
+			sector := binary.LittleEndian.Uint32(data[4*i:])
+			if sector <= oleMaxRegSector {
+				fatSectors = append(fatSectors, sector)
+			}
+		}
+		difat = binary.LittleEndian.Uint32(data[4*perSector:])
+	}
+
+	for _, sector := range fatSectors {
+		data, err := f.readSector(sector)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(data); i += 4 {
+			f.fat = append(f.fat, binary.LittleEndian.Uint32(data[i:]))
+		}
+	}
+
+	// 读取目录
+	dirData, err := f.readChain(binary.LittleEndian.Uint32(header[48:52]), 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+oleDirEntrySize <= len(dirData); i += oleDirEntrySize {
+		raw := dirData[i : i+oleDirEntrySize]
+		nameLen := int(binary.LittleEndian.Uint16(raw[64:66]))
+		if nameLen < 2 || nameLen > 64 {
+			f.entries = append(f.entries, oleDirEntry{})
+			continue
+		}
+		units := make([]uint16, nameLen/2-1)
+		for j := range units {
+			units[j] = binary.LittleEndian.Uint16(raw[2*j:])
+		}
+		entry := oleDirEntry{
+			name:        string(utf16.Decode(units)),
+			objectType:  raw[66],
+			startSector: binary.LittleEndian.Uint32(raw[116:120]),
+			size:        binary.LittleEndian.Uint64(raw[120:128]),
+		}
+		if f.sectorSize == 512 {
+			// 版本3的文件只使用低32位
+			entry.size &= 0xFFFFFFFF
+		}
+		f.entries = append(f.entries, entry)
+	}
+	if len(f.entries) == 0 || f.entries[0].objectType != oleRootEntryType {
+		return nil, errors.New("OLE2目录异常")
+	}
+
+	// 读取 MiniFAT 和 Mini Stream
+	miniFATStart := binary.LittleEndian.Uint32(header[60:64])
+	if miniFATStart <= oleMaxRegSector {
+		data, err := f.readChain(miniFATStart, 0)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(data); i += 4 {
+			f.miniFAT = append(f.miniFAT, binary.LittleEndian.Uint32(data[i:]))
+		}
+	}
+	root := f.entries[0]
+	if root.startSector <= oleMaxRegSector && root.size > 0 {
+		if f.miniStream, err = f.readChain(root.startSector, root.size); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// readSector 读取指定扇区
+func (f *oleFile) readSector(sector uint32) ([]byte, error) {
+	offset := int64(sector+1) * int64(f.sectorSize)
+	if offset+int64(f.sectorSize) > f.size {
+		return nil, errOLEChain
+	}
+	data := make([]byte, f.sectorSize)
+	if _, err := f.r.ReadAt(data, offset); err != nil {
+		return nil, fmt.Errorf("无法读取OLE2扇区: %w", err)
+	}
+	return data, nil
+}
+
+// readChain 沿 FAT 链读取数据，size 为 0 时读取整条链
+func (f *oleFile) readChain(start uint32, size uint64) ([]byte, error) {
+	if size > maxEntrySize {
+		return nil, errors.New("OLE2数据流过大")
+	}
+	var data []byte
+	sector := start
+	for count := 0; sector <= oleMaxRegSector; count++ {
+		// 链长度不可能超过扇区总数，超过说明存在环
+		if count > len(f.fat) || int(sector) >= len(f.fat) {
+			return nil, errOLEChain
+		}
+		chunk, err := f.readSector(sector)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+		if size > 0 && uint64(len(data)) >= size {
+			return data[:size], nil
+		}
+		sector = f.fat[sector]
+	}
+	if size > 0 && uint64(len(data)) < size {
+		return nil, errOLEChain
+	}
+	return data, nil
+}
+
+// readMiniChain 沿 MiniFAT 链从 Mini Stream 读取数据
+func (f *oleFile) readMiniChain(start uint32, size uint64) ([]byte, error) {
+	data := make([]byte, 0, size)
+	sector := start
+	for count := 0; sector <= oleMaxRegSector && uint64(len(data)) < size; count++ {
+		offset := int(sector) * f.miniSectorSize
+		if count > len(f.miniFAT) || int(sector) >= len(f.miniFAT) || offset+f.miniSectorSize > len(f.miniStream) {
+			return nil, errOLEChain
+		}
+		data = append(data, f.miniStream[offset:offset+f.miniSectorSize]...)
+		sector = f.miniFAT[sector]
+	}
+	if uint64(len(data)) < size {
+		return nil, errOLEChain
+	}
+	return data[:size], nil
+}
+
+// hasStream 是否存在指定名称的流
+func (f *oleFile) hasStream(name string) bool {
+	for _, entry := range f.entries {
+		if entry.objectType == oleStreamType && strings.EqualFold(entry.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// readStream 按名称读取流（不区分所在的存储）
+func (f *oleFile) readStream(name string) ([]byte, error) {
+	for _, entry := range f.entries {
+		if entry.objectType != oleStreamType || !strings.EqualFold(entry.name, name) {
+			continue
+		}
+		if entry.size < f.miniCutoff {
+			return f.readMiniChain(entry.startSector, entry.size)
+		}
+		return f.readChain(entry.startSector, entry.size)
+	}
+	return nil, fmt.Errorf("OLE2中不存在数据流 %s", name)
+}
@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 路径模板示例：
+//
+//	{type}/{modYear}/{modMonth}/{name}
+//	{author}/{ext}/{name}
+//
+// 变量值中的路径分隔符会替换为 _，值为空时使用“未知”；
+// 模板最后一级不含 {name} 或 {stem} 时自动在末尾添加 /{name}。
+
+// 变量值为空时使用的名称
+const templateUnknown = "未知"
+
+// templateVar 模板变量，meta 表示需要文档元数据
+type templateVar struct {
+	value func(f *FileInfo) string
+	meta  bool
+}
+
+// 路径模板变量
+var templateVars = map[string]templateVar{
+	"name": {value: func(f *FileInfo) string { return f.Name }},
+	"stem": {value: func(f *FileInfo) string { return strings.TrimSuffix(f.Name, filepath.Ext(f.Name)) }},
+	"ext":  {value: func(f *FileInfo) string { return strings.TrimPrefix(strings.ToLower(f.Extension), ".") }},
+	"type": {value: func(f *FileInfo) string { return f.FileType }},
+	"modYear": {value: func(f *FileInfo) string {
+		return strconv.Itoa(f.ModTime.Local().Year())
+	}},
+	"modMonth": {value: func(f *FileInfo) string {
+		return fmt.Sprintf("%02d", int(f.ModTime.Local().Month()))
+	}},
+	"modDay": {value: func(f *FileInfo) string {
+		return fmt.Sprintf("%02d", f.ModTime.Local().Day())
+	}},
+	"label": {value: func(f *FileInfo) string {
+		if len(f.Labels) == 0 {
+			return ""
+		}
+		return f.Labels[0]
+	}},
+	"root": {value: func(f *FileInfo) string {
+		// 扫描根路径的目录名
+		parts := strings.FieldsFunc(archiveDirPath(f.Root), isPathSep)
+		if len(parts) == 0 {
+			return ""
+		}
+		return parts[len(parts)-1]
+	}},
+	"parent": {value: func(f *FileInfo) string { return parentDirName(f.Path) }},
+	"dir": {value: func(f *FileInfo) string {
+		// 相对于扫描根路径的目录，保留其中的分隔符
+		rel, ok := relativeTo(archiveDirPath(f.Root), archiveDirPath(f.Path))
+		if !ok {
+			return ""
+		}
+		if i := strings.LastIndexAny(rel, `/\`); i >= 0 {
+			return rel[:i]
+		}
+		return ""
+	}},
+	"author":  {meta: true, value: func(f *FileInfo) string { return f.Metadata.Author }},
+	"title":   {meta: true, value: func(f *FileInfo) string { return f.Metadata.Title }},
+	"subject": {meta: true, value: func(f *FileInfo) string { return f.Metadata.Subject }},
+}
+
+// templatePart 模板的一段：文本或变量
+type templatePart struct {
+	text     string
+	variable string
+}
+
+// PathTemplate 解析后的导出路径模板
+type PathTemplate struct {
+	parts    []templatePart
+	needMeta bool
+}
+
+// ParsePathTemplate 解析导出路径模板
+func ParsePathTemplate(text string) (*PathTemplate, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("路径模板不能为空")
+	}
+	t := &PathTemplate{}
+	for text != "" {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{text: text})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{text: text[:open]})
+		}
+		end := strings.IndexByte(text[open:], '}')
+		if end < 0 {
+			return nil, errors.New("路径模板中的 { 没有闭合")
+		}
+		name := text[open+1 : open+end]
+		v, ok := templateVars[name]
+		if !ok {
+			return nil, fmt.Errorf("未知的模板变量 {%s}", name)
+		}
+		t.needMeta = t.needMeta || v.meta
+		t.parts = append(t.parts, templatePart{variable: name})
+		text = text[open+end+1:]
+	}
+
+	// 最后一级不含文件名时添加 /{name}
+	for i := len(t.parts) - 1; i >= 0; i-- {
+		part := t.parts[i]
+		if part.variable == "name" || part.variable == "stem" {
+			return t, nil
+		}
+		if strings.ContainsAny(part.text, `/\`) {
+			break
+		}
+	}
+	t.parts = append(t.parts, templatePart{text: "/"}, templatePart{variable: "name"})
+	return t, nil
+}
+
+// Render 生成文件的导出路径（以 / 分隔），扫描结果中没有元数据而模板需要时临时提取
+func (t *PathTemplate) Render(file FileInfo) string {
+	if t.needMeta && file.Metadata == nil {
+		if meta, err := ExtractMetadata(file.Path); err == nil {
+			file.Metadata = meta
+		} else {
+			file.Metadata = &DocMetadata{}
+		}
+	}
+
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.variable == "" {
+			b.WriteString(part.text)
+			continue
+		}
+		value := strings.TrimSpace(templateVars[part.variable].value(&file))
+		if part.variable != "dir" {
+			value = strings.NewReplacer("/", "_", `\`, "_").Replace(value)
+		}
+		if value == "" && part.variable != "dir" {
+			value = templateUnknown
+		}
+		b.WriteString(value)
+	}
+
+	return cleanRelPath(b.String(), file.Name)
+}
@@ -0,0 +1,356 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 敏感信息类型
+const (
+	FindingIDCard   = "idcard"   // 居民身份证号
+	FindingMobile   = "mobile"   // 手机号
+	FindingBankCard = "bankcard" // 银行卡号
+	FindingEmail    = "email"    // 电子邮箱
+
+	customFindingPrefix = "custom:" // 自定义规则的类型前缀
+	maxFindingSamples   = 3         // 每种类型最多保留的脱敏样例数
+)
+
+// Finding 敏感信息检测结果
+type Finding struct {
+	Type    string   `json:"type"`    // idcard, mobile, bankcard, email, custom:<名称>
+	Count   int      `json:"count"`   // 出现次数
+	Samples []string `json:"samples"` // 脱敏后的样例
+}
+
+// CustomPattern 用户自定义的检测规则
+type CustomPattern struct {
+	Name    string `json:"name"`    // 规则名称
+	Pattern string `json:"pattern"` // 正则表达式
+}
+
+// 内置检测规则
+var (
+	idCardPattern   = regexp.MustCompile(`\d{17}[\dXx]`)
+	mobilePattern   = regexp.MustCompile(`(?:\+?86[- ]?)?1[3-9]\d(?:[- ]?\d{4}){2}`)
+	bankCardPattern = regexp.MustCompile(`\d(?:[ -]?\d){12,18}`)
+	emailPattern    = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+)
+
+// GB 11643 校验码计算参数
+var (
+	idCardWeights    = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardCheckCodes = "10X98765432"
+)
+
+// compiledPattern 编译后的自定义规则
+type compiledPattern struct {
+	findingType string
+	re          *regexp.Regexp
+}
+
+// ContentInspector 文档内容敏感信息检测器
+type ContentInspector struct {
+	custom []compiledPattern
+}
+
+// NewContentInspector 创建检测器，自定义规则无效时返回错误
+func NewContentInspector(patterns []CustomPattern) (*ContentInspector, error) {
+	inspector := &ContentInspector{}
+	for _, p := range patterns {
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			return nil, fmt.Errorf("自定义规则缺少名称: %s", p.Pattern)
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("无效的自定义规则 %s: %w", name, err)
+		}
+		inspector.custom = append(inspector.custom, compiledPattern{
+			findingType: customFindingPrefix + name,
+			re:          re,
+		})
+	}
+	return inspector, nil
+}
+
+// InspectFile 提取文件文本并检测敏感信息
+func (c *ContentInspector) InspectFile(path string) ([]Finding, error) {
+	text, err := ExtractText(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	return c.Inspect(text), nil
+}
+
+// Inspect 检测文本中的敏感信息
+func (c *ContentInspector) Inspect(text string) []Finding {
+	collector := newFindingCollector()
+
+	// 按顺序检测，已被识别的数字片段不再参与后续规则，避免身份证号被重复识别为银行卡号
+	var covered [][2]int
+	overlaps := func(start, end int) bool {
+		for _, span := range covered {
+			if start < span[1] && end > span[0] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, loc := range idCardPattern.FindAllStringIndex(text, -1) {
+		if !digitBoundary(text, loc[0], loc[1]) {
+			continue
+		}
+		id := text[loc[0]:loc[1]]
+		if ValidateIDCard(id) {
+			collector.add(FindingIDCard, maskMiddle(id, 6, 4))
+			covered = append(covered, [2]int{loc[0], loc[1]})
+		}
+	}
+
+	for _, loc := range mobilePattern.FindAllStringIndex(text, -1) {
+		if !digitBoundary(text, loc[0], loc[1]) || overlaps(loc[0], loc[1]) {
+			continue
+		}
+		digits := onlyDigits(text[loc[0]:loc[1]])
+		digits = digits[len(digits)-11:]
+		collector.add(FindingMobile, maskMiddle(digits, 3, 4))
+		covered = append(covered, [2]int{loc[0], loc[1]})
+	}
+
+	for _, loc := range bankCardPattern.FindAllStringIndex(text, -1) {
+		if !digitBoundary(text, loc[0], loc[1]) || overlaps(loc[0], loc[1]) {
+			continue
+		}
+		digits := onlyDigits(text[loc[0]:loc[1]])
+		if isBankCardNumber(digits) {
+			collector.add(FindingBankCard, maskMiddle(digits, 4, 4))
+			covered = append(covered, [2]int{loc[0], loc[1]})
+		}
+	}
+
+	for _, email := range emailPattern.FindAllString(text, -1) {
+		collector.add(FindingEmail, maskEmail(email))
+	}
+
+	for _, p := range c.custom {
+		for _, match := range p.re.FindAllString(text, -1) {
+			if match == "" {
+				continue
+			}
+			keep := utf8.RuneCountInString(match) / 4
+			collector.add(p.findingType, maskMiddle(match, keep, keep))
+		}
+	}
+
+	return collector.findings()
+}
+
+// ValidateIDCard 校验18位居民身份证号（出生日期与 GB 11643 校验码）
+func ValidateIDCard(id string) bool {
+	if len(id) != 18 {
+		return false
+	}
+
+	// 地址码首位：1-6 为各省级行政区，8 为港澳台居民居住证
+	if id[0] < '1' || id[0] > '8' || id[0] == '7' {
+		return false
+	}
+
+	birth, err := time.Parse("20060102", id[6:14])
+	if err != nil || birth.Year() < 1900 || birth.After(time.Now()) {
+		return false
+	}
+
+	sum := 0
+	for i, w := range idCardWeights {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		sum += int(id[i]-'0') * w
+	}
+	check := id[17]
+	if check == 'x' {
+		check = 'X'
+	}
+	return idCardCheckCodes[sum%11] == check
+}
+
+// ValidateLuhn 使用 Luhn 算法校验数字串
+func ValidateLuhn(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// isBankCardNumber 判断是否是可能的银行卡号（13-19位，常见发卡机构前缀，通过 Luhn 校验）
+func isBankCardNumber(digits string) bool {
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	// 银联 62、Visa 4、万事达 5/2、美国运通 34/37、JCB 35
+	if digits[0] < '2' || digits[0] > '6' {
+		return false
+	}
+	// 全部相同的数字（如 0000...）不是卡号
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+	return ValidateLuhn(digits)
+}
+
+// digitBoundary 判断匹配片段两端是否不与其他数字相连
+func digitBoundary(text string, start, end int) bool {
+	if start > 0 && isDigitByte(text[start-1]) {
+		return false
+	}
+	if end < len(text) && (isDigitByte(text[end]) || text[end] == 'X' || text[end] == 'x') {
+		return false
+	}
+	return true
+}
+
+func isDigitByte(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// onlyDigits 去除数字串中的分隔符
+func onlyDigits(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if isDigitByte(s[i]) || s[i] == 'X' || s[i] == 'x' {
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// maskMiddle 保留首尾若干字符，其余替换为 *
+func maskMiddle(s string, keepStart, keepEnd int) string {
+	runes := []rune(s)
+	if keepStart+keepEnd >= len(runes) {
+		keepStart, keepEnd = 0, 0
+		if len(runes) > 2 {
+			keepStart = 1
+		}
+	}
+	masked := make([]rune, len(runes))
+	for i, r := range runes {
+		if i < keepStart || i >= len(runes)-keepEnd {
+			masked[i] = r
+		} else {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
+
+// maskEmail 邮箱脱敏：保留用户名首字符和域名
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return maskMiddle(email, 1, 0)
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// findingCollector 汇总检测结果
+type findingCollector struct {
+	order []string
+	items map[string]*Finding
+}
+
+func newFindingCollector() *findingCollector {
+	return &findingCollector{items: make(map[string]*Finding)}
+}
+
+// add 记录一次命中
+func (c *findingCollector) add(findingType, sample string) {
+	item, ok := c.items[findingType]
+	if !ok {
+		item = &Finding{Type: findingType, Samples: make([]string, 0, maxFindingSamples)}
+		c.items[findingType] = item
+		c.order = append(c.order, findingType)
+	}
+	item.Count++
+	if len(item.Samples) >= maxFindingSamples {
+		return
+	}
+	for _, s := range item.Samples {
+		if s == sample {
+			return
+		}
+	}
+	item.Samples = append(item.Samples, sample)
+}
+
+// findings 返回汇总结果
+func (c *findingCollector) findings() []Finding {
+	if len(c.order) == 0 {
+		return nil
+	}
+	result := make([]Finding, 0, len(c.order))
+	for _, t := range c.order {
+		result = append(result, *c.items[t])
+	}
+	return result
+}
+
+// HasFindingType 判断文件是否包含任一指定类型的敏感信息，types 为空时判断是否包含任意敏感信息
+func (f FileInfo) HasFindingType(types []string) bool {
+	if len(types) == 0 {
+		return len(f.Findings) > 0
+	}
+	for _, finding := range f.Findings {
+		for _, t := range types {
+			if finding.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterByFindingTypes 筛选包含指定类型敏感信息的文件
+func FilterByFindingTypes(files []FileInfo, types []string) []FileInfo {
+	var result []FileInfo
+	for _, file := range files {
+		if file.HasFindingType(types) {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// countFindings 统计每种敏感信息涉及的文件数
+func countFindings(files []FileInfo) map[string]int {
+	counts := make(map[string]int)
+	for _, file := range files {
+		for _, finding := range file.Findings {
+			counts[finding.Type]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateIDCard(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"11010519491231002X", true},
+		{"11010519491231002x", true},
+		{"440306200002291239", true},  // 闰日
+		{"810000199001010019", true},  // 港澳台居民居住证
+		{"110105194912310021", false}, // 校验码错误
+		{"440306200102291239", false}, // 2001 年没有 2 月 29 日
+		{"310101200013010015", false}, // 月份无效
+		{"110105189912310012", false}, // 1900 年以前
+		{"110105300001010012", false}, // 未来的日期
+		{"710000199001010015", false}, // 地址码首位无效
+		{"010000199001010015", false},
+		{"11010519491231002", false},
+		{"11010519491231002XX", false},
+		{"1101051949123100AX", false},
+	}
+	for _, tt := range tests {
+		if got := ValidateIDCard(tt.id); got != tt.want {
+			t.Errorf("ValidateIDCard(%s) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestValidateLuhn(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   bool
+	}{
+		{"79927398713", true},
+		{"79927398710", false},
+		{"6222021234567890128", true},
+		{"6222021234567890127", false},
+		{"00", true},
+		{"0", false},
+		{"", false},
+		{"7992 7398 713", false},
+	}
+	for _, tt := range tests {
+		if got := ValidateLuhn(tt.digits); got != tt.want {
+			t.Errorf("ValidateLuhn(%q) = %v, want %v", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestIsBankCardNumber(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   bool
+	}{
+		{"6222021234567890128", true}, // 银联 19 位
+		{"4111111111111111", true},    // Visa
+		{"37828224631003", true},
+		{"4111111111111112", false},     // Luhn 校验失败
+		{"123456789012347", false},      // 发卡机构前缀无效
+		{"411111111111", false},         // 过短
+		{"62220212345678901280", false}, // 过长
+		{"5555555555555555", false},     // 全部相同
+	}
+	for _, tt := range tests {
+		if got := isBankCardNumber(tt.digits); got != tt.want {
+			t.Errorf("isBankCardNumber(%s) = %v, want %v", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	inspector, err := NewContentInspector([]CustomPattern{{Name: "合同编号", Pattern: `HT-\d{6}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		text string
+		want []Finding
+	}{
+		{"id card", "身份证 11010519491231002X。", []Finding{{Type: FindingIDCard, Count: 1, Samples: []string{"110105********002X"}}}},
+		// 校验失败的 18 位数字既不是身份证号也不是银行卡号
+		{"bad id card", "编号 110105194912310021", nil},
+		{"joined digits", "9911010519491231002X", nil},
+		{"mobile", "电话 138-1234-5678 或 +86 13812345678", []Finding{{Type: FindingMobile, Count: 2, Samples: []string{"138****5678"}}}},
+		{"bank card", "卡号 6222 0212 3456 7890 128", []Finding{{Type: FindingBankCard, Count: 1, Samples: []string{"6222***********0128"}}}},
+		{"luhn failure", "卡号 6222021234567890127", nil},
+		{"email", "联系 zhang.san@example.com.cn", []Finding{{Type: FindingEmail, Count: 1, Samples: []string{"z***@example.com.cn"}}}},
+		{"custom", "合同 HT-202401、HT-202402", []Finding{{Type: customFindingPrefix + "合同编号", Count: 2, Samples: []string{"HT*****01", "HT*****02"}}}},
+		{"mixed", "11010519491231002X 13812345678", []Finding{
+			{Type: FindingIDCard, Count: 1, Samples: []string{"110105********002X"}},
+			{Type: FindingMobile, Count: 1, Samples: []string{"138****5678"}},
+		}},
+	}
+	for _, tt := range tests {
+		if got := inspector.Inspect(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Inspect = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := NewContentInspector([]CustomPattern{{Name: "坏规则", Pattern: "("}}); err == nil {
+		t.Error("NewContentInspector accepted an invalid pattern")
+	}
+	if _, err := NewContentInspector([]CustomPattern{{Pattern: `\d+`}}); err == nil {
+		t.Error("NewContentInspector accepted a pattern without a name")
+	}
+}
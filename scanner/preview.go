@@ -0,0 +1,318 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 预览类型
+const (
+	PreviewImage = "image" // 文档内嵌的缩略图
+	PreviewText  = "text"  // 正文开头的文本
+)
+
+// 预览参数
+const (
+	previewTextSize  = 16 << 10 // 提取文本的上限
+	previewMaxLines  = 12       // 文本预览最多保留的段落/行数
+	previewMaxRunes  = 1200     // 文本预览最多保留的字符数
+	maxThumbnailSize = 4 << 20  // 缩略图最大 4MB
+	pidThumbnail     = 0x11     // SummaryInformation 中的缩略图属性
+	vtCF             = 0x47     // 剪贴板数据类型
+	cfMetafilePict   = 3        // Windows 图元文件（WMF）
+	cfDIB            = 8        // 设备无关位图
+	cfEnhMetafile    = 14       // 增强型图元文件（EMF）
+)
+
+// 浏览器可直接显示的缩略图格式
+var thumbnailMimeTypes = map[string]string{
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".bmp":  "image/bmp",
+}
+
+// Preview 文档预览
+type Preview struct {
+	Kind     string `json:"kind"`           // image 或 text
+	MimeType string `json:"mimeType"`       // 数据的 MIME 类型
+	DataURL  string `json:"dataUrl"`        // data: URL，可直接用于 <img> 或下载
+	Text     string `json:"text,omitempty"` // 文本预览内容
+	Note     string `json:"note,omitempty"` // 预览的说明，如缩略图无法显示而改为显示正文
+}
+
+// Previewer 文档预览生成器，结果缓存在磁盘上
+type Previewer struct {
+	cacheDir string
+}
+
+// NewPreviewer 创建预览生成器，cacheDir 为空时使用系统缓存目录
+func NewPreviewer(cacheDir string) *Previewer {
+	if cacheDir == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			cacheDir = filepath.Join(dir, "DocRadar", "previews")
+		} else {
+			cacheDir = filepath.Join(os.TempDir(), "DocRadar", "previews")
+		}
+	}
+	return &Previewer{cacheDir: cacheDir}
+}
+
+// GetPreview 获取文档预览：优先使用内嵌缩略图，没有时返回正文开头的文本
+func (p *Previewer) GetPreview(path string) (*Preview, error) {
+	// 压缩包内的文件以压缩包本身的大小和修改时间判断是否变化
+	stat, err := statPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法获取文件信息: %w", err)
+	}
+
+	// 缓存键包含路径、大小和修改时间，文件变化后自动失效
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", path, stat.Size(), stat.ModTime().UnixNano())))
+	key := hex.EncodeToString(sum[:])
+	cachePath := filepath.Join(p.cacheDir, key[:2], key+".json")
+
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var cached Preview
+		if json.Unmarshal(data, &cached) == nil {
+			// 修改时间记录最近一次使用，Prune 据此删除长期未使用的缓存
+			now := time.Now()
+			os.Chtimes(cachePath, now, now)
+			return &cached, nil
+		}
+	}
+
+	preview, err := buildPreview(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 写入缓存失败不影响返回结果
+	p.store(cachePath, preview)
+	return preview, nil
+}
+
+// store 原子地写入缓存文件
+func (p *Previewer) store(cachePath string, preview *Preview) {
+	data, err := json.Marshal(preview)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return
+	}
+	// 同一文件可能被同时预览，每次写入使用各自的临时文件
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), cachePath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Prune 删除超过 maxAge 未使用的缓存（读取缓存时会更新修改时间）
+func (p *Previewer) Prune(maxAge time.Duration) error {
+	cutoff := time.Now().Add(-maxAge)
+	return filepath.WalkDir(p.cacheDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// buildPreview 生成预览
+func buildPreview(path string) (*Preview, error) {
+	mimeType, data, metafile := extractThumbnail(path)
+	if data != nil {
+		return &Preview{
+			Kind:     PreviewImage,
+			MimeType: mimeType,
+			DataURL:  "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+		}, nil
+	}
+
+	text, err := ExtractText(path, previewTextSize)
+	if err != nil {
+		return nil, err
+	}
+	text = previewExcerpt(text)
+	preview := &Preview{
+		Kind:     PreviewText,
+		MimeType: "text/plain",
+		DataURL:  "data:text/plain;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(text)),
+		Text:     text,
+	}
+	if metafile {
+		preview.Note = "文档的缩略图是 EMF/WMF 图元文件，无法在预览中显示，改为显示正文开头"
+	}
+	return preview, nil
+}
+
+// previewExcerpt 截取前几个非空段落（表格为前几行）
+func previewExcerpt(text string) string {
+	var lines []string
+	runes := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		n := utf8.RuneCountInString(line)
+		if runes+n > previewMaxRunes {
+			lines = append(lines, string([]rune(line)[:previewMaxRunes-runes])+"…")
+			break
+		}
+		runes += n
+		lines = append(lines, line)
+		if len(lines) >= previewMaxLines {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// extractThumbnail 提取内嵌缩略图，返回 MIME 类型和数据；没有可显示的缩略图时返回 nil，
+// metafile 表示文档有缩略图但是网页无法显示的 EMF/WMF 图元文件
+func extractThumbnail(path string) (mimeType string, data []byte, metafile bool) {
+	file, err := openDocument(path)
+	if err != nil {
+		return "", nil, false
+	}
+	defer file.Close()
+
+	header := make([]byte, 8)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, zipMagic):
+		zr, err := zip.NewReader(file, file.Size())
+		if err != nil {
+			return "", nil, false
+		}
+		return ooxmlThumbnail(zr)
+	case bytes.HasPrefix(header, oleMagic):
+		ole, err := openOLE(file, file.Size())
+		if err != nil {
+			return "", nil, false
+		}
+		return ole2Thumbnail(ole)
+	}
+	return "", nil, false
+}
+
+// ooxmlThumbnail 读取 docProps/thumbnail.*（EMF/WMF 无法在网页中显示，只记录存在）
+func ooxmlThumbnail(zr *zip.Reader) (string, []byte, bool) {
+	metafile := false
+	for _, f := range zr.File {
+		if !strings.HasPrefix(strings.ToLower(f.Name), "docprops/thumbnail.") {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(f.Name))
+		if ext == ".emf" || ext == ".wmf" {
+			metafile = true
+			continue
+		}
+		mimeType, ok := thumbnailMimeTypes[ext]
+		if !ok || f.UncompressedSize64 > maxThumbnailSize {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxThumbnailSize))
+		rc.Close()
+		if err == nil && len(data) > 0 {
+			return mimeType, data, false
+		}
+	}
+	return "", nil, metafile
+}
+
+// ole2Thumbnail 读取 SummaryInformation 中的缩略图（仅支持 DIB 位图，转换为 BMP）
+func ole2Thumbnail(ole *oleFile) (string, []byte, bool) {
+	data, err := ole.readStream("\x05SummaryInformation")
+	if err != nil {
+		return "", nil, false
+	}
+	props, err := parsePropertySet(data)
+	if err != nil {
+		return "", nil, false
+	}
+
+	v, ok := props.values[pidThumbnail]
+	if !ok || len(v) < 16 || binary.LittleEndian.Uint16(v) != vtCF {
+		return "", nil, false
+	}
+	size := int(binary.LittleEndian.Uint32(v[4:8]))
+	format := int32(binary.LittleEndian.Uint32(v[8:12]))
+	if size < 8 || size > len(v)-8 || format != -1 {
+		return "", nil, false
+	}
+	switch binary.LittleEndian.Uint32(v[12:16]) {
+	case cfDIB:
+	case cfMetafilePict, cfEnhMetafile:
+		// 图元文件无法在网页中显示
+		return "", nil, true
+	default:
+		return "", nil, false
+	}
+	bmp := dibToBMP(v[16 : 8+size])
+	if bmp == nil {
+		return "", nil, false
+	}
+	return "image/bmp", bmp, false
+}
+
+// dibToBMP 为设备无关位图补充 BMP 文件头
+func dibToBMP(dib []byte) []byte {
+	if len(dib) < 40 {
+		return nil
+	}
+	headerSize := binary.LittleEndian.Uint32(dib[0:4])
+	if headerSize < 40 || int(headerSize) > len(dib) {
+		return nil
+	}
+	bitCount := binary.LittleEndian.Uint16(dib[14:16])
+	compression := binary.LittleEndian.Uint32(dib[16:20])
+	colorsUsed := binary.LittleEndian.Uint32(dib[32:36])
+
+	if colorsUsed == 0 && bitCount <= 8 {
+		colorsUsed = 1 << bitCount
+	}
+	offset := 14 + headerSize + colorsUsed*4
+	if compression == 3 && headerSize == 40 { // BI_BITFIELDS 的颜色掩码
+		offset += 12
+	}
+
+	bmp := make([]byte, 14, 14+len(dib))
+	bmp[0], bmp[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(bmp[2:6], uint32(14+len(dib)))
+	binary.LittleEndian.PutUint32(bmp[10:14], offset)
+	return append(bmp, dib...)
+}
@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWordXML = `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:body><w:p><w:r><w:t>合同变更通知</w:t></w:r></w:p><w:p><w:r><w:t>第二段</w:t></w:r></w:p></w:body></w:document>`
+
+func TestPreviewThumbnails(t *testing.T) {
+	dir := t.TempDir()
+	png := []byte("\x89PNG\r\n\x1a\nfake")
+	withPNG := writeTestFile(t, dir, "png.docx", zipBytes(t, map[string][]byte{
+		"word/document.xml":      []byte(testWordXML),
+		"docProps/thumbnail.png": png,
+	}))
+	withEMF := writeTestFile(t, dir, "emf.docx", zipBytes(t, map[string][]byte{
+		"word/document.xml":      []byte(testWordXML),
+		"docProps/thumbnail.emf": []byte("\x01\x00\x00\x00emf"),
+	}))
+	plain := writeTestFile(t, dir, "plain.docx", zipBytes(t, map[string][]byte{
+		"word/document.xml": []byte(testWordXML),
+	}))
+
+	p := NewPreviewer(filepath.Join(dir, "cache"))
+	tests := []struct {
+		path     string
+		kind     string
+		mimeType string
+		note     bool
+	}{
+		{withPNG, PreviewImage, "image/png", false},
+		{withEMF, PreviewText, "text/plain", true},
+		{plain, PreviewText, "text/plain", false},
+	}
+	for _, tt := range tests {
+		preview, err := p.GetPreview(tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if preview.Kind != tt.kind || preview.MimeType != tt.mimeType || (preview.Note != "") != tt.note {
+			t.Errorf("%s: preview = %s %s note %q", filepath.Base(tt.path), preview.Kind, preview.MimeType, preview.Note)
+		}
+		if tt.kind == PreviewText && preview.Text != "合同变更通知\n第二段" {
+			t.Errorf("%s: text = %q", filepath.Base(tt.path), preview.Text)
+		}
+	}
+}
+
+func TestPreviewCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	used := writeTestFile(t, dir, "used.docx", zipBytes(t, map[string][]byte{"word/document.xml": []byte(testWordXML)}))
+	unused := writeTestFile(t, dir, "unused.docx", zipBytes(t, map[string][]byte{"word/document.xml": []byte(testWordXML)}))
+	cacheDir := filepath.Join(dir, "cache")
+	p := NewPreviewer(cacheDir)
+
+	for _, path := range []string{used, unused} {
+		if _, err := p.GetPreview(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var cached []string
+	filepath.WalkDir(cacheDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if !strings.HasSuffix(path, ".json") {
+				t.Errorf("unexpected cache file %s", path)
+			}
+			cached = append(cached, path)
+		}
+		return nil
+	})
+	if len(cached) != 2 {
+		t.Fatalf("cache files = %v, want 2", cached)
+	}
+
+	// 两个缓存都是 40 天前生成的，其中一个刚刚被使用过
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	for _, path := range cached {
+		os.Chtimes(path, old, old)
+	}
+	if _, err := p.GetPreview(used); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Prune(30 * 24 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, path := range cached {
+		if _, err := os.Stat(path); err == nil {
+			left = append(left, path)
+		}
+	}
+	if len(left) != 1 {
+		t.Fatalf("cache files after Prune = %v, want only the recently used one", left)
+	}
+
+	// 剩下的缓存属于最近使用的文件：删除后再预览会重新生成
+	os.Remove(left[0])
+	if _, err := p.GetPreview(used); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(left[0]); err != nil {
+		t.Errorf("cache for the used file was pruned: %v", err)
+	}
+}
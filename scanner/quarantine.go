@@ -0,0 +1,420 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 隔离区位于配置目录下的 quarantine，每个文件保存在 items/<ID>/ 中（保留原文件名），
+// manifest.json 记录原路径、隔离原因和时间，用于恢复和到期清理。
+// 移动文件之前先把条目写入 items/<ID>.json，批量隔离中途退出或清单写入失败时，
+// 读取清单会根据这些文件补回已隔离但不在清单中的条目
+
+// 隔离区清单版本
+const quarantineVersion = 1
+
+// 默认保留天数，超过后自动永久删除
+const defaultQuarantineRetention = 30
+
+// QuarantineEntry 隔离区中的文件
+type QuarantineEntry struct {
+	ID            string    `json:"id"`
+	OriginalPath  string    `json:"originalPath"` // 隔离前的路径，恢复到这里
+	Name          string    `json:"name"`
+	Size          int64     `json:"size"`
+	ModTime       time.Time `json:"modTime"`
+	Hash          string    `json:"hash,omitempty"`
+	Reason        string    `json:"reason"` // 隔离原因（如无效文件、重复文件）
+	QuarantinedAt time.Time `json:"quarantinedAt"`
+}
+
+// FileActionResult 隔离、恢复、删除或移到回收站的结果
+type FileActionResult struct {
+	Success     int      `json:"success"`
+	Failed      int      `json:"failed"`
+	FailedFiles []string `json:"failedFiles"`
+	Warning     string   `json:"warning,omitempty"` // 文件已处理但保存隔离区清单失败等情况
+}
+
+// quarantineManifest 隔离区清单格式
+type quarantineManifest struct {
+	Version       int               `json:"version"`
+	RetentionDays int               `json:"retentionDays"` // 保留天数，0 表示不自动删除
+	Entries       []QuarantineEntry `json:"entries"`
+}
+
+var quarantineMu sync.Mutex
+
+// quarantineDir 返回隔离区目录
+func quarantineDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "quarantine")
+	if err := os.MkdirAll(filepath.Join(dir, "items"), 0700); err != nil {
+		return "", fmt.Errorf("无法创建隔离区: %w", err)
+	}
+	return dir, nil
+}
+
+// quarantineItemPath 返回隔离文件的保存路径
+func quarantineItemPath(dir string, entry QuarantineEntry) string {
+	return filepath.Join(dir, "items", entry.ID, sanitizeName(entry.Name))
+}
+
+// quarantineEntryPath 返回隔离文件条目的保存路径（与 items/<ID> 目录同级，不会和隔离的文件重名）
+func quarantineEntryPath(dir, id string) string {
+	return filepath.Join(dir, "items", id+".json")
+}
+
+// writeQuarantineEntry 在移动文件之前保存条目
+func writeQuarantineEntry(dir string, entry QuarantineEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(quarantineEntryPath(dir, entry.ID), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("无法保存隔离记录: %w", err)
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("无法保存隔离记录: %w", err)
+	}
+	return nil
+}
+
+// removeQuarantineItem 删除隔离文件的目录和条目
+func removeQuarantineItem(dir, id string) error {
+	if err := os.RemoveAll(filepath.Join(dir, "items", id)); err != nil {
+		return err
+	}
+	if err := os.Remove(quarantineEntryPath(dir, id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// readQuarantine 读取隔离区清单，不存在时返回空清单。
+// 清单与 items 不一致时（隔离或恢复中途退出、清单写入失败）以 items 中实际存在的文件为准
+func readQuarantine(dir string) (*quarantineManifest, error) {
+	m := &quarantineManifest{Version: quarantineVersion, RetentionDays: defaultQuarantineRetention}
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("无法读取隔离区清单: %w", err)
+	default:
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("隔离区清单格式错误: %w", err)
+		}
+		if m.Version > quarantineVersion {
+			return nil, fmt.Errorf("不支持的隔离区清单版本: %d", m.Version)
+		}
+	}
+	reconcileQuarantine(dir, m)
+	return m, nil
+}
+
+// reconcileQuarantine 去掉文件已不在隔离区的条目，补回已隔离但不在清单中的条目，
+// 条目已保存但文件还没有移入时（移动前退出或移动失败）删除条目
+func reconcileQuarantine(dir string, m *quarantineManifest) {
+	known := make(map[string]bool, len(m.Entries))
+	kept := m.Entries[:0]
+	for _, entry := range m.Entries {
+		if _, err := os.Lstat(quarantineItemPath(dir, entry)); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		known[entry.ID] = true
+		kept = append(kept, entry)
+	}
+	m.Entries = kept
+
+	names, err := filepath.Glob(filepath.Join(dir, "items", "*.json"))
+	if err != nil {
+		return
+	}
+	var recovered []QuarantineEntry
+	for _, name := range names {
+		id := strings.TrimSuffix(filepath.Base(name), ".json")
+		if known[id] {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var entry QuarantineEntry
+		if json.Unmarshal(data, &entry) != nil || entry.ID != id {
+			continue
+		}
+		if _, err := os.Lstat(quarantineItemPath(dir, entry)); err != nil {
+			removeQuarantineItem(dir, id)
+			continue
+		}
+		recovered = append(recovered, entry)
+	}
+	sort.Slice(recovered, func(i, j int) bool { return recovered[i].QuarantinedAt.Before(recovered[j].QuarantinedAt) })
+	m.Entries = append(m.Entries, recovered...)
+}
+
+// writeQuarantine 写入隔离区清单
+func writeQuarantine(dir string, m *quarantineManifest) error {
+	m.Version = quarantineVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "manifest.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("无法保存隔离区清单: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法保存隔离区清单: %w", err)
+	}
+	return nil
+}
+
+// QuarantineFiles 把文件移到隔离区，reason 为隔离原因，无效文件会附上无效原因。
+// 压缩包内和远程存储中的文件不能隔离。保存清单失败时仍然返回处理结果（已隔离的文件可以从条目恢复）和错误
+func QuarantineFiles(files []FileInfo, reason string) (*FileActionResult, error) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	dir, err := quarantineDir()
+	if err != nil {
+		return nil, err
+	}
+	m, err := readQuarantine(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FileActionResult{FailedFiles: make([]string, 0)}
+	now := time.Now()
+	batch := now.Format("20060102-150405")
+	for i, file := range files {
+		src, err := filepath.Abs(file.Path)
+		if err != nil || !isLocalPath(file.Path) {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			continue
+		}
+		entry := QuarantineEntry{
+			ID:            batch + "-" + strconv.Itoa(i+1),
+			OriginalPath:  src,
+			Name:          file.Name,
+			Size:          file.Size,
+			ModTime:       file.ModTime,
+			Hash:          file.Hash,
+			Reason:        reason,
+			QuarantinedAt: now,
+		}
+		if file.InvalidReason != "" {
+			if entry.Reason != "" {
+				entry.Reason += ": "
+			}
+			entry.Reason += file.InvalidReason
+		}
+		// 同一秒内多次隔离时避开已有的 ID
+		for n := 2; ; n++ {
+			if _, err := os.Stat(filepath.Join(dir, "items", entry.ID)); errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			entry.ID = batch + "-" + strconv.Itoa(i+1) + "-" + strconv.Itoa(n)
+		}
+
+		// 先保存条目再移动，中途退出时已移入的文件不会丢失记录
+		if err := os.MkdirAll(filepath.Join(dir, "items", entry.ID), 0700); err != nil {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			continue
+		}
+		if err := writeQuarantineEntry(dir, entry); err != nil {
+			removeQuarantineItem(dir, entry.ID)
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			continue
+		}
+		if err := moveFile(src, quarantineItemPath(dir, entry)); err != nil {
+			removeQuarantineItem(dir, entry.ID)
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			continue
+		}
+		m.Entries = append(m.Entries, entry)
+		result.Success++
+	}
+
+	if result.Success > 0 {
+		if err := writeQuarantine(dir, m); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// ListQuarantine 列出隔离区中的文件，最近隔离的在前
+func ListQuarantine() ([]QuarantineEntry, error) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	dir, err := quarantineDir()
+	if err != nil {
+		return nil, err
+	}
+	m, err := readQuarantine(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]QuarantineEntry, 0, len(m.Entries))
+	for i := len(m.Entries) - 1; i >= 0; i-- {
+		entries = append(entries, m.Entries[i])
+	}
+	return entries, nil
+}
+
+// RestoreQuarantined 把隔离的文件恢复到原路径，原路径已有文件时不恢复
+func RestoreQuarantined(ids []string) (*FileActionResult, error) {
+	return updateQuarantine(ids, func(dir string, entry QuarantineEntry) error {
+		if _, err := os.Lstat(entry.OriginalPath); err == nil {
+			return fmt.Errorf("原位置已有文件: %s", entry.OriginalPath)
+		}
+		if err := moveFile(quarantineItemPath(dir, entry), entry.OriginalPath); err != nil {
+			return err
+		}
+		return removeQuarantineItem(dir, entry.ID)
+	})
+}
+
+// PurgeQuarantined 永久删除隔离的文件
+func PurgeQuarantined(ids []string) (*FileActionResult, error) {
+	return updateQuarantine(ids, func(dir string, entry QuarantineEntry) error {
+		return removeQuarantineItem(dir, entry.ID)
+	})
+}
+
+// updateQuarantine 对指定的隔离文件执行 action，成功的从清单中移除。
+// 保存清单失败时仍然返回处理结果和错误，下次读取清单时会去掉已处理的条目
+func updateQuarantine(ids []string, action func(dir string, entry QuarantineEntry) error) (*FileActionResult, error) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	dir, err := quarantineDir()
+	if err != nil {
+		return nil, err
+	}
+	m, err := readQuarantine(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	result := &FileActionResult{FailedFiles: make([]string, 0)}
+	kept := m.Entries[:0]
+	for _, entry := range m.Entries {
+		if !selected[entry.ID] {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := action(dir, entry); err != nil {
+			kept = append(kept, entry)
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, entry.OriginalPath)
+			continue
+		}
+		result.Success++
+	}
+	m.Entries = kept
+
+	if result.Success > 0 {
+		if err := writeQuarantine(dir, m); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// PurgeExpiredQuarantine 永久删除超过保留天数的隔离文件，返回删除的文件数
+func PurgeExpiredQuarantine() (int, error) {
+	days, err := GetQuarantineRetention()
+	if err != nil || days <= 0 {
+		return 0, err
+	}
+	entries, err := ListQuarantine()
+	if err != nil {
+		return 0, err
+	}
+	deadline := time.Now().AddDate(0, 0, -days)
+	var expired []string
+	for _, entry := range entries {
+		if entry.QuarantinedAt.Before(deadline) {
+			expired = append(expired, entry.ID)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	result, err := PurgeQuarantined(expired)
+	if err != nil {
+		return 0, err
+	}
+	return result.Success, nil
+}
+
+// GetQuarantineRetention 获取隔离文件的保留天数，0 表示不自动删除
+func GetQuarantineRetention() (int, error) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	dir, err := quarantineDir()
+	if err != nil {
+		return 0, err
+	}
+	m, err := readQuarantine(dir)
+	if err != nil {
+		return 0, err
+	}
+	return m.RetentionDays, nil
+}
+
+// SetQuarantineRetention 设置隔离文件的保留天数，0 表示不自动删除
+func SetQuarantineRetention(days int) error {
+	if days < 0 {
+		return errors.New("保留天数不能为负数")
+	}
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	dir, err := quarantineDir()
+	if err != nil {
+		return err
+	}
+	m, err := readQuarantine(dir)
+	if err != nil {
+		return err
+	}
+	m.RetentionDays = days
+	return writeQuarantine(dir, m)
+}
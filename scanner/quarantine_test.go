@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuarantineAndRestore(t *testing.T) {
+	src, _, files := moveFixture(t, "a.pdf", "b.docx")
+	files[1].InvalidReason = "文件头不匹配"
+
+	result, err := QuarantineFiles(files, "无效文件")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 2 || result.Failed != 0 {
+		t.Fatalf("QuarantineFiles = %+v", result)
+	}
+	assertMissing(t, filepath.Join(src, "a.pdf"))
+
+	entries, err := ListQuarantine()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ListQuarantine = %+v, %v", entries, err)
+	}
+	if entries[0].Reason != "无效文件: 文件头不匹配" {
+		t.Errorf("reason = %q", entries[0].Reason)
+	}
+
+	restored, err := RestoreQuarantined([]string{entries[0].ID, entries[1].ID})
+	if err != nil || restored.Success != 2 {
+		t.Fatalf("RestoreQuarantined = %+v, %v", restored, err)
+	}
+	assertContent(t, filepath.Join(src, "a.pdf"), "content of a.pdf")
+	assertContent(t, filepath.Join(src, "b.docx"), "content of b.docx")
+
+	dir, _ := quarantineDir()
+	left, _ := os.ReadDir(filepath.Join(dir, "items"))
+	if len(left) != 0 {
+		t.Errorf("items left after restore: %d", len(left))
+	}
+}
+
+func TestQuarantineRecoversUnlistedItems(t *testing.T) {
+	src, _, files := moveFixture(t, "a.pdf", "b.docx")
+	dir, err := quarantineDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 清单无法写入：文件已经移走，结果仍然返回
+	if err := os.MkdirAll(filepath.Join(dir, "manifest.json.tmp", "x"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	result, err := QuarantineFiles(files[:1], "")
+	if err == nil || result == nil || result.Success != 1 {
+		t.Fatalf("QuarantineFiles with a broken manifest = %+v, %v; want the partial result and an error", result, err)
+	}
+	assertMissing(t, filepath.Join(src, "a.pdf"))
+	os.RemoveAll(filepath.Join(dir, "manifest.json.tmp"))
+
+	// 模拟隔离过程中退出：条目已保存但文件还没移入
+	orphan := QuarantineEntry{ID: "20240101-000000-9", OriginalPath: files[1].Path, Name: "b.docx"}
+	os.MkdirAll(filepath.Join(dir, "items", orphan.ID), 0700)
+	if err := writeQuarantineEntry(dir, orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ListQuarantine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].OriginalPath != files[0].Path {
+		t.Fatalf("ListQuarantine = %+v, want the recovered a.pdf only", entries)
+	}
+	assertMissing(t, quarantineEntryPath(dir, orphan.ID))
+	assertContent(t, filepath.Join(src, "b.docx"), "content of b.docx")
+
+	if restored, err := RestoreQuarantined([]string{entries[0].ID}); err != nil || restored.Success != 1 {
+		t.Fatalf("RestoreQuarantined = %+v, %v", restored, err)
+	}
+	assertContent(t, filepath.Join(src, "a.pdf"), "content of a.pdf")
+}
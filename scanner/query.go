@@ -0,0 +1,674 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 查询语言示例：
+//
+//	type:pdf size>10MB modified:2024-01..2024-06 path:"/合同/" -invalid name~"报价.*v\d"
+//
+// 空格分隔的条件同时满足，OR 连接的条件满足其一，括号分组，- 或 NOT 取反。
+// 条件形如 字段:值、字段>值、字段~正则；不带字段的词按文件名包含匹配。
+
+// 查询字段（在规则条件字段之外）
+const (
+	queryFieldLabel    = "label"
+	queryFieldRoot     = "root"
+	queryFieldModified = "modified"
+)
+
+// 查询字段别名
+var queryFieldAliases = map[string]string{
+	"modified": queryFieldModified,
+	"mtime":    queryFieldModified,
+	"modtime":  queryFieldModified,
+	"date":     queryFieldModified,
+	"tag":      queryFieldLabel,
+	"labels":   queryFieldLabel,
+	"findings": FieldFinding,
+}
+
+// 不带值的标志条件
+var queryFlags = map[string]func(f *FileInfo) bool{
+	"valid":   func(f *FileInfo) bool { return f.IsValid },
+	"invalid": func(f *FileInfo) bool { return !f.IsValid },
+	"archived": func(f *FileInfo) bool {
+		return IsArchivePath(f.Path)
+	},
+	"sensitive": func(f *FileInfo) bool { return len(f.Findings) > 0 },
+	"labeled":   func(f *FileInfo) bool { return len(f.Labels) > 0 },
+	"encrypted": func(f *FileInfo) bool { return f.Encrypted },
+}
+
+// QueryError 查询语法错误，Pos 为出错位置（从 1 开始的字符序号）
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("查询语法错误（第 %d 个字符）: %s", e.Pos, e.Msg)
+}
+
+// Query 解析后的查询
+type Query struct {
+	text string
+	root queryNode
+}
+
+// queryNode 查询表达式节点
+type queryNode interface {
+	match(f *FileInfo) bool
+}
+
+type (
+	andNode   []queryNode
+	orNode    []queryNode
+	notNode   struct{ node queryNode }
+	matchNode func(f *FileInfo) bool
+)
+
+func (n andNode) match(f *FileInfo) bool {
+	for _, c := range n {
+		if !c.match(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) match(f *FileInfo) bool {
+	for _, c := range n {
+		if c.match(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n notNode) match(f *FileInfo) bool   { return !n.node.match(f) }
+func (n matchNode) match(f *FileInfo) bool { return n(f) }
+
+// ParseQuery 解析查询，空查询匹配所有文件
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, end: len([]rune(text)) + 1}
+	q := &Query{text: text}
+	if len(tokens) == 0 {
+		return q, nil
+	}
+	q.root, err = p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil {
+		return nil, &QueryError{Pos: t.pos, Msg: "多余的 " + t.text}
+	}
+	return q, nil
+}
+
+// String 返回查询原文
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.text
+}
+
+// Match 判断文件是否符合查询
+func (q *Query) Match(f *FileInfo) bool {
+	if q == nil || q.root == nil {
+		return true
+	}
+	return q.root.match(f)
+}
+
+// 词法单元类型
+const (
+	tokTerm = iota
+	tokLParen
+	tokRParen
+	tokOr
+	tokNot
+)
+
+// queryToken 词法单元
+type queryToken struct {
+	kind   int
+	pos    int    // 从 1 开始的字符序号
+	text   string // 原文
+	negate bool   // 条件前带 -
+	field  string // 字段名，为空表示不带字段的词
+	op     string // : = > >= < <= ~
+	value  string // 去掉引号后的值
+	valPos int    // 值的位置
+}
+
+// lexQuery 把查询切分为词法单元
+func lexQuery(text string) ([]queryToken, error) {
+	runes := []rune(text)
+	var tokens []queryToken
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, pos: i + 1, text: "("})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, pos: i + 1, text: ")"})
+			i++
+			continue
+		case r == '|':
+			tokens = append(tokens, queryToken{kind: tokOr, pos: i + 1, text: "|"})
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '(':
+			// -( ... ) 对整组取反，与 NOT 相同
+			tokens = append(tokens, queryToken{kind: tokNot, pos: i + 1, text: "-"})
+			i++
+			continue
+		}
+
+		tok := queryToken{kind: tokTerm, pos: i + 1}
+		start := i
+		if r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			tok.negate = true
+			i++
+		}
+
+		// 字段名和运算符
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) && runes[j] < unicode.MaxASCII) {
+			j++
+		}
+		if j > i && j < len(runes) {
+			if op := queryOperator(runes[j:]); op != "" {
+				tok.field = strings.ToLower(string(runes[i:j]))
+				tok.op = op
+				i = j + len(op)
+			}
+		}
+
+		// 值：带引号或直到空白、括号
+		tok.valPos = i + 1
+		if i < len(runes) && runes[i] == '"' {
+			value, next, ok := readQuoted(runes, i)
+			if !ok {
+				return nil, &QueryError{Pos: i + 1, Msg: "引号未闭合"}
+			}
+			tok.value = value
+			i = next
+		} else {
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '(' && runes[j] != ')' {
+				j++
+			}
+			tok.value = string(runes[i:j])
+			i = j
+		}
+		tok.text = string(runes[start:i])
+
+		if tok.field == "" && !tok.negate {
+			switch tok.text {
+			case "OR":
+				tok.kind = tokOr
+			case "NOT":
+				tok.kind = tokNot
+			case "AND":
+				continue
+			}
+		}
+		if tok.kind == tokTerm && tok.field != "" && tok.value == "" {
+			return nil, &QueryError{Pos: tok.valPos, Msg: "字段 " + tok.field + " 缺少值"}
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+// queryOperator 识别字段名之后的运算符
+func queryOperator(rest []rune) string {
+	if len(rest) >= 2 && (rest[0] == '>' || rest[0] == '<') && rest[1] == '=' {
+		return string(rest[:2])
+	}
+	switch rest[0] {
+	case ':', '=', '>', '<', '~':
+		return string(rest[:1])
+	}
+	return ""
+}
+
+// readQuoted 读取双引号中的值，\" 表示引号，\\ 表示反斜杠，其他反斜杠原样保留（便于书写正则）
+func readQuoted(runes []rune, start int) (string, int, bool) {
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return b.String(), i + 1, true
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+			}
+		}
+		b.WriteRune(runes[i])
+	}
+	return "", 0, false
+}
+
+// queryParser 递归下降解析：or := and (OR and)*；and := unary+；unary := (NOT|-)? primary
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	end    int
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	var nodes orNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		t := p.peek()
+		if t == nil || t.kind != tokOr {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var nodes andNode
+	for {
+		t := p.peek()
+		if t == nil || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		pos := p.end
+		if t := p.peek(); t != nil {
+			pos = t.pos
+		}
+		return nil, &QueryError{Pos: pos, Msg: "缺少条件"}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	t := p.peek()
+	if t.kind == tokNot {
+		p.pos++
+		if next := p.peek(); next == nil || next.kind == tokOr || next.kind == tokRParen {
+			return nil, &QueryError{Pos: t.pos, Msg: "NOT 之后缺少条件"}
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+
+	if t.kind == tokLParen {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.peek()
+		if closing == nil || closing.kind != tokRParen {
+			return nil, &QueryError{Pos: t.pos, Msg: "括号未闭合"}
+		}
+		p.pos++
+		return node, nil
+	}
+
+	p.pos++
+	node, err := compileQueryTerm(t)
+	if err != nil {
+		return nil, err
+	}
+	if t.negate {
+		return notNode{node}, nil
+	}
+	return node, nil
+}
+
+// compileQueryTerm 把单个条件编译为匹配函数
+func compileQueryTerm(t *queryToken) (queryNode, error) {
+	fail := func(format string, args ...interface{}) error {
+		return &QueryError{Pos: t.valPos, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	// 不带字段的词：标志或文件名包含
+	if t.field == "" {
+		if flag, ok := queryFlags[strings.ToLower(t.value)]; ok && !strings.HasPrefix(t.text, "\"") && !strings.HasPrefix(t.text, "-\"") {
+			return matchNode(flag), nil
+		}
+		return textMatcher(t.value, ":", func(f *FileInfo) []string { return []string{f.Name} }, fail)
+	}
+
+	field := t.field
+	if alias, ok := queryFieldAliases[field]; ok {
+		field = alias
+	}
+
+	switch field {
+	case FieldSize:
+		return sizeMatcher(t, fail)
+	case queryFieldModified:
+		return dateMatcher(t, fail)
+	case FieldPages:
+		return pagesMatcher(t, fail)
+	case FieldValid:
+		valid, err := strconv.ParseBool(t.value)
+		if err != nil || t.op != ":" && t.op != "=" {
+			return nil, fail("valid 只能为 true 或 false")
+		}
+		return matchNode(func(f *FileInfo) bool { return f.IsValid == valid }), nil
+	case FieldType:
+		if t.op != ":" && t.op != "=" {
+			return nil, fail("type 只支持 : 运算符")
+		}
+		types := strings.Split(strings.ToLower(t.value), ",")
+		return matchNode(func(f *FileInfo) bool {
+			ext := strings.TrimPrefix(strings.ToLower(f.Extension), ".")
+			for _, want := range types {
+				want = strings.TrimPrefix(want, ".")
+				if want == f.FileType || want == ext {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case FieldExt:
+		if t.op != ":" && t.op != "=" {
+			return nil, fail("ext 只支持 : 运算符")
+		}
+		exts := strings.Split(strings.ToLower(t.value), ",")
+		return matchNode(func(f *FileInfo) bool {
+			ext := strings.TrimPrefix(strings.ToLower(f.Extension), ".")
+			for _, want := range exts {
+				if strings.TrimPrefix(want, ".") == ext {
+					return true
+				}
+			}
+			return false
+		}), nil
+	}
+
+	var values func(f *FileInfo) []string
+	switch field {
+	case FieldName:
+		values = func(f *FileInfo) []string { return []string{f.Name} }
+	case FieldPath:
+		values = func(f *FileInfo) []string { return []string{strings.ReplaceAll(f.Path, "\\", "/")} }
+	case queryFieldRoot:
+		values = func(f *FileInfo) []string { return []string{strings.ReplaceAll(f.Root, "\\", "/")} }
+	case queryFieldLabel:
+		values = func(f *FileInfo) []string { return f.Labels }
+	case FieldFinding:
+		values = func(f *FileInfo) []string {
+			types := make([]string, len(f.Findings))
+			for i, finding := range f.Findings {
+				types[i] = finding.Type
+			}
+			return types
+		}
+	case FieldAuthor:
+		values = func(f *FileInfo) []string {
+			if f.Metadata == nil {
+				return nil
+			}
+			return []string{f.Metadata.Author}
+		}
+	case FieldTitle:
+		values = func(f *FileInfo) []string {
+			if f.Metadata == nil {
+				return nil
+			}
+			return []string{f.Metadata.Title}
+		}
+	default:
+		return nil, &QueryError{Pos: t.pos, Msg: fmt.Sprintf("未知的字段 %s，可用字段: type ext name path root size modified pages author title label finding valid", t.field)}
+	}
+	if field == queryFieldLabel || field == FieldFinding {
+		// 标签和敏感信息类型默认按完整名称匹配
+		if t.op == ":" {
+			t.op = "="
+		}
+	}
+	return textMatcher(t.value, t.op, values, fail)
+}
+
+// textMatcher 文本条件：: 包含，= 等于（忽略大小写和全半角），~ 正则（忽略大小写）；任一值满足即可
+func textMatcher(value, op string, values func(f *FileInfo) []string, fail func(string, ...interface{}) error) (queryNode, error) {
+	switch op {
+	case ":":
+		want := FoldText(value)
+		return matchNode(func(f *FileInfo) bool {
+			for _, v := range values(f) {
+				if strings.Contains(FoldText(v), want) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "=":
+		want := FoldText(value)
+		return matchNode(func(f *FileInfo) bool {
+			for _, v := range values(f) {
+				if FoldText(v) == want {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "~":
+		if _, err := regexp.Compile(value); err != nil {
+			return nil, fail("无效的正则表达式: %v", err)
+		}
+		re := regexp.MustCompile("(?i)" + value)
+		return matchNode(func(f *FileInfo) bool {
+			for _, v := range values(f) {
+				if re.MatchString(v) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	}
+	return nil, fail("文本字段不支持 %s 运算符", op)
+}
+
+// numberRange 闭区间 [min, max]，hasMin/hasMax 为 false 时不限
+type numberRange struct {
+	min, max       int64
+	hasMin, hasMax bool
+}
+
+func (r numberRange) contains(v int64) bool {
+	return (!r.hasMin || v >= r.min) && (!r.hasMax || v <= r.max)
+}
+
+// parseNumberRange 解析比较或区间（a..b、a..、..b），parse 解析单个值
+func parseNumberRange(op, value string, parse func(string) (int64, error)) (numberRange, error) {
+	var r numberRange
+	if op == ":" || op == "=" {
+		if lo, hi, ok := strings.Cut(value, ".."); ok {
+			if lo == "" && hi == "" {
+				return r, fmt.Errorf("区间两端不能都为空")
+			}
+			if lo != "" {
+				n, err := parse(lo)
+				if err != nil {
+					return r, err
+				}
+				r.min, r.hasMin = n, true
+			}
+			if hi != "" {
+				n, err := parse(hi)
+				if err != nil {
+					return r, err
+				}
+				r.max, r.hasMax = n, true
+			}
+			return r, nil
+		}
+	}
+
+	n, err := parse(value)
+	if err != nil {
+		return r, err
+	}
+	switch op {
+	case ":", "=":
+		r = numberRange{min: n, max: n, hasMin: true, hasMax: true}
+	case ">":
+		r.min, r.hasMin = n+1, true
+	case ">=":
+		r.min, r.hasMin = n, true
+	case "<":
+		r.max, r.hasMax = n-1, true
+	case "<=":
+		r.max, r.hasMax = n, true
+	default:
+		return r, fmt.Errorf("不支持 %s 运算符", op)
+	}
+	return r, nil
+}
+
+// sizeMatcher 文件大小条件，如 size>10MB、size:1MB..5MB
+func sizeMatcher(t *queryToken, fail func(string, ...interface{}) error) (queryNode, error) {
+	r, err := parseNumberRange(t.op, t.value, ParseSize)
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+	return matchNode(func(f *FileInfo) bool { return r.contains(f.Size) }), nil
+}
+
+// pagesMatcher 页数条件（需要已提取元数据）
+func pagesMatcher(t *queryToken, fail func(string, ...interface{}) error) (queryNode, error) {
+	r, err := parseNumberRange(t.op, t.value, func(s string) (int64, error) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的页数: %s", s)
+		}
+		return n, nil
+	})
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+	return matchNode(func(f *FileInfo) bool {
+		return f.Metadata != nil && r.contains(int64(f.Metadata.Pages))
+	}), nil
+}
+
+// dateMatcher 修改时间条件，日期可以是 2024、2024-06 或 2024-06-15，表示整个年、月或日；
+// modified:2024-01..2024-06 表示 2024 年 1 月初到 6 月底
+func dateMatcher(t *queryToken, fail func(string, ...interface{}) error) (queryNode, error) {
+	var start, end time.Time // 时间段 [start, end)
+	var hasStart, hasEnd bool
+
+	lo, hi, isRange := strings.Cut(t.value, "..")
+	if isRange && t.op != ":" && t.op != "=" {
+		return nil, fail("日期区间只支持 : 运算符")
+	}
+	if !isRange {
+		from, to, err := parseQueryDate(t.value)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		switch t.op {
+		case ":", "=":
+			start, end, hasStart, hasEnd = from, to, true, true
+		case ">":
+			start, hasStart = to, true
+		case ">=":
+			start, hasStart = from, true
+		case "<":
+			end, hasEnd = from, true
+		case "<=":
+			end, hasEnd = to, true
+		default:
+			return nil, fail("日期字段不支持 %s 运算符", t.op)
+		}
+	} else {
+		if lo == "" && hi == "" {
+			return nil, fail("区间两端不能都为空")
+		}
+		if lo != "" {
+			from, _, err := parseQueryDate(lo)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			start, hasStart = from, true
+		}
+		if hi != "" {
+			_, to, err := parseQueryDate(hi)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			end, hasEnd = to, true
+		}
+	}
+
+	return matchNode(func(f *FileInfo) bool {
+		return (!hasStart || !f.ModTime.Before(start)) && (!hasEnd || f.ModTime.Before(end))
+	}), nil
+}
+
+// parseQueryDate 解析日期并返回它表示的时间段 [from, to)
+func parseQueryDate(s string) (time.Time, time.Time, error) {
+	layouts := []struct {
+		layout string
+		next   func(time.Time) time.Time
+	}{
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+	}
+	for _, l := range layouts {
+		if len(s) != len(l.layout) {
+			continue
+		}
+		if t, err := time.ParseInLocation(l.layout, s, time.Local); err == nil {
+			return t, l.next(t), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("无效的日期 %s，应为 2024、2024-06 或 2024-06-15", s)
+}
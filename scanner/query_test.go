@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.Local)
+	}
+	files := []FileInfo{
+		{Path: "/data/合同/采购合同 v2.pdf", Name: "采购合同 v2.pdf", Extension: ".pdf", FileType: "pdf", Size: 12 << 20, ModTime: date(2024, 3, 15), IsValid: true, Root: "/data",
+			Labels: []string{"合同"}, Metadata: &DocMetadata{Author: "张三", Title: "采购合同", Pages: 12}},
+		{Path: "/data/报价/报价单v3.xlsx", Name: "报价单v3.xlsx", Extension: ".xlsx", FileType: "excel", Size: 200 << 10, ModTime: date(2024, 7, 1), IsValid: true, Root: "/data",
+			Findings: []Finding{{Type: FindingMobile, Count: 1}}},
+		{Path: "/backup/旧文档.doc", Name: "旧文档.doc", Extension: ".doc", FileType: "word", Size: 5 << 20, ModTime: date(2023, 12, 31), IsValid: false, Root: "/backup", Encrypted: true},
+		{Path: "/data/bundle.zip" + ArchiveSeparator + "Report.pptx", Name: "Report.pptx", Extension: ".pptx", FileType: "ppt", Size: 1 << 20, ModTime: date(2024, 6, 30), IsValid: true, Root: "/data"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string // 匹配的文件名
+	}{
+		{"", []string{"采购合同 v2.pdf", "报价单v3.xlsx", "旧文档.doc", "Report.pptx"}},
+		{"合同", []string{"采购合同 v2.pdf"}},
+		{"report", []string{"Report.pptx"}},
+		{"type:pdf,word", []string{"采购合同 v2.pdf", "旧文档.doc"}},
+		{"ext:.xlsx", []string{"报价单v3.xlsx"}},
+		{"size>10MB", []string{"采购合同 v2.pdf"}},
+		{"size:1MB..5MB", []string{"旧文档.doc", "Report.pptx"}},
+		{"size<=1M", []string{"报价单v3.xlsx", "Report.pptx"}},
+		{"modified:2024-01..2024-06", []string{"采购合同 v2.pdf", "Report.pptx"}},
+		{"modified:2024-07-01", []string{"报价单v3.xlsx"}},
+		{"date<2024", []string{"旧文档.doc"}},
+		{"modified>2024-06", []string{"报价单v3.xlsx"}},
+		{`path:"/合同/"`, []string{"采购合同 v2.pdf"}},
+		{"root=/backup", []string{"旧文档.doc"}},
+		{`name~"报价.*v\d"`, []string{"报价单v3.xlsx"}},
+		{"author:张 pages>=10", []string{"采购合同 v2.pdf"}},
+		{"title=采购合同", []string{"采购合同 v2.pdf"}},
+		{"label:合同", []string{"采购合同 v2.pdf"}},
+		{"tag:合", nil}, // 标签按完整名称匹配
+		{"finding:mobile", []string{"报价单v3.xlsx"}},
+		{"sensitive", []string{"报价单v3.xlsx"}},
+		{"invalid", []string{"旧文档.doc"}},
+		{`"invalid"`, nil}, // 带引号时按文件名匹配
+		{"valid:false", []string{"旧文档.doc"}},
+		{"encrypted", []string{"旧文档.doc"}},
+		{"archived", []string{"Report.pptx"}},
+		{"-invalid type:pdf,ppt", []string{"采购合同 v2.pdf", "Report.pptx"}},
+		{"NOT archived valid", []string{"采购合同 v2.pdf", "报价单v3.xlsx"}},
+		{"type:pdf OR type:excel", []string{"采购合同 v2.pdf", "报价单v3.xlsx"}},
+		{"type:pdf | invalid", []string{"采购合同 v2.pdf", "旧文档.doc"}},
+		{"valid AND (type:excel OR size>10MB)", []string{"采购合同 v2.pdf", "报价单v3.xlsx"}},
+		{"-(type:pdf OR type:word)", []string{"报价单v3.xlsx", "Report.pptx"}},
+		{`"合同 v2"`, []string{"采购合同 v2.pdf"}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for i := range files {
+			if q.Match(&files[i]) {
+				got = append(got, files[i].Name)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.query, got, tt.want)
+		}
+		if q.String() != tt.query {
+			t.Errorf("String() = %q, want %q", q.String(), tt.query)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{`name:"合同`, 6},
+		{"size:", 6},
+		{"size>big", 6},
+		{"modified:2024-13", 10},
+		{"modified:..", 10},
+		{"modified~2024", 10},
+		{"valid:maybe", 7},
+		{"type>pdf", 6},
+		{"owner:张三", 1},
+		{"name~[", 6},
+		{"(type:pdf", 1},
+		{"type:pdf)", 9},
+		{"type:pdf OR", 12},
+		{"OR type:pdf", 1},
+		{"NOT", 1},
+		{"()", 2},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		var qerr *QueryError
+		if !errors.As(err, &qerr) {
+			t.Errorf("ParseQuery(%q) = %v, want a QueryError", tt.query, err)
+			continue
+		}
+		if qerr.Pos != tt.pos {
+			t.Errorf("ParseQuery(%q) error at %d (%s), want %d", tt.query, qerr.Pos, qerr.Msg, tt.pos)
+		}
+	}
+}
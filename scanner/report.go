@@ -0,0 +1,508 @@
+package scanner
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 报告格式
+const (
+	ReportCSV  = "csv"
+	ReportXLSX = "xlsx"
+	ReportJSON = "json"
+	ReportHTML = "html"
+)
+
+// ReportColumn 报告中可以选择的列
+type ReportColumn struct {
+	Key     string `json:"key"`
+	Title   string `json:"title"`
+	Default bool   `json:"default"` // 未指定列时是否包含
+}
+
+// ReportOptions 报告选项
+type ReportOptions struct {
+	Format  string   `json:"format"`  // csv、xlsx、json、html，为空时根据文件扩展名选择
+	Columns []string `json:"columns"` // 包含的列（ReportColumn.Key），按顺序输出，为空时使用默认列
+	Title   string   `json:"title"`   // 报告标题
+	Filter  string   `json:"filter"`  // 生成报告时的过滤条件说明，写在报告开头
+}
+
+// ReportCount 报告统计中的一项
+type ReportCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// ReportStats 报告中的统计
+type ReportStats struct {
+	Total          int           `json:"total"`
+	Valid          int           `json:"valid"`
+	Invalid        int           `json:"invalid"`
+	Encrypted      int           `json:"encrypted"`
+	TotalSize      int64         `json:"totalSize"`
+	ByType         []ReportCount `json:"byType"`         // 各文件类型的文件数和大小
+	InvalidReasons []ReportCount `json:"invalidReasons"` // 各无效原因的文件数
+	Findings       []ReportCount `json:"findings"`       // 包含各类敏感信息的文件数
+	Labels         []ReportCount `json:"labels"`         // 各标签的文件数
+}
+
+// reportColumn 列定义：value 返回 string、int64、int、bool 或 time.Time，
+// JSON 和 XLSX 中保留类型，CSV 和 HTML 中格式化为文本
+type reportColumn struct {
+	ReportColumn
+	value func(f *FileInfo) any
+}
+
+// 可选的列，顺序即界面中的默认顺序
+var reportColumns = []reportColumn{
+	{ReportColumn{"name", "文件名", true}, func(f *FileInfo) any { return f.Name }},
+	{ReportColumn{"path", "路径", true}, func(f *FileInfo) any { return f.Path }},
+	{ReportColumn{"type", "类型", true}, func(f *FileInfo) any { return f.FileType }},
+	{ReportColumn{"extension", "扩展名", false}, func(f *FileInfo) any { return f.Extension }},
+	{ReportColumn{"size", "大小（字节）", true}, func(f *FileInfo) any { return f.Size }},
+	{ReportColumn{"modTime", "修改时间", true}, func(f *FileInfo) any { return f.ModTime }},
+	{ReportColumn{"valid", "有效", true}, func(f *FileInfo) any { return f.IsValid }},
+	{ReportColumn{"invalidReason", "无效原因", true}, func(f *FileInfo) any { return f.InvalidReason }},
+	{ReportColumn{"encrypted", "加密", false}, func(f *FileInfo) any { return f.Encrypted }},
+	{ReportColumn{"findings", "敏感信息", false}, func(f *FileInfo) any { return formatFindings(f.Findings) }},
+	{ReportColumn{"labels", "标签", false}, func(f *FileInfo) any { return strings.Join(f.Labels, "、") }},
+	{ReportColumn{"hash", "SHA-256", false}, func(f *FileInfo) any { return f.Hash }},
+	{ReportColumn{"root", "扫描路径", false}, func(f *FileInfo) any { return f.Root }},
+	{ReportColumn{"title", "标题", false}, func(f *FileInfo) any { return metadataField(f, func(m *DocMetadata) any { return m.Title }) }},
+	{ReportColumn{"author", "作者", false}, func(f *FileInfo) any { return metadataField(f, func(m *DocMetadata) any { return m.Author }) }},
+	{ReportColumn{"pages", "页数", false}, func(f *FileInfo) any { return metadataField(f, func(m *DocMetadata) any { return m.Pages }) }},
+}
+
+// 敏感信息类型的名称，自定义规则使用规则名称
+var findingNames = map[string]string{
+	FindingIDCard:   "身份证",
+	FindingMobile:   "手机号",
+	FindingBankCard: "银行卡",
+	FindingEmail:    "邮箱",
+}
+
+// ReportColumns 返回可以选择的报告列
+func ReportColumns() []ReportColumn {
+	columns := make([]ReportColumn, len(reportColumns))
+	for i, c := range reportColumns {
+		columns[i] = c.ReportColumn
+	}
+	return columns
+}
+
+// resolveColumns 按键查找列，keys 为空时返回默认列
+func resolveColumns(keys []string) ([]reportColumn, error) {
+	var columns []reportColumn
+	if len(keys) == 0 {
+		for _, c := range reportColumns {
+			if c.Default {
+				columns = append(columns, c)
+			}
+		}
+		return columns, nil
+	}
+	for _, key := range keys {
+		found := false
+		for _, c := range reportColumns {
+			if c.Key == key {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("未知的报告列: %s", key)
+		}
+	}
+	return columns, nil
+}
+
+// findingName 返回敏感信息类型的名称
+func findingName(t string) string {
+	if name, ok := findingNames[t]; ok {
+		return name
+	}
+	return strings.TrimPrefix(t, customFindingPrefix)
+}
+
+// formatFindings 把敏感信息格式化为“身份证×3、手机号×1”
+func formatFindings(findings []Finding) string {
+	parts := make([]string, len(findings))
+	for i, finding := range findings {
+		parts[i] = findingName(finding.Type) + "×" + strconv.Itoa(finding.Count)
+	}
+	return strings.Join(parts, "、")
+}
+
+// metadataField 返回文档元数据中的字段，未提取元数据时为空
+func metadataField(f *FileInfo, field func(m *DocMetadata) any) any {
+	if f.Metadata == nil {
+		return ""
+	}
+	return field(f.Metadata)
+}
+
+// formatReportValue 把列的值格式化为文本
+func formatReportValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	case bool:
+		if v {
+			return "是"
+		}
+		return "否"
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ComputeReportStats 统计文件的有效性、类型、无效原因、敏感信息和标签
+func ComputeReportStats(files []FileInfo) ReportStats {
+	stats := ReportStats{Total: len(files)}
+	byType := make(map[string]*ReportCount)
+	reasons := make(map[string]*ReportCount)
+	findings := make(map[string]*ReportCount)
+	labels := make(map[string]*ReportCount)
+	add := func(m map[string]*ReportCount, name string, size int64) {
+		c, ok := m[name]
+		if !ok {
+			c = &ReportCount{Name: name}
+			m[name] = c
+		}
+		c.Count++
+		c.Size += size
+	}
+
+	for i := range files {
+		f := &files[i]
+		stats.TotalSize += f.Size
+		if f.IsValid {
+			stats.Valid++
+		} else {
+			stats.Invalid++
+			reason := f.InvalidReason
+			if reason == "" {
+				reason = "未知原因"
+			}
+			add(reasons, reason, f.Size)
+		}
+		if f.Encrypted {
+			stats.Encrypted++
+		}
+		add(byType, f.FileType, f.Size)
+		for _, finding := range f.Findings {
+			add(findings, findingName(finding.Type), f.Size)
+		}
+		for _, label := range f.Labels {
+			add(labels, label, f.Size)
+		}
+	}
+
+	stats.ByType = sortedCounts(byType)
+	stats.InvalidReasons = sortedCounts(reasons)
+	stats.Findings = sortedCounts(findings)
+	stats.Labels = sortedCounts(labels)
+	return stats
+}
+
+// sortedCounts 按文件数从多到少排序，文件数相同时按名称排序
+func sortedCounts(m map[string]*ReportCount) []ReportCount {
+	counts := make([]ReportCount, 0, len(m))
+	for _, c := range m {
+		counts = append(counts, *c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// statRows 把统计展开为“项目、文件数、大小”三列的表格，用于 CSV 和 XLSX
+func (s ReportStats) statRows() []statRow {
+	rows := []statRow{
+		{Section: "总计", Name: "全部文件", Count: s.Total, Size: s.TotalSize},
+		{Section: "总计", Name: "有效", Count: s.Valid},
+		{Section: "总计", Name: "无效", Count: s.Invalid},
+		{Section: "总计", Name: "加密", Count: s.Encrypted},
+	}
+	for _, section := range s.sections() {
+		for _, c := range section.Counts {
+			rows = append(rows, statRow{Section: section.Name, Name: c.Name, Count: c.Count, Size: c.Size})
+		}
+	}
+	return rows
+}
+
+// reportSection 统计中按名称分组的一部分
+type reportSection struct {
+	Name   string
+	Counts []ReportCount
+}
+
+// sections 返回分组统计
+func (s ReportStats) sections() []reportSection {
+	return []reportSection{
+		{"文件类型", s.ByType},
+		{"无效原因", s.InvalidReasons},
+		{"敏感信息", s.Findings},
+		{"标签", s.Labels},
+	}
+}
+
+// statRow 统计表格中的一行
+type statRow struct {
+	Section string
+	Name    string
+	Count   int
+	Size    int64
+}
+
+// reportTitle 返回报告标题，未设置时使用默认标题
+func reportTitle(options ReportOptions) string {
+	if strings.TrimSpace(options.Title) != "" {
+		return options.Title
+	}
+	return "DocRadar 文件清单"
+}
+
+// ExportReport 把文件列表保存为报告，Format 为空时根据扩展名选择格式（默认 CSV）
+func ExportReport(files []FileInfo, options ReportOptions, path string) error {
+	if options.Format == "" {
+		options.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("无法创建报告文件: %w", err)
+	}
+	err = WriteReport(f, files, options, time.Now())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("无法保存报告: %w", err)
+	}
+	return nil
+}
+
+// WriteReport 按格式写出报告，generated 为报告中记录的生成时间
+func WriteReport(w io.Writer, files []FileInfo, options ReportOptions, generated time.Time) error {
+	columns, err := resolveColumns(options.Columns)
+	if err != nil {
+		return err
+	}
+	stats := ComputeReportStats(files)
+	switch options.Format {
+	case ReportCSV, "":
+		return writeReportCSV(w, files, columns, stats)
+	case ReportXLSX:
+		return writeReportXLSX(w, files, columns, stats)
+	case ReportJSON:
+		return writeReportJSON(w, files, columns, stats, options, generated)
+	case ReportHTML:
+		return writeReportHTML(w, files, columns, stats, options, generated)
+	default:
+		return errors.New("不支持的报告格式: " + options.Format)
+	}
+}
+
+// writeReportCSV 写出 CSV 报告：文件列表之后空一行写统计，带 UTF-8 BOM 以便 Excel 正确识别中文
+func writeReportCSV(w io.Writer, files []FileInfo, columns []reportColumn, stats ReportStats) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = c.Title
+	}
+	cw.Write(record)
+	for i := range files {
+		for j, c := range columns {
+			record[j] = formatReportValue(c.value(&files[i]))
+		}
+		cw.Write(record)
+	}
+
+	cw.Write(nil)
+	cw.Write([]string{"统计", "项目", "文件数", "大小（字节）"})
+	for _, row := range stats.statRows() {
+		size := ""
+		if row.Size > 0 {
+			size = strconv.FormatInt(row.Size, 10)
+		}
+		cw.Write([]string{row.Section, row.Name, strconv.Itoa(row.Count), size})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeReportJSON 写出 JSON 报告，每个文件是以列键为字段的对象，值保留原始类型
+func writeReportJSON(w io.Writer, files []FileInfo, columns []reportColumn, stats ReportStats, options ReportOptions, generated time.Time) error {
+	report := struct {
+		Title     string           `json:"title"`
+		Generated time.Time        `json:"generated"`
+		Filter    string           `json:"filter,omitempty"`
+		Columns   []ReportColumn   `json:"columns"`
+		Stats     ReportStats      `json:"stats"`
+		Files     []map[string]any `json:"files"`
+	}{
+		Title:     reportTitle(options),
+		Generated: generated,
+		Filter:    options.Filter,
+		Stats:     stats,
+		Files:     make([]map[string]any, len(files)),
+	}
+	for _, c := range columns {
+		report.Columns = append(report.Columns, c.ReportColumn)
+	}
+	for i := range files {
+		row := make(map[string]any, len(columns))
+		for _, c := range columns {
+			row[c.Key] = c.value(&files[i])
+		}
+		report.Files[i] = row
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// HTML 报告模板，样式内联，不引用外部资源，可以直接用浏览器打开或打印
+var reportHTMLTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"size": formatSize,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI","Microsoft YaHei","PingFang SC",sans-serif;margin:24px;color:#303133;font-size:13px}
+h1{font-size:20px;margin:0 0 4px}
+h2{font-size:15px;margin:24px 0 8px}
+.meta{color:#909399;margin-bottom:16px}
+.cards{display:flex;gap:12px;flex-wrap:wrap}
+.card{border:1px solid #ebeef5;border-radius:6px;padding:10px 16px;min-width:110px}
+.card b{display:block;font-size:20px}
+.card.bad b{color:#f56c6c}
+table{border-collapse:collapse;width:100%}
+th,td{border:1px solid #ebeef5;padding:4px 8px;text-align:left;vertical-align:top;word-break:break-all}
+th{background:#f5f7fa;position:sticky;top:0}
+td.num{text-align:right;white-space:nowrap}
+tr.invalid td{background:#fef0f0}
+.stats{width:auto;min-width:360px}
+@media print{th{position:static}}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">生成时间：{{.Generated.Format "2006-01-02 15:04:05"}}{{if .Filter}}　过滤条件：{{.Filter}}{{end}}</div>
+<div class="cards">
+<div class="card">全部文件<b>{{.Stats.Total}}</b></div>
+<div class="card">有效<b>{{.Stats.Valid}}</b></div>
+<div class="card bad">无效<b>{{.Stats.Invalid}}</b></div>
+<div class="card">加密<b>{{.Stats.Encrypted}}</b></div>
+<div class="card">总大小<b>{{size .Stats.TotalSize}}</b></div>
+</div>
+{{range .Sections}}{{if .Counts}}
+<h2>{{.Name}}</h2>
+<table class="stats">
+<tr><th>{{.Name}}</th><th>文件数</th><th>大小</th></tr>
+{{range .Counts}}<tr><td>{{.Name}}</td><td class="num">{{.Count}}</td><td class="num">{{size .Size}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+<h2>文件列表</h2>
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr{{if .Invalid}} class="invalid"{{end}}>{{range .Cells}}<td{{if .Num}} class="num"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// htmlCell HTML 报告中的单元格
+type htmlCell struct {
+	Text string
+	Num  bool // 数字右对齐
+}
+
+// htmlRow HTML 报告中的一行
+type htmlRow struct {
+	Invalid bool
+	Cells   []htmlCell
+}
+
+// writeReportHTML 写出自包含的 HTML 报告，无效文件所在行标红
+func writeReportHTML(w io.Writer, files []FileInfo, columns []reportColumn, stats ReportStats, options ReportOptions, generated time.Time) error {
+	data := struct {
+		Title     string
+		Generated time.Time
+		Filter    string
+		Stats     ReportStats
+		Sections  []reportSection
+		Columns   []string
+		Rows      []htmlRow
+	}{
+		Title:     reportTitle(options),
+		Generated: generated,
+		Filter:    options.Filter,
+		Stats:     stats,
+		Sections:  stats.sections(),
+		Rows:      make([]htmlRow, len(files)),
+	}
+	for _, c := range columns {
+		data.Columns = append(data.Columns, c.Title)
+	}
+	for i := range files {
+		row := htmlRow{Invalid: !files[i].IsValid, Cells: make([]htmlCell, len(columns))}
+		for j, c := range columns {
+			v := c.value(&files[i])
+			_, num := v.(int64)
+			row.Cells[j] = htmlCell{Text: formatReportValue(v), Num: num}
+		}
+		data.Rows[i] = row
+	}
+	return reportHTMLTemplate.Execute(w, data)
+}
+
+// formatSize 把字节数格式化为便于阅读的大小
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// RootStats 单个扫描根路径的统计
+type RootStats struct {
+	Root         string  `json:"root"`
+	TotalCount   int     `json:"totalCount"`
+	ValidCount   int     `json:"validCount"`
+	InvalidCount int     `json:"invalidCount"`
+	ScannedDirs  int     `json:"scannedDirs"`
+	ScanTime     float64 `json:"scanTime"`        // 扫描耗时（秒）
+	Error        string  `json:"error,omitempty"` // 无法扫描时的错误信息
+}
+
+// RootProgress 单个扫描根路径的进度
+type RootProgress struct {
+	Root        string `json:"root"`
+	ScannedDirs int    `json:"scannedDirs"`
+	FoundFiles  int    `json:"foundFiles"`
+	Done        bool   `json:"done"`
+	Error       string `json:"error,omitempty"`
+}
+
+// scanRoots 合并 RootPath 和 RootPaths，去掉重复的根路径以及被其他根路径包含的子目录
+func scanRoots(options ScanOptions) []string {
+	var candidates []string
+	if options.RootPath != "" {
+		candidates = append(candidates, options.RootPath)
+	}
+	candidates = append(candidates, options.RootPaths...)
+
+	local := options.FS == nil && (options.Backend == "" || options.Backend == BackendOS)
+	var roots []string
+	for _, root := range candidates {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			continue
+		}
+		if local && urlScheme(root) == "" {
+			if abs, err := filepath.Abs(root); err == nil {
+				root = abs
+			}
+		}
+		roots = append(roots, root)
+	}
+
+	var result []string
+	for i, root := range roots {
+		covered := false
+		for j, other := range roots {
+			if i == j || !rootContains(other, root) {
+				continue
+			}
+			// 相同的根路径保留第一个
+			if !rootContains(root, other) || j < i {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, root)
+		}
+	}
+	return result
+}
+
+// rootContains 判断 child 是否等于 parent 或位于 parent 之下
+func rootContains(parent, child string) bool {
+	sep := string(filepath.Separator)
+	if urlScheme(parent) != "" || urlScheme(child) != "" {
+		sep = "/"
+	} else if runtime.GOOS == "windows" {
+		parent, child = strings.ToLower(parent), strings.ToLower(child)
+	}
+
+	parent = strings.TrimSuffix(parent, sep)
+	child = strings.TrimSuffix(child, sep)
+	return child == parent || strings.HasPrefix(child, parent+sep)
+}
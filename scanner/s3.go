@@ -0,0 +1,437 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 后端：s3:// 使用 HTTPS，s3+http:// 使用 HTTP（如内网 MinIO）
+const (
+	BackendS3     = "s3"
+	BackendS3HTTP = "s3+http"
+)
+
+// s3PartSize 上传分片大小，超过该大小的文件使用分片上传
+const s3PartSize = 16 << 20
+
+func init() {
+	RegisterBackend(BackendS3, mountS3)
+	RegisterBackend(BackendS3HTTP, mountS3)
+}
+
+// s3Location 解析后的 S3 地址
+type s3Location struct {
+	client *minio.Client
+	bucket string
+	key    string // 桶内的路径（不以 / 开头和结尾）
+	prefix string // 结果路径前缀 scheme://host/bucket/
+}
+
+// parseS3URL 解析 s3://host[:port]/bucket/prefix 并创建客户端
+// 访问密钥取自已保存的凭据（用户名为 Access Key，密码为 Secret Key），没有时使用环境变量
+func parseS3URL(rawURL string) (*s3Location, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("无效的 S3 地址: %s", rawURL)
+	}
+	parts := strings.SplitN(strings.Trim(u.Path, "/"), "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf("S3 地址缺少存储桶: %s", rawURL)
+	}
+
+	var creds *credentials.Credentials
+	if cred, ok := findCredential(u); ok && cred.Username != "" {
+		creds = credentials.NewStaticV4(cred.Username, cred.Password, "")
+	} else {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+		})
+	}
+
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:  creds,
+		Secure: !strings.EqualFold(u.Scheme, BackendS3HTTP),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("无法创建 S3 客户端: %w", err)
+	}
+
+	loc := &s3Location{
+		client: client,
+		bucket: parts[0],
+		prefix: strings.ToLower(u.Scheme) + "://" + u.Host + "/" + parts[0] + "/",
+	}
+	if len(parts) == 2 {
+		loc.key = strings.Trim(parts[1], "/")
+	}
+	return loc, nil
+}
+
+// mountS3 把存储桶中的前缀挂载为目录树
+func mountS3(root string) (*Mount, error) {
+	loc, err := parseS3URL(root)
+	if err != nil {
+		return nil, err
+	}
+	fsys := &s3FS{client: loc.client, bucket: loc.bucket}
+
+	exists, err := loc.client.BucketExists(context.Background(), loc.bucket)
+	if err != nil {
+		return nil, fmt.Errorf("无法访问存储桶 %s: %w", loc.bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("存储桶不存在: %s", loc.bucket)
+	}
+
+	rootDir := loc.key
+	if rootDir == "" {
+		rootDir = "."
+	}
+	return &Mount{FS: fsys, Root: rootDir, Prefix: loc.prefix}, nil
+}
+
+// s3FS 把对象键中的 / 视为目录分隔符的只读文件系统
+type s3FS struct {
+	client *minio.Client
+	bucket string
+}
+
+// s3FileInfo 对象或公共前缀的信息
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *s3FileInfo) Name() string               { return i.name }
+func (i *s3FileInfo) Size() int64                { return i.size }
+func (i *s3FileInfo) ModTime() time.Time         { return i.modTime }
+func (i *s3FileInfo) IsDir() bool                { return i.dir }
+func (i *s3FileInfo) Sys() any                   { return nil }
+func (i *s3FileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i *s3FileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func (i *s3FileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// s3Error 把 S3 错误转换为文件系统错误
+func s3Error(op, name string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket", "NotFound":
+		err = fs.ErrNotExist
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		err = fs.ErrPermission
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// dirPrefix 返回目录对应的列举前缀
+func dirPrefix(name string) string {
+	if name == "." {
+		return ""
+	}
+	return name + "/"
+}
+
+// listObjects 列举对象；提前结束读取时必须调用 stop，它取消列举并读完剩余的结果，
+// 否则 minio-go 的列举协程会一直阻塞在发送上（取消后它还会再发送一次 ctx.Err()）
+func (s *s3FS) listObjects(opts minio.ListObjectsOptions) (<-chan minio.ObjectInfo, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := s.client.ListObjects(ctx, s.bucket, opts)
+	return objects, func() {
+		cancel()
+		for range objects {
+		}
+	}
+}
+
+// Stat 实现 fs.StatFS；对象不存在但有以其为前缀的对象时视为目录
+func (s *s3FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &s3FileInfo{name: ".", dir: true}, nil
+	}
+
+	ctx := context.Background()
+	obj, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{})
+	if err == nil {
+		return &s3FileInfo{name: path.Base(name), size: obj.Size, modTime: obj.LastModified}, nil
+	}
+	if minio.ToErrorResponse(err).StatusCode != 404 {
+		return nil, s3Error("stat", name, err)
+	}
+
+	objects, stop := s.listObjects(minio.ListObjectsOptions{Prefix: dirPrefix(name), MaxKeys: 1})
+	defer stop()
+	for obj := range objects {
+		if obj.Err != nil {
+			return nil, s3Error("stat", name, obj.Err)
+		}
+		return &s3FileInfo{name: path.Base(name), dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir 实现 fs.ReadDirFS，公共前缀作为子目录，结果按名称排序
+func (s *s3FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	prefix := dirPrefix(name)
+	objects, stop := s.listObjects(minio.ListObjectsOptions{Prefix: prefix})
+	defer stop()
+	var entries []fs.DirEntry
+	for obj := range objects {
+		if obj.Err != nil {
+			return nil, s3Error("readdir", name, obj.Err)
+		}
+		rel := strings.TrimPrefix(obj.Key, prefix)
+		if rel == "" {
+			// 目录占位对象
+			continue
+		}
+		if strings.HasSuffix(rel, "/") {
+			entries = append(entries, &s3FileInfo{name: strings.TrimSuffix(rel, "/"), dir: true})
+			continue
+		}
+		entries = append(entries, &s3FileInfo{name: rel, size: obj.Size, modTime: obj.LastModified})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Open 实现 fs.FS
+func (s *s3FS) Open(name string) (fs.File, error) {
+	info, err := s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &remoteDir{name: name, info: info, list: func() ([]fs.DirEntry, error) { return s.ReadDir(name) }}, nil
+	}
+	return &s3File{fsys: s, name: name, info: info}, nil
+}
+
+// s3File S3 对象，随机读取使用 Range 请求，顺序读取复用同一个响应
+type s3File struct {
+	fsys   *s3FS
+	name   string
+	info   fs.FileInfo
+	offset int64
+	body   io.ReadCloser
+}
+
+func (f *s3File) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *s3File) Close() error {
+	if f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	return nil
+}
+
+// get 请求从 offset 开始的内容，length<=0 表示到对象末尾
+func (f *s3File) get(offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	var err error
+	switch {
+	case length > 0:
+		err = opts.SetRange(offset, offset+length-1)
+	case offset > 0:
+		err = opts.SetRange(offset, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	core := minio.Core{Client: f.fsys.client}
+	body, _, _, err := core.GetObject(context.Background(), f.fsys.bucket, f.name, opts)
+	if err != nil {
+		return nil, s3Error("read", f.name, err)
+	}
+	return body, nil
+}
+
+// Read 实现 io.Reader
+func (f *s3File) Read(p []byte) (int, error) {
+	if f.offset >= f.info.Size() {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		body, err := f.get(f.offset, 0)
+		if err != nil {
+			return 0, err
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+// ReadAt 实现 io.ReaderAt，每次调用发送一个 Range 请求
+func (f *s3File) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.info.Size() {
+		return 0, io.EOF
+	}
+	length := min(int64(len(p)), f.info.Size()-off)
+	body, err := f.get(off, length)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:length])
+	if err == nil && length < int64(len(p)) {
+		err = io.EOF
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Seek 实现 io.Seeker
+func (f *s3File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// IsS3Path 判断是否是 S3 地址（用于导出目标）
+func IsS3Path(p string) bool {
+	scheme := urlScheme(p)
+	return scheme == BackendS3 || scheme == BackendS3HTTP
+}
+
+// s3Uploader 上传文件到 S3
+type s3Uploader struct {
+	loc *s3Location
+}
+
+// newS3Uploader 连接导出目标的存储桶，不存在时返回错误
+func newS3Uploader(dest string) (*s3Uploader, error) {
+	loc, err := parseS3URL(dest)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := loc.client.BucketExists(context.Background(), loc.bucket)
+	if err != nil {
+		return nil, fmt.Errorf("无法访问存储桶 %s: %w", loc.bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("存储桶不存在: %s", loc.bucket)
+	}
+	return &s3Uploader{loc: loc}, nil
+}
+
+// objectKey 返回相对路径对应的对象键
+func (u *s3Uploader) objectKey(relPath string) string {
+	relPath = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(relPath, "\\", "/")), "/")
+	if u.loc.key == "" {
+		return relPath
+	}
+	return u.loc.key + "/" + relPath
+}
+
+// exists 判断对象是否已存在
+func (u *s3Uploader) exists(ctx context.Context, key string) bool {
+	_, err := u.loc.client.StatObject(ctx, u.loc.bucket, key, minio.StatObjectOptions{})
+	return err == nil
+}
+
+// upload 上传文件，大文件自动使用分片上传。progress 在每次发送数据后被调用，
+// 分片上传时 minio 会在多个协程中并发调用。上传大小取自打开的文件，扫描后文件有变化时按当前内容上传
+func (u *s3Uploader) upload(ctx context.Context, src, key string, progress func(n int64)) error {
+	rc, size, err := openSourceSized(src)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	_, err = u.loc.client.PutObject(ctx, u.loc.bucket, key, rc, size, minio.PutObjectOptions{
+		PartSize:    s3PartSize,
+		ContentType: mimeTypeByExt(path.Ext(key)),
+		Progress:    progressReader(progress),
+	})
+	if err != nil {
+		return fmt.Errorf("上传失败: %w", err)
+	}
+	return nil
+}
+
+// put 上传内存中的数据（如导出清单）
+func (u *s3Uploader) put(ctx context.Context, key string, data []byte) error {
+	_, err := u.loc.client.PutObject(ctx, u.loc.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return fmt.Errorf("上传失败: %w", err)
+	}
+	return nil
+}
+
+// progressReader minio 通过读取 Progress 报告已上传的字节数
+type progressReader func(n int64)
+
+func (p progressReader) Read(b []byte) (int, error) {
+	if p != nil {
+		p(int64(len(b)))
+	}
+	return len(b), nil
+}
+
+// Office 文档的 MIME 类型
+var officeMimeTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".csv":  "text/csv",
+}
+
+// mimeTypeByExt 返回扩展名对应的 MIME 类型
+func mimeTypeByExt(ext string) string {
+	if t, ok := officeMimeTypes[strings.ToLower(ext)]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+var errS3ZipUnsupported = errors.New("打包导出不支持 S3 目标，请先导出到本地")
@@ -0,0 +1,478 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeS3 只实现扫描和导出用到的 S3 接口的内存服务器（路径风格，不校验签名）
+type fakeS3 struct {
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+
+	rangeGets  atomic.Int64 // 带 Range 的 GET 请求数
+	servedGet  atomic.Int64 // GET 返回的对象字节数
+	partsPut   atomic.Int64 // 上传的分片数
+	multiparts atomic.Int64 // 完成的分片上传数
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, string) {
+	t.Helper()
+	isolateConfig(t)
+	f := &fakeS3{bucket: bucket, objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, "s3+http://" + strings.TrimPrefix(srv.URL, "http://") + "/" + bucket
+}
+
+func (f *fakeS3) put(key string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = data
+}
+
+func (f *fakeS3) get(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	return data, ok
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (f *fakeS3) error(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message><Resource>%s</Resource><RequestId>1</RequestId></Error>", code, code, r.URL.Path)
+	}
+}
+
+func (f *fakeS3) xml(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	data, _ := xml.Marshal(v)
+	w.Write(append([]byte(xml.Header), data...))
+}
+
+// body 读取请求体，签名的流式上传（aws-chunked）需要去掉分块头
+func (f *fakeS3) body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var out bytes.Buffer
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseInt(strings.SplitN(strings.TrimSpace(line), ";", 2)[0], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+		if _, err := io.CopyN(&out, br, size); err != nil {
+			return nil, err
+		}
+		br.ReadString('\n')
+	}
+}
+
+type fakeListResult struct {
+	XMLName               xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string
+	Prefix                string
+	Delimiter             string
+	MaxKeys               int
+	KeyCount              int
+	IsTruncated           bool
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []fakeListObject
+	CommonPrefixes        []fakeListPrefix
+}
+
+type fakeListObject struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+type fakeListPrefix struct {
+	Prefix string
+}
+
+type fakeCompleteUpload struct {
+	Parts []struct {
+		PartNumber int
+		ETag       string
+	} `xml:"Part"`
+}
+
+var fakeModTime = time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		f.error(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	q := r.URL.Query()
+
+	if key == "" {
+		switch {
+		case q.Has("location"):
+			f.xml(w, struct {
+				XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint"`
+			}{})
+		case r.Method == http.MethodHead:
+		case r.Method == http.MethodGet:
+			f.list(w, q.Get("prefix"), q.Get("delimiter"), q.Get("max-keys"))
+		default:
+			f.error(w, r, http.StatusNotImplemented, "NotImplemented")
+		}
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.mu.Lock()
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = map[int][]byte{}
+		f.mu.Unlock()
+		f.xml(w, struct {
+			XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		data, err := f.body(r)
+		if err != nil {
+			f.error(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		f.mu.Lock()
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if ok {
+			parts[n] = data
+		}
+		f.mu.Unlock()
+		if !ok {
+			f.error(w, r, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		f.partsPut.Add(1)
+		w.Header().Set("ETag", etag(data))
+
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		var req fakeCompleteUpload
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.error(w, r, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		f.mu.Lock()
+		parts := f.uploads[q.Get("uploadId")]
+		var data []byte
+		for _, p := range req.Parts {
+			data = append(data, parts[p.PartNumber]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, q.Get("uploadId"))
+		f.mu.Unlock()
+		f.multiparts.Add(1)
+		f.xml(w, struct {
+			XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: etag(data)})
+
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		f.mu.Lock()
+		delete(f.uploads, q.Get("uploadId"))
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		data, err := f.body(r)
+		if err != nil {
+			f.error(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.put(key, data)
+		w.Header().Set("ETag", etag(data))
+
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		data, ok := f.get(key)
+		if !ok {
+			f.error(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if r.Method == http.MethodGet {
+			if r.Header.Get("Range") != "" {
+				f.rangeGets.Add(1)
+			}
+			w = &countingResponse{ResponseWriter: w, n: &f.servedGet}
+		}
+		w.Header().Set("ETag", etag(data))
+		http.ServeContent(w, r, key, fakeModTime, bytes.NewReader(data))
+
+	default:
+		f.error(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list 实现 ListObjectsV2，delimiter 为 / 时把下级目录合并为公共前缀
+func (f *fakeS3) list(w http.ResponseWriter, prefix, delimiter, maxKeys string) {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	res := fakeListResult{Name: f.bucket, Prefix: prefix, Delimiter: delimiter, MaxKeys: 1000}
+	if n, err := strconv.Atoi(maxKeys); err == nil && n > 0 {
+		res.MaxKeys = n
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if res.KeyCount == res.MaxKeys {
+			// 测试只用到第一页（Stat 时 max-keys 为 1）
+			res.IsTruncated, res.NextContinuationToken = true, key
+			break
+		}
+		rest := strings.TrimPrefix(key, prefix)
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			p := prefix + rest[:i+len(delimiter)]
+			if !seen[p] {
+				seen[p] = true
+				res.CommonPrefixes = append(res.CommonPrefixes, fakeListPrefix{Prefix: p})
+				res.KeyCount++
+			}
+			continue
+		}
+		res.Contents = append(res.Contents, fakeListObject{
+			Key:          key,
+			LastModified: fakeModTime.Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(f.objects[key]),
+			Size:         int64(len(f.objects[key])),
+			StorageClass: "STANDARD",
+		})
+		res.KeyCount++
+	}
+	f.mu.Unlock()
+	f.xml(w, res)
+}
+
+// countingResponse 统计写出的响应体字节数
+type countingResponse struct {
+	http.ResponseWriter
+	n *atomic.Int64
+}
+
+func (c *countingResponse) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+func TestS3Listing(t *testing.T) {
+	fake, root := newFakeS3(t, "docs")
+	fake.put("reports/a.pdf", testPDF(0))
+	fake.put("reports/2024/b.csv", []byte("name,amount\nx,1\n"))
+	fake.put("reports/2024/", nil) // 目录占位对象
+	fake.put("reports/notes.txt", []byte("skip"))
+	fake.put("other/c.pdf", testPDF(0))
+
+	m, err := mountS3(root + "/reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := fs.ReadDir(m.FS, m.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, fmt.Sprintf("%s:%v", e.Name(), e.IsDir()))
+	}
+	if got, want := strings.Join(names, " "), "2024:true a.pdf:false notes.txt:false"; got != want {
+		t.Errorf("ReadDir = %s, want %s", got, want)
+	}
+
+	if info, err := fs.Stat(m.FS, "reports/2024"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(reports/2024) = %v, %v, want directory", info, err)
+	}
+	if info, err := fs.Stat(m.FS, "reports/a.pdf"); err != nil || info.IsDir() || info.Size() != int64(len(testPDF(0))) {
+		t.Errorf("Stat(reports/a.pdf) = %v, %v", info, err)
+	}
+	if _, err := fs.Stat(m.FS, "reports/missing.pdf"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(missing) error = %v, want fs.ErrNotExist", err)
+	}
+
+	result, err := NewScanner().Scan(ScanOptions{RootPath: root + "/reports", ValidateFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Release()
+	var paths []string
+	for _, f := range result.Files {
+		if !f.IsValid {
+			t.Errorf("%s invalid: %s", f.Path, f.InvalidReason)
+		}
+		paths = append(paths, f.Path)
+	}
+	sort.Strings(paths)
+	want := []string{root + "/reports/2024/b.csv", root + "/reports/a.pdf"}
+	if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+		t.Errorf("scanned %q, want %q", paths, want)
+	}
+
+	if _, err := mountS3(strings.TrimSuffix(root, "docs") + "missing"); err == nil {
+		t.Error("mounting a missing bucket succeeded")
+	}
+}
+
+func TestS3ListingDoesNotLeak(t *testing.T) {
+	fake, root := newFakeS3(t, "docs")
+	for i := 0; i < 5; i++ {
+		fake.put(fmt.Sprintf("many/%d.pdf", i), testPDF(i))
+	}
+	m, err := mountS3(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := func() {
+		if info, err := fs.Stat(m.FS, "many"); err != nil || !info.IsDir() {
+			t.Fatalf("Stat(many) = %v, %v", info, err)
+		}
+	}
+	stat() // 建立连接
+	before := runtime.NumGoroutine()
+
+	// 目录下还有更多对象时只读取第一个，提前返回后列举协程也要退出
+	for i := 0; i < 20; i++ {
+		stat()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before+2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before+2 {
+		t.Errorf("%d goroutines after 20 directory stats, %d before", n, before)
+	}
+}
+
+func TestS3RangedValidation(t *testing.T) {
+	fake, root := newFakeS3(t, "docs")
+	const padding = 4 << 20
+	pdf := testPDF(padding)
+	fake.put("big.pdf", pdf)
+	broken := append([]byte(nil), pdf[:len(pdf)-8]...)
+	fake.put("broken.pdf", broken)
+
+	m, err := mountS3(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, msg := ValidateFS(m.FS, "big.pdf", "pdf"); !ok {
+		t.Fatalf("ValidateFS(big.pdf) = false, %s", msg)
+	}
+	if ok, _ := ValidateFS(m.FS, "broken.pdf", "pdf"); ok {
+		t.Error("ValidateFS(broken.pdf) = true, want false")
+	}
+	if fake.rangeGets.Load() == 0 {
+		t.Error("validation did not use range requests")
+	}
+	if served := fake.servedGet.Load(); served > padding/4 {
+		t.Errorf("validation downloaded %d bytes of two %d byte objects", served, len(pdf))
+	}
+}
+
+func TestS3MultipartUpload(t *testing.T) {
+	fake, root := newFakeS3(t, "backup")
+	dir := t.TempDir()
+
+	data := make([]byte, s3PartSize+s3PartSize/2)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	big := writeTestFile(t, dir, "big.pdf", data)
+	small := writeTestFile(t, dir, "small.pdf", testPDF(0))
+
+	u, err := newS3Uploader(root + "/2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent atomic.Int64
+	if err := u.upload(context.Background(), big, u.objectKey("big.pdf"), func(n int64) { sent.Add(n) }); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := fake.get("2024/big.pdf"); !ok || !bytes.Equal(got, data) {
+		t.Fatalf("uploaded object differs (found %v, %d bytes)", ok, len(got))
+	}
+	if fake.multiparts.Load() != 1 || fake.partsPut.Load() < 2 {
+		t.Errorf("multipart uploads = %d, parts = %d, want 1 upload with at least 2 parts", fake.multiparts.Load(), fake.partsPut.Load())
+	}
+	if sent.Load() != int64(len(data)) {
+		t.Errorf("progress reported %d bytes, want %d", sent.Load(), len(data))
+	}
+
+	// 扫描后文件变大：按打开时的大小上传完整内容
+	if err := os.WriteFile(small, testPDF(100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := []FileInfo{{Path: small, Name: "small.pdf", Size: int64(len(testPDF(0))), FileType: "pdf"}}
+	result, err := NewExporter().Export(context.Background(), ExportOptions{DestPath: root + "/2024", Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 1 || result.Failed != 0 {
+		t.Fatalf("export result = %+v", result)
+	}
+	if got, _ := fake.get("2024/small.pdf"); !bytes.Equal(got, testPDF(100)) {
+		t.Errorf("uploaded %d bytes, want the current %d byte file", len(got), len(testPDF(100)))
+	}
+	if _, ok := fake.get(result.ManifestPath); !ok {
+		t.Errorf("manifest %q not uploaded", result.ManifestPath)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(matches) != 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+}
@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 已保存查询文件版本
+const savedQueriesFileVersion = 1
+
+// SavedQuery 已保存的查询，可在不同扫描之间复用
+type SavedQuery struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// savedQueriesFile 已保存查询文件格式
+type savedQueriesFile struct {
+	Version int          `json:"version"`
+	Queries []SavedQuery `json:"queries"`
+}
+
+var savedQueriesMu sync.Mutex
+
+// savedQueriesPath 返回已保存查询文件路径
+func savedQueriesPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "queries.json"), nil
+}
+
+// readSavedQueries 读取已保存的查询，文件不存在时返回空列表
+func readSavedQueries() ([]SavedQuery, error) {
+	path, err := savedQueriesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取已保存的查询: %w", err)
+	}
+
+	var qf savedQueriesFile
+	if err := json.Unmarshal(data, &qf); err != nil {
+		return nil, fmt.Errorf("查询文件格式错误: %w", err)
+	}
+	if qf.Version > savedQueriesFileVersion {
+		return nil, fmt.Errorf("不支持的查询文件版本: %d", qf.Version)
+	}
+	return qf.Queries, nil
+}
+
+// writeSavedQueries 写入已保存的查询
+func writeSavedQueries(queries []SavedQuery) error {
+	path, err := savedQueriesPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(savedQueriesFile{Version: savedQueriesFileVersion, Queries: queries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("无法保存查询: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法保存查询: %w", err)
+	}
+	return nil
+}
+
+// ListSavedQueries 列出已保存的查询
+func ListSavedQueries() ([]SavedQuery, error) {
+	savedQueriesMu.Lock()
+	defer savedQueriesMu.Unlock()
+	return readSavedQueries()
+}
+
+// SaveQuery 保存查询，同名查询会被替换；查询有语法错误时不保存
+func SaveQuery(name, query string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("查询名称不能为空")
+	}
+	if _, err := ParseQuery(query); err != nil {
+		return err
+	}
+
+	savedQueriesMu.Lock()
+	defer savedQueriesMu.Unlock()
+
+	queries, err := readSavedQueries()
+	if err != nil {
+		return err
+	}
+	saved := SavedQuery{Name: name, Query: query, UpdatedAt: time.Now()}
+	for i, q := range queries {
+		if q.Name == name {
+			queries[i] = saved
+			return writeSavedQueries(queries)
+		}
+	}
+	return writeSavedQueries(append(queries, saved))
+}
+
+// DeleteSavedQuery 删除已保存的查询
+func DeleteSavedQuery(name string) error {
+	savedQueriesMu.Lock()
+	defer savedQueriesMu.Unlock()
+
+	queries, err := readSavedQueries()
+	if err != nil {
+		return err
+	}
+	kept := queries[:0]
+	for _, q := range queries {
+		if q.Name != name {
+			kept = append(kept, q)
+		}
+	}
+	return writeSavedQueries(kept)
+}
@@ -1,6 +1,11 @@
 package scanner
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -11,22 +16,42 @@ import (
 
 // FileInfo 文件信息结构
 type FileInfo struct {
-	Path          string    `json:"path"`
-	Name          string    `json:"name"`
-	Size          int64     `json:"size"`
-	ModTime       time.Time `json:"modTime"`
-	Extension     string    `json:"extension"`
-	FileType      string    `json:"fileType"` // pdf, word, excel, ppt
-	IsValid       bool      `json:"isValid"`
-	InvalidReason string    `json:"invalidReason,omitempty"`
+	Path          string       `json:"path"`
+	Name          string       `json:"name"`
+	Size          int64        `json:"size"`
+	ModTime       time.Time    `json:"modTime"`
+	Extension     string       `json:"extension"`
+	FileType      string       `json:"fileType"` // pdf, word, excel, ppt
+	IsValid       bool         `json:"isValid"`
+	InvalidReason string       `json:"invalidReason,omitempty"`
+	Findings      []Finding    `json:"findings,omitempty"`  // 敏感信息检测结果
+	Labels        []string     `json:"labels,omitempty"`    // 分类标签
+	Metadata      *DocMetadata `json:"metadata,omitempty"`  // 文档元数据（按需提取）
+	Root          string       `json:"root,omitempty"`      // 所属的扫描根路径
+	Hash          string       `json:"hash,omitempty"`      // 文件内容的 SHA-256（ComputeHash 时计算）
+	Encrypted     bool         `json:"encrypted,omitempty"` // 是否设置了打开密码（DetectEncryption 时检测）
 }
 
 // ScanOptions 扫描选项
 type ScanOptions struct {
 	RootPath      string   `json:"rootPath"`
+	RootPaths     []string `json:"rootPaths"`     // 多个扫描根路径，与 RootPath 合并，重叠的路径只扫描一次
+	Backend       string   `json:"backend"`       // 存储后端（os、zip 等），为空时根据 RootPath 的 URL scheme 选择，默认本地文件系统
 	IncludeTypes  []string `json:"includeTypes"`  // pdf, word, excel, ppt
 	ExcludePaths  []string `json:"excludePaths"`  // 排除的路径
 	ValidateFiles bool     `json:"validateFiles"` // 是否验证文件有效性
+
+	InspectContent bool            `json:"inspectContent"` // 是否检测文档中的敏感信息
+	CustomPatterns []CustomPattern `json:"customPatterns"` // 自定义敏感信息规则
+	Rules          []ClassifyRule  `json:"rules"`          // 分类规则
+
+	ScanArchives bool `json:"scanArchives"` // 是否扫描 zip/tar 压缩包内的文件
+	ArchiveDepth int  `json:"archiveDepth"` // 进入的压缩包层数，0 表示默认值
+
+	ComputeHash      bool `json:"computeHash"`      // 是否计算文件内容的 SHA-256（用于比较两次扫描）
+	DetectEncryption bool `json:"detectEncryption"` // 是否检测文档是否加密
+
+	FS fs.FS `json:"-"` // 直接指定要扫描的文件系统（如内存文件系统），RootPath 为其中的目录
 }
 
 // ScanResult 扫描结果
@@ -36,6 +61,22 @@ type ScanResult struct {
 	ValidCount   int        `json:"validCount"`
 	InvalidCount int        `json:"invalidCount"`
 	ScanTime     float64    `json:"scanTime"` // 扫描耗时（秒）
+
+	FindingCounts map[string]int `json:"findingCounts,omitempty"` // 每种敏感信息涉及的文件数
+	LabelCounts   map[string]int `json:"labelCounts,omitempty"`   // 每个标签涉及的文件数
+	Roots         []RootStats    `json:"roots,omitempty"`         // 各扫描根路径的统计
+	SessionID     string         `json:"sessionId,omitempty"`     // 结果保存在界面层会话中时的会话 ID
+
+	mounts []*Mount // 结果引用的远程挂载
+}
+
+// Release 释放扫描结果引用的远程挂载（SFTP 连接、内存文件系统等），之后不能再按结果路径读取其中的文件。
+// 不再使用扫描结果时调用，可以重复调用
+func (r *ScanResult) Release() {
+	for _, m := range r.mounts {
+		m.release()
+	}
+	r.mounts = nil
 }
 
 // ScanProgress 扫描进度
@@ -45,17 +86,30 @@ type ScanProgress struct {
 	FoundFiles  int    `json:"foundFiles"`  // 已找到的文件数
 	CurrentFile string `json:"currentFile"` // 当前处理的文件
 	IsScanning  bool   `json:"isScanning"`  // 是否正在扫描
+
+	CurrentRoot string         `json:"currentRoot,omitempty"` // 当前扫描的根路径
+	Roots       []RootProgress `json:"roots,omitempty"`       // 各扫描根路径的进度
 }
 
 // ProgressCallback 进度回调函数类型
 type ProgressCallback func(progress ScanProgress)
 
+// BatchCallback 批量结果回调函数类型，扫描过程中分批返回找到的文件
+type BatchCallback func(files []FileInfo)
+
+// 批量结果的大小和最长间隔
+const (
+	scanBatchSize     = 500
+	scanBatchInterval = 300 * time.Millisecond
+)
+
 // Scanner 文件扫描器
 type Scanner struct {
 	options          ScanOptions
 	mu               sync.Mutex
 	progress         ScanProgress
 	progressCallback ProgressCallback
+	batchCallback    BatchCallback
 }
 
 // NewScanner 创建新的扫描器
@@ -70,6 +124,13 @@ func (s *Scanner) SetProgressCallback(callback ProgressCallback) {
 	s.progressCallback = callback
 }
 
+// SetBatchCallback 设置批量结果回调
+func (s *Scanner) SetBatchCallback(callback BatchCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchCallback = callback
+}
+
 // GetProgress 获取当前扫描进度
 func (s *Scanner) GetProgress() ScanProgress {
 	s.mu.Lock()
@@ -140,31 +201,192 @@ var windowsSystemDirs = []string{
 	"ProgramData",
 }
 
-// Scan 执行文件扫描
+// Scan 执行文件扫描，多个根路径依次扫描，结果合并去重
 func (s *Scanner) Scan(options ScanOptions) (*ScanResult, error) {
 	startTime := time.Now()
 
+	roots := scanRoots(options)
+	if len(roots) == 0 {
+		return nil, errors.New("未指定扫描路径")
+	}
+
 	s.mu.Lock()
 	s.options = options
+	batchCallback := s.batchCallback
 	s.mu.Unlock()
 
+	// 准备敏感信息检测器
+	var inspector *ContentInspector
+	if options.InspectContent {
+		var err error
+		if inspector, err = NewContentInspector(options.CustomPatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	// 准备分类器
+	var classifier *Classifier
+	if len(options.Rules) > 0 {
+		var err error
+		if classifier, err = NewClassifier(options.Rules); err != nil {
+			return nil, err
+		}
+	}
+
 	var files []FileInfo
 	var mu sync.Mutex
+	seen := make(map[string]bool)
 	scannedDirs := 0
 	foundFiles := 0
 	lastUpdateTime := time.Now()
 
+	// 分批发送新找到的文件，force 为 true 时发送所有未发送的文件
+	sent := 0
+	lastBatchTime := time.Now()
+	flushBatch := func(force bool) {
+		if batchCallback == nil {
+			return
+		}
+		mu.Lock()
+		pending := len(files) - sent
+		if pending == 0 || (!force && pending < scanBatchSize && time.Since(lastBatchTime) < scanBatchInterval) {
+			mu.Unlock()
+			return
+		}
+		batch := append([]FileInfo(nil), files[sent:]...)
+		sent = len(files)
+		lastBatchTime = time.Now()
+		mu.Unlock()
+		batchCallback(batch)
+	}
+
+	// 各根路径的进度
+	rootProgress := make([]RootProgress, len(roots))
+	for i, root := range roots {
+		rootProgress[i].Root = root
+	}
+	current := 0
+
+	// 生成进度快照
+	snapshot := func(currentPath, currentFile string, scanning bool) ScanProgress {
+		progress := ScanProgress{
+			CurrentPath: currentPath,
+			ScannedDirs: scannedDirs,
+			FoundFiles:  foundFiles,
+			CurrentFile: currentFile,
+			IsScanning:  scanning,
+			Roots:       append([]RootProgress(nil), rootProgress...),
+		}
+		if scanning {
+			progress.CurrentRoot = roots[current]
+		}
+		return progress
+	}
+
 	// 初始化进度
-	s.updateProgress(ScanProgress{
-		CurrentPath: options.RootPath,
-		ScannedDirs: 0,
-		FoundFiles:  0,
-		IsScanning:  true,
-	})
+	s.updateProgress(snapshot(roots[0], "", true))
 
 	// 合并默认排除路径和用户指定的排除路径
 	excludePaths := append(defaultExcludePaths, options.ExcludePaths...)
 
+	// 检查文件类型是否需要扫描
+	matchType := func(name string) (string, string, bool) {
+		ext := strings.ToLower(filepath.Ext(name))
+		fileType, ok := extensionMap[ext]
+		if !ok {
+			return "", "", false
+		}
+		if len(options.IncludeTypes) > 0 {
+			for _, t := range options.IncludeTypes {
+				if t == fileType {
+					return ext, fileType, true
+				}
+			}
+			return "", "", false
+		}
+		return ext, fileType, true
+	}
+
+	// 加密检测、敏感信息检测和按元数据或正文分类需要读取文档内容
+	needDocument := options.DetectEncryption || inspector != nil || (classifier != nil && classifier.needsDocument())
+
+	// 验证、检测并分类找到的文件，然后加入结果；open 打开文档内容，各项检测共用一次打开
+	addFile := func(fileInfo FileInfo, validate func() (bool, string), hash func() string, open func() (docReader, error)) {
+		// 同一文件可能经由不同的根路径（如符号链接）被找到
+		mu.Lock()
+		duplicate := seen[fileInfo.Path]
+		seen[fileInfo.Path] = true
+		mu.Unlock()
+		if duplicate {
+			return
+		}
+
+		if options.ValidateFiles {
+			fileInfo.IsValid, fileInfo.InvalidReason = validate()
+		}
+
+		if options.ComputeHash {
+			fileInfo.Hash = hash()
+		}
+
+		// 损坏的文件无法判断是否加密，也不检测敏感信息
+		detectEncryption := options.DetectEncryption && fileInfo.IsValid
+		inspect := inspector != nil && fileInfo.IsValid
+		var doc docReader
+		if detectEncryption || inspect || (classifier != nil && classifier.needsDocument()) {
+			if d, err := open(); err == nil {
+				defer d.Close()
+				doc = d
+			}
+		}
+
+		// 检测加密
+		if detectEncryption && doc != nil {
+			fileInfo.Encrypted = DetectEncryption(doc, doc.Size(), fileInfo.FileType)
+		}
+
+		// 正文只提取一次，敏感信息检测和分类共用
+		var text *string
+		getText := func() string {
+			if text == nil {
+				t := ""
+				if doc != nil {
+					t, _ = extractDocumentText(doc, fileInfo.Path, 0)
+				}
+				text = &t
+			}
+			return *text
+		}
+
+		// 检测敏感信息
+		if inspect && doc != nil {
+			fileInfo.Findings = inspector.Inspect(getText())
+		}
+
+		// 按规则分类
+		if classifier != nil {
+			classifier.classifyDocument(&fileInfo, doc, getText)
+		}
+
+		mu.Lock()
+		files = append(files, fileInfo)
+		foundFiles++
+		rootProgress[current].FoundFiles++
+		mu.Unlock()
+		flushBatch(false)
+
+		// 更新进度（找到文件时）
+		if time.Since(lastUpdateTime) > 100*time.Millisecond {
+			s.updateProgress(snapshot(filepath.Dir(fileInfo.Path), fileInfo.Name, true))
+			lastUpdateTime = time.Now()
+		}
+	}
+
+	archiveDepth := options.ArchiveDepth
+	if archiveDepth <= 0 {
+		archiveDepth = defaultArchiveDepth
+	}
+
 	// 检查路径是否应该被排除
 	shouldExclude := func(path string, isDir bool) bool {
 		// 统一路径分隔符为 /
@@ -194,113 +416,221 @@ func (s *Scanner) Scan(options ScanOptions) (*ScanResult, error) {
 		return false
 	}
 
-	err := filepath.WalkDir(options.RootPath, func(path string, d fs.DirEntry, err error) error {
+	// 扫描单个根路径
+	var resultMounts []*Mount
+	scanRoot := func(root string) error {
+		// 挂载要扫描的文件系统
+		mount, err := mountRoot(options, root)
 		if err != nil {
-			// 跳过无法访问的目录（权限问题、符号链接等）
-			if d != nil && d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			return err
+		}
+		// 登记挂载，扫描中和扫描后都可以按结果路径提取内容、预览和导出；
+		// 远程挂载由扫描结果继续引用，直到调用 ScanResult.Release
+		mount.register()
+		if mount.shared() {
+			resultMounts = append(resultMounts, mount)
+		} else {
+			defer mount.release()
 		}
 
-		// 检查是否应该排除此路径
-		if shouldExclude(path, d.IsDir()) {
+		return fs.WalkDir(mount.FS, mount.Root, func(name string, d fs.DirEntry, err error) error {
+			path := mount.path(name)
+			if err != nil {
+				// 根路径本身无法访问时报告错误
+				if name == mount.Root {
+					return fmt.Errorf("无法访问扫描路径 %s: %w", root, err)
+				}
+				// 跳过无法访问的目录（权限问题、符号链接等）
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// 检查是否应该排除此路径
+			if shouldExclude(path, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// 处理目录 - 更新进度
 			if d.IsDir() {
-				return filepath.SkipDir
+				scannedDirs++
+				rootProgress[current].ScannedDirs++
+				// 每100ms更新一次进度，避免过于频繁
+				if time.Since(lastUpdateTime) > 100*time.Millisecond {
+					s.updateProgress(snapshot(path, "", true))
+					lastUpdateTime = time.Now()
+				}
+				flushBatch(false)
+				return nil
 			}
-			return nil
-		}
 
-		// 处理目录 - 更新进度
-		if d.IsDir() {
-			scannedDirs++
-			// 每100ms更新一次进度，避免过于频繁
-			if time.Since(lastUpdateTime) > 100*time.Millisecond {
-				s.updateProgress(ScanProgress{
-					CurrentPath: path,
-					ScannedDirs: scannedDirs,
-					FoundFiles:  foundFiles,
-					IsScanning:  true,
+			// 扫描压缩包内的文件，损坏的压缩包直接跳过
+			if options.ScanArchives && archiveKind(d.Name()) != "" {
+				skip := func(p string) bool { return shouldExclude(p, false) }
+				walkArchive(path, archiveDepth, skip, func(entry archiveEntry) error {
+					ext, fileType, ok := matchType(entry.name)
+					if !ok {
+						return nil
+					}
+					fileInfo := FileInfo{
+						Path:      entry.path,
+						Name:      entry.name,
+						Size:      entry.size,
+						ModTime:   entry.modTime,
+						Extension: ext,
+						FileType:  fileType,
+						IsValid:   true,
+						Root:      root,
+					}
+
+					// 需要读取内容时把条目解压一次，验证、哈希和内容检测都使用解压后的数据
+					if needDocument {
+						rc, err := entry.open()
+						var doc docReader
+						if err == nil {
+							doc, err = materialize(rc, entry.size)
+							rc.Close()
+						}
+						if err != nil {
+							addFile(fileInfo, func() (bool, string) {
+								return false, "无法读取压缩包内的文件"
+							}, func() string {
+								return ""
+							}, func() (docReader, error) {
+								return nil, err
+							})
+							return nil
+						}
+						defer doc.Close()
+						addFile(fileInfo, func() (bool, string) {
+							return ValidateReaderAt(doc, doc.Size(), fileType)
+						}, func() string {
+							hash, _ := hashReader(func() (io.ReadCloser, error) {
+								return io.NopCloser(io.NewSectionReader(doc, 0, doc.Size())), nil
+							})
+							return hash
+						}, func() (docReader, error) {
+							return sharedReader{doc}, nil
+						})
+						return nil
+					}
+
+					// tar 中的条目只能顺序读取一次，需要哈希时在验证的同时计算
+					var hashed string
+					addFile(fileInfo, func() (bool, string) {
+						rc, err := entry.open()
+						if err != nil {
+							return false, "无法读取压缩包内的文件"
+						}
+						defer rc.Close()
+						if !options.ComputeHash {
+							return ValidateStream(rc, entry.size, fileType)
+						}
+						h := sha256.New()
+						valid, reason := ValidateStream(io.TeeReader(rc, h), entry.size, fileType)
+						if _, err := io.Copy(h, rc); err == nil {
+							hashed = hex.EncodeToString(h.Sum(nil))
+						}
+						return valid, reason
+					}, func() string {
+						if hashed == "" {
+							hashed, _ = hashReader(entry.open)
+						}
+						return hashed
+					}, func() (docReader, error) {
+						return nil, errors.New("压缩包内的文件只能读取一次")
+					})
+					return nil
 				})
-				lastUpdateTime = time.Now()
+				return nil
 			}
-			return nil
-		}
-
-		// 检查文件扩展名
-		ext := strings.ToLower(filepath.Ext(path))
-		fileType, ok := extensionMap[ext]
-		if !ok {
-			return nil
-		}
 
-		// 检查是否在包含的类型中
-		if len(options.IncludeTypes) > 0 {
-			found := false
-			for _, t := range options.IncludeTypes {
-				if t == fileType {
-					found = true
-					break
-				}
+			// 检查文件扩展名和包含的类型
+			ext, fileType, ok := matchType(path)
+			if !ok {
+				return nil
 			}
-			if !found {
+
+			// 获取文件信息
+			info, err := d.Info()
+			if err != nil {
 				return nil
 			}
-		}
 
-		// 获取文件信息
-		info, err := d.Info()
-		if err != nil {
+			addFile(FileInfo{
+				Path:      path,
+				Name:      d.Name(),
+				Size:      info.Size(),
+				ModTime:   info.ModTime(),
+				Extension: ext,
+				FileType:  fileType,
+				IsValid:   true,
+				Root:      root,
+			}, func() (bool, string) {
+				return ValidateFS(mount.FS, name, fileType)
+			}, func() string {
+				hash, _ := hashReader(func() (io.ReadCloser, error) { return mount.FS.Open(name) })
+				return hash
+			}, func() (docReader, error) {
+				return openMounted(mount, name)
+			})
+
 			return nil
-		}
+		})
+	}
 
-		fileInfo := FileInfo{
-			Path:      path,
-			Name:      d.Name(),
-			Size:      info.Size(),
-			ModTime:   info.ModTime(),
-			Extension: ext,
-			FileType:  fileType,
-			IsValid:   true,
+	// 依次扫描各根路径，单个根路径失败不影响其他根路径
+	rootStats := make([]RootStats, len(roots))
+	var firstErr error
+	failedRoots := 0
+	for i, root := range roots {
+		current = i
+		rootStart := time.Now()
+		firstFile := len(files)
+		dirsBefore := scannedDirs
+
+		s.updateProgress(snapshot(root, "", true))
+		err := scanRoot(root)
+		flushBatch(true)
+
+		stats := RootStats{
+			Root:        root,
+			ScannedDirs: scannedDirs - dirsBefore,
+			ScanTime:    time.Since(rootStart).Seconds(),
 		}
-
-		// 验证文件有效性
-		if options.ValidateFiles {
-			valid, reason := ValidateFile(path, fileType)
-			fileInfo.IsValid = valid
-			fileInfo.InvalidReason = reason
+		for _, f := range files[firstFile:] {
+			stats.TotalCount++
+			if f.IsValid {
+				stats.ValidCount++
+			} else {
+				stats.InvalidCount++
+			}
 		}
-
-		mu.Lock()
-		files = append(files, fileInfo)
-		foundFiles++
-		mu.Unlock()
-
-		// 更新进度（找到文件时）
-		if time.Since(lastUpdateTime) > 100*time.Millisecond {
-			s.updateProgress(ScanProgress{
-				CurrentPath: filepath.Dir(path),
-				ScannedDirs: scannedDirs,
-				FoundFiles:  foundFiles,
-				CurrentFile: d.Name(),
-				IsScanning:  true,
-			})
-			lastUpdateTime = time.Now()
+		if err != nil {
+			stats.Error = err.Error()
+			rootProgress[i].Error = stats.Error
+			failedRoots++
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
-
-		return nil
-	})
+		rootProgress[i].Done = true
+		rootStats[i] = stats
+	}
 
 	// 扫描完成，更新进度
-	s.updateProgress(ScanProgress{
-		CurrentPath: options.RootPath,
-		ScannedDirs: scannedDirs,
-		FoundFiles:  foundFiles,
-		IsScanning:  false,
-	})
-
-	if err != nil {
-		return nil, err
+	s.updateProgress(snapshot(roots[0], "", false))
+
+	// 所有根路径都无法扫描时返回错误
+	if failedRoots == len(roots) {
+		for _, m := range resultMounts {
+			m.release()
+		}
+		return nil, firstErr
 	}
 
 	// 统计结果
@@ -314,13 +644,23 @@ func (s *Scanner) Scan(options ScanOptions) (*ScanResult, error) {
 		}
 	}
 
-	return &ScanResult{
+	result := &ScanResult{
 		Files:        files,
 		TotalCount:   len(files),
 		ValidCount:   validCount,
 		InvalidCount: invalidCount,
 		ScanTime:     time.Since(startTime).Seconds(),
-	}, nil
+		Roots:        rootStats,
+		mounts:       resultMounts,
+	}
+	if inspector != nil {
+		result.FindingCounts = countFindings(files)
+	}
+	if classifier != nil {
+		result.LabelCounts = countLabels(files)
+	}
+
+	return result, nil
 }
 
 // GetDrives 获取系统所有驱动器（Windows）或根目录（macOS/Linux）
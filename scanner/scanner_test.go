@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanSharesExtractedText(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "客户/名单.csv", []byte("name,phone,note\n张三,13812345678,合同变更\n"))
+	writeTestFile(t, dir, "其他.csv", []byte("name\n李四\n"))
+
+	result, err := NewScanner().Scan(ScanOptions{
+		RootPath:       dir,
+		ValidateFiles:  true,
+		InspectContent: true,
+		Rules: []ClassifyRule{
+			{Name: "变更", Labels: []string{"变更"}, Conditions: []RuleCondition{{Field: FieldContent, Op: OpContains, Value: "合同变更"}}},
+			{Name: "个人信息", Labels: []string{"个人信息"}, Conditions: []RuleCondition{{Field: FieldFinding, Op: OpEquals, Value: FindingMobile}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 2 {
+		t.Fatalf("scanned %d files, want 2", len(result.Files))
+	}
+	for _, f := range result.Files {
+		if f.Path != path {
+			if len(f.Findings) != 0 || len(f.Labels) != 0 {
+				t.Errorf("%s: findings %+v, labels %v, want none", f.Name, f.Findings, f.Labels)
+			}
+			continue
+		}
+		if len(f.Findings) != 1 || f.Findings[0].Type != FindingMobile || f.Findings[0].Samples[0] != "138****5678" {
+			t.Errorf("findings = %+v", f.Findings)
+		}
+		if len(f.Labels) != 2 || f.Labels[0] != "变更" || f.Labels[1] != "个人信息" {
+			t.Errorf("labels = %v, want [变更 个人信息]", f.Labels)
+		}
+	}
+}
+
+func TestScanFS(t *testing.T) {
+	isolateConfig(t)
+	fsys := fstest.MapFS{
+		"docs/a.pdf":              {Data: testPDF(16)},
+		"docs/2024/b.csv":         {Data: []byte("name,phone\n张三,13812345678\n")},
+		"docs/2024/bad.docx":      {Data: []byte("not a zip")},
+		"docs/node_modules/c.pdf": {Data: testPDF(0)},
+		"docs/readme.txt":         {Data: []byte("skip")},
+		"other/d.pdf":             {Data: testPDF(0)},
+	}
+	result, err := NewScanner().Scan(ScanOptions{FS: fsys, RootPath: "docs", ValidateFiles: true, InspectContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Release()
+
+	got := map[string]FileInfo{}
+	prefix := ""
+	for _, f := range result.Files {
+		i := strings.Index(f.Path, "/docs/")
+		if !strings.HasPrefix(f.Path, "mem://") || i < 0 {
+			t.Fatalf("unexpected path %s", f.Path)
+		}
+		prefix = f.Path[:i+1]
+		got[f.Path[i+1:]] = f
+	}
+	want := map[string]bool{"docs/a.pdf": true, "docs/2024/b.csv": true, "docs/2024/bad.docx": false}
+	if len(got) != len(want) {
+		t.Fatalf("scanned %v, want %v", got, want)
+	}
+	for name, valid := range want {
+		if f, ok := got[name]; !ok || f.IsValid != valid {
+			t.Errorf("%s: found %v, valid %v, want valid %v", name, ok, f.IsValid, valid)
+		}
+	}
+	if findings := got["docs/2024/b.csv"].Findings; len(findings) != 1 || findings[0].Type != FindingMobile {
+		t.Errorf("b.csv findings = %+v", findings)
+	}
+
+	// 扫描结果释放前可以按结果路径读取，释放后挂载被注销
+	if text, err := ExtractText(prefix+"docs/2024/b.csv", 0); err != nil || !strings.Contains(text, "张三") {
+		t.Errorf("ExtractText = %q, %v", text, err)
+	}
+	result.Release()
+	if m, _ := findMount(prefix + "docs/a.pdf"); m != nil {
+		t.Errorf("mount %s still registered after Release", prefix)
+	}
+
+	// 每次扫描使用新的挂载，释放后不会残留
+	countMounts := func() int {
+		mountsMu.RLock()
+		defer mountsMu.RUnlock()
+		return len(mounts)
+	}
+	before := countMounts()
+	for i := 0; i < 3; i++ {
+		r, err := NewScanner().Scan(ScanOptions{FS: fsys, RootPath: "other"})
+		if err != nil || len(r.Files) != 1 {
+			t.Fatalf("Scan = %+v, %v", r, err)
+		}
+		r.Release()
+	}
+	if left := countMounts(); left != before {
+		t.Errorf("%d mounts registered after releasing all results, want %d", left, before)
+	}
+}
+
+// closeCounter 记录关闭次数
+type closeCounter struct{ closed int }
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestMountSharedPrefix(t *testing.T) {
+	fsys := fstest.MapFS{"a.pdf": {Data: testPDF(0)}}
+	var first, second closeCounter
+	older := &Mount{FS: fsys, Root: ".", Prefix: "test://host/", Closer: &first}
+	newer := &Mount{FS: fsys, Root: ".", Prefix: "test://host/", Closer: &second}
+
+	older.register()
+	newer.register()
+	if m, name := findMount("test://host/a.pdf"); m != newer || name != "a.pdf" {
+		t.Fatalf("findMount = %p %q, want the newer mount", m, name)
+	}
+
+	// 新的扫描结果被丢弃时不能关闭仍在使用的旧挂载
+	newer.release()
+	if second.closed != 1 || first.closed != 0 {
+		t.Errorf("closed older %d, newer %d; want 0, 1", first.closed, second.closed)
+	}
+	if m, _ := findMount("test://host/a.pdf"); m != older {
+		t.Errorf("findMount after releasing the newer mount = %p, want the older one", m)
+	}
+
+	older.register()
+	older.release()
+	if first.closed != 0 {
+		t.Error("mount closed while still referenced")
+	}
+	older.release()
+	if first.closed != 1 {
+		t.Errorf("older closed %d times, want 1", first.closed)
+	}
+	if m, _ := findMount("test://host/a.pdf"); m != nil {
+		t.Error("mount still registered after the last release")
+	}
+}
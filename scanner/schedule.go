@@ -0,0 +1,510 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 定时扫描文件版本
+const schedulesFileVersion = 1
+
+// 定时扫描的重复周期
+const (
+	EveryHour  = "hourly"
+	EveryDay   = "daily"
+	EveryWeek  = "weekly"
+	EveryMonth = "monthly"
+)
+
+// 检查是否有到期定时扫描的间隔
+const scheduleCheckInterval = 30 * time.Second
+
+// 提醒中每类问题最多列出的文件数
+const maxAlertSamples = 20
+
+// 提醒的问题类型
+const (
+	AlertInvalid   = "invalid"   // 新出现的无效文件
+	AlertEncrypted = "encrypted" // 新出现的加密文件
+	AlertDuplicate = "duplicate" // 新出现的重复文件（内容相同）
+)
+
+// Schedule 定时扫描的执行时间
+type Schedule struct {
+	Every   string `json:"every"`   // hourly、daily、weekly、monthly
+	Time    string `json:"time"`    // 执行时间 HH:MM，每小时执行时只使用分钟
+	Weekday int    `json:"weekday"` // 每周执行时的星期（0 为星期日）
+	Day     int    `json:"day"`     // 每月执行时的日期（1-28）
+}
+
+// ScheduledScan 定时扫描
+type ScheduledScan struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Enabled  bool        `json:"enabled"`
+	Schedule Schedule    `json:"schedule"`
+	Options  ScanOptions `json:"options"`
+
+	NextRun      time.Time `json:"nextRun"`
+	LastRun      time.Time `json:"lastRun"`
+	LastRecordID string    `json:"lastRecordId,omitempty"` // 上次扫描保存的历史记录，用于判断哪些问题是新出现的
+	LastError    string    `json:"lastError,omitempty"`
+}
+
+// AlertFile 提醒中列出的文件
+type AlertFile struct {
+	Kind string `json:"kind"` // invalid、encrypted、duplicate
+	Path string `json:"path"`
+}
+
+// ScanAlert 定时扫描完成后发现的新问题
+type ScanAlert struct {
+	ScheduleID string         `json:"scheduleId"`
+	Name       string         `json:"name"`
+	RecordID   string         `json:"recordId,omitempty"` // 本次扫描的历史记录
+	Time       time.Time      `json:"time"`
+	Counts     map[string]int `json:"counts"`  // 各类新问题的文件数
+	Samples    []AlertFile    `json:"samples"` // 每类最多列出 maxAlertSamples 个文件
+	Error      string         `json:"error,omitempty"`
+}
+
+// schedulesFile 定时扫描文件格式
+type schedulesFile struct {
+	Version   int             `json:"version"`
+	Schedules []ScheduledScan `json:"schedules"`
+}
+
+var schedulesMu sync.Mutex
+
+// Validate 检查执行时间是否有效
+func (s Schedule) Validate() error {
+	if _, _, err := s.clock(); err != nil {
+		return err
+	}
+	switch s.Every {
+	case EveryHour, EveryDay:
+	case EveryWeek:
+		if s.Weekday < 0 || s.Weekday > 6 {
+			return errors.New("星期无效")
+		}
+	case EveryMonth:
+		if s.Day < 1 || s.Day > 28 {
+			return errors.New("每月执行的日期需要在 1 到 28 之间")
+		}
+	default:
+		return fmt.Errorf("未知的执行周期: %s", s.Every)
+	}
+	return nil
+}
+
+// clock 解析 HH:MM 形式的执行时间
+func (s Schedule) clock() (int, int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s.Time), ":")
+	hour, err1 := strconv.Atoi(hh)
+	minute, err2 := strconv.Atoi(mm)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("执行时间格式错误: %q（应为 HH:MM）", s.Time)
+	}
+	return hour, minute, nil
+}
+
+// Next 返回 after 之后的下一次执行时间（本地时间）
+func (s Schedule) Next(after time.Time) (time.Time, error) {
+	if err := s.Validate(); err != nil {
+		return time.Time{}, err
+	}
+	hour, minute, _ := s.clock()
+	after = after.Local()
+	y, m, d := after.Date()
+
+	var next time.Time
+	switch s.Every {
+	case EveryHour:
+		next = time.Date(y, m, d, after.Hour(), minute, 0, 0, time.Local)
+		if !next.After(after) {
+			next = next.Add(time.Hour)
+		}
+	case EveryDay:
+		next = time.Date(y, m, d, hour, minute, 0, 0, time.Local)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case EveryWeek:
+		next = time.Date(y, m, d, hour, minute, 0, 0, time.Local)
+		next = next.AddDate(0, 0, (s.Weekday-int(next.Weekday())+7)%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+	case EveryMonth:
+		next = time.Date(y, m, s.Day, hour, minute, 0, 0, time.Local)
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+	}
+	return next, nil
+}
+
+// schedulesPath 返回定时扫描文件路径
+func schedulesPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "schedules.json"), nil
+}
+
+// readSchedules 读取定时扫描，文件不存在时返回空列表
+func readSchedules() ([]ScheduledScan, error) {
+	path, err := schedulesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取定时扫描: %w", err)
+	}
+
+	var sf schedulesFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("定时扫描文件格式错误: %w", err)
+	}
+	if sf.Version > schedulesFileVersion {
+		return nil, fmt.Errorf("不支持的定时扫描文件版本: %d", sf.Version)
+	}
+	return sf.Schedules, nil
+}
+
+// writeSchedules 写入定时扫描
+func writeSchedules(schedules []ScheduledScan) error {
+	path, err := schedulesPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(schedulesFile{Version: schedulesFileVersion, Schedules: schedules}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("无法保存定时扫描: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("无法保存定时扫描: %w", err)
+	}
+	return nil
+}
+
+// ListScheduledScans 列出定时扫描
+func ListScheduledScans() ([]ScheduledScan, error) {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+	return readSchedules()
+}
+
+// SaveScheduledScan 新建或修改定时扫描（ID 为空时新建），返回保存后的定时扫描
+func SaveScheduledScan(sc ScheduledScan) (*ScheduledScan, error) {
+	sc.Name = strings.TrimSpace(sc.Name)
+	if sc.Name == "" {
+		return nil, errors.New("定时扫描名称不能为空")
+	}
+	if len(scanRoots(sc.Options)) == 0 {
+		return nil, errors.New("未指定扫描路径")
+	}
+	next, err := sc.Schedule.Next(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sc.Options.FS = nil
+
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	schedules, err := readSchedules()
+	if err != nil {
+		return nil, err
+	}
+	for i, existing := range schedules {
+		if existing.ID != sc.ID {
+			continue
+		}
+		// 运行状态由调度器维护，执行时间不变时保留原来的下次执行时间
+		sc.LastRun, sc.LastRecordID, sc.LastError = existing.LastRun, existing.LastRecordID, existing.LastError
+		sc.NextRun = next
+		if existing.Schedule == sc.Schedule && existing.NextRun.After(time.Now()) {
+			sc.NextRun = existing.NextRun
+		}
+		schedules[i] = sc
+		if err := writeSchedules(schedules); err != nil {
+			return nil, err
+		}
+		return &sc, nil
+	}
+
+	if sc.ID == "" {
+		sc.ID = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	sc.NextRun = next
+	sc.LastRun, sc.LastRecordID, sc.LastError = time.Time{}, "", ""
+	if err := writeSchedules(append(schedules, sc)); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// DeleteScheduledScan 删除定时扫描（已保存的扫描历史不受影响）
+func DeleteScheduledScan(id string) error {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	schedules, err := readSchedules()
+	if err != nil {
+		return err
+	}
+	kept := schedules[:0]
+	for _, sc := range schedules {
+		if sc.ID != id {
+			kept = append(kept, sc)
+		}
+	}
+	return writeSchedules(kept)
+}
+
+// updateScheduledScan 修改已保存的定时扫描，定时扫描已被删除时不做任何操作
+func updateScheduledScan(id string, update func(sc *ScheduledScan)) error {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	schedules, err := readSchedules()
+	if err != nil {
+		return err
+	}
+	for i := range schedules {
+		if schedules[i].ID == id {
+			update(&schedules[i])
+			return writeSchedules(schedules)
+		}
+	}
+	return nil
+}
+
+// Scheduler 在后台按时执行定时扫描，同一时间只执行一个扫描
+type Scheduler struct {
+	mu     sync.Mutex
+	notify func(alert ScanAlert)
+	stop   chan struct{}
+	queue  chan string
+}
+
+// NewScheduler 创建调度器，notify 在定时扫描完成且发现新问题（或扫描失败）时调用，首次扫描不提醒
+func NewScheduler(notify func(alert ScanAlert)) *Scheduler {
+	return &Scheduler{notify: notify, queue: make(chan string, 16)}
+}
+
+// Start 启动调度器，程序启动时已错过的定时扫描会立即补执行一次
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go s.loop(s.stop)
+}
+
+// Stop 停止调度器，正在执行的扫描会继续完成
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// RunNow 立即执行定时扫描（不影响下次执行时间）
+func (s *Scheduler) RunNow(id string) error {
+	schedules, err := ListScheduledScans()
+	if err != nil {
+		return err
+	}
+	for _, sc := range schedules {
+		if sc.ID == id {
+			select {
+			case s.queue <- id:
+				return nil
+			default:
+				return errors.New("等待执行的定时扫描过多，请稍后再试")
+			}
+		}
+	}
+	return errors.New("定时扫描不存在")
+}
+
+func (s *Scheduler) loop(stop chan struct{}) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	s.runDue(time.Now())
+	for {
+		select {
+		case <-stop:
+			return
+		case id := <-s.queue:
+			schedules, err := ListScheduledScans()
+			if err != nil {
+				continue
+			}
+			for _, sc := range schedules {
+				if sc.ID == id {
+					s.run(sc, false)
+				}
+			}
+		case now := <-ticker.C:
+			s.runDue(now)
+		}
+	}
+}
+
+// runDue 依次执行到期的定时扫描
+func (s *Scheduler) runDue(now time.Time) {
+	schedules, err := ListScheduledScans()
+	if err != nil {
+		return
+	}
+	for _, sc := range schedules {
+		if sc.Enabled && !sc.NextRun.IsZero() && !sc.NextRun.After(now) {
+			s.run(sc, true)
+		}
+	}
+}
+
+// run 执行一次定时扫描，保存扫描历史并与上次的结果比较
+// 为了发现加密和重复文件，定时扫描总是检测加密并计算文件哈希
+func (s *Scheduler) run(sc ScheduledScan, scheduled bool) {
+	options := sc.Options
+	options.ComputeHash = true
+	options.DetectEncryption = true
+
+	alert := ScanAlert{ScheduleID: sc.ID, Name: sc.Name, Counts: map[string]int{}}
+	result, err := NewScanner().Scan(options)
+	var record *ScanRecord
+	if err == nil {
+		// 定时扫描只保存历史记录，不再读取结果中的文件
+		defer result.Release()
+		record, err = saveScanHistory(options, result, sc.ID)
+	}
+	alert.Time = time.Now()
+
+	if err != nil {
+		alert.Error = err.Error()
+	} else {
+		alert.RecordID = record.ID
+		// 首次扫描（或上次的历史记录已被删除）只作为之后比较的基准，不提醒已有的问题
+		if sc.LastRecordID != "" {
+			if _, previous, err := LoadScanHistory(sc.LastRecordID); err == nil {
+				alert.Counts, alert.Samples = newProblems(previous, result.Files)
+			}
+		}
+	}
+
+	updateScheduledScan(sc.ID, func(saved *ScheduledScan) {
+		saved.LastRun = alert.Time
+		saved.LastError = alert.Error
+		if record != nil {
+			saved.LastRecordID = record.ID
+		}
+		if scheduled {
+			if next, err := saved.Schedule.Next(alert.Time); err == nil {
+				saved.NextRun = next
+			}
+		}
+	})
+
+	if s.notify != nil && (alert.Error != "" || len(alert.Samples) > 0) {
+		s.notify(alert)
+	}
+}
+
+// newProblems 找出本次扫描中新出现的无效、加密和重复文件
+func newProblems(previous, current []FileInfo) (map[string]int, []AlertFile) {
+	type state struct{ invalid, encrypted bool }
+	before := make(map[string]state, len(previous))
+	for _, f := range previous {
+		before[f.Path] = state{invalid: !f.IsValid, encrypted: f.Encrypted}
+	}
+	duplicatedBefore := duplicatePaths(previous)
+	duplicatedNow := duplicatePaths(current)
+
+	counts := map[string]int{}
+	var samples []AlertFile
+	add := func(kind, path string) {
+		counts[kind]++
+		if counts[kind] <= maxAlertSamples {
+			samples = append(samples, AlertFile{Kind: kind, Path: path})
+		}
+	}
+	for _, f := range current {
+		old := before[f.Path]
+		if !f.IsValid && !old.invalid {
+			add(AlertInvalid, f.Path)
+		}
+		if f.Encrypted && !old.encrypted {
+			add(AlertEncrypted, f.Path)
+		}
+		if duplicatedNow[f.Path] && !duplicatedBefore[f.Path] {
+			add(AlertDuplicate, f.Path)
+		}
+	}
+	return counts, samples
+}
+
+// duplicatePaths 返回与其他文件内容相同（哈希相同）的文件路径
+func duplicatePaths(files []FileInfo) map[string]bool {
+	byHash := make(map[string][]string)
+	for _, f := range files {
+		if f.Hash != "" && f.Size > 0 {
+			byHash[f.Hash] = append(byHash[f.Hash], f.Path)
+		}
+	}
+	paths := make(map[string]bool)
+	for _, group := range byHash {
+		if len(group) > 1 {
+			for _, p := range group {
+				paths[p] = true
+			}
+		}
+	}
+	return paths
+}
+
+// Summary 返回提醒的简要说明，用于桌面通知
+func (a ScanAlert) Summary() string {
+	if a.Error != "" {
+		return "扫描失败: " + a.Error
+	}
+	var parts []string
+	for _, kind := range []struct{ key, label string }{
+		{AlertInvalid, "无效文件"},
+		{AlertEncrypted, "加密文件"},
+		{AlertDuplicate, "重复文件"},
+	} {
+		if n := a.Counts[kind.key]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d 个", kind.label, n))
+		}
+	}
+	if len(parts) == 0 {
+		return "未发现新问题"
+	}
+	return "发现新的" + strings.Join(parts, "，")
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.Local)
+	}
+	now := at(time.March, 4, 10, 30) // 星期三
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		after    time.Time
+		want     time.Time
+	}{
+		{"hourly later this hour", Schedule{Every: EveryHour, Time: "00:45"}, now, at(time.March, 4, 10, 45)},
+		{"hourly next hour", Schedule{Every: EveryHour, Time: "00:15"}, now, at(time.March, 4, 11, 15)},
+		{"hourly at the same minute", Schedule{Every: EveryHour, Time: "00:30"}, now, at(time.March, 4, 11, 30)},
+		{"daily today", Schedule{Every: EveryDay, Time: "23:59"}, now, at(time.March, 4, 23, 59)},
+		{"daily tomorrow", Schedule{Every: EveryDay, Time: "09:00"}, now, at(time.March, 5, 9, 0)},
+		{"daily at the same time", Schedule{Every: EveryDay, Time: "10:30"}, now, at(time.March, 5, 10, 30)},
+		{"weekly today", Schedule{Every: EveryWeek, Time: "11:00", Weekday: 3}, now, at(time.March, 4, 11, 0)},
+		{"weekly passed today", Schedule{Every: EveryWeek, Time: "10:30", Weekday: 3}, now, at(time.March, 11, 10, 30)},
+		{"weekly sunday", Schedule{Every: EveryWeek, Time: "08:00", Weekday: 0}, now, at(time.March, 8, 8, 0)},
+		{"weekly monday", Schedule{Every: EveryWeek, Time: "08:00", Weekday: 1}, now, at(time.March, 9, 8, 0)},
+		{"monthly this month", Schedule{Every: EveryMonth, Time: "12:00", Day: 28}, now, at(time.March, 28, 12, 0)},
+		{"monthly next month", Schedule{Every: EveryMonth, Time: "00:00", Day: 1}, now, at(time.April, 1, 0, 0)},
+		{"monthly next year", Schedule{Every: EveryMonth, Time: "06:00", Day: 28}, at(time.December, 31, 0, 0), time.Date(2027, time.January, 28, 6, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := tt.schedule.Next(tt.after)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: Next = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	invalid := []Schedule{
+		{Every: EveryDay, Time: "24:00"},
+		{Every: EveryDay, Time: "9"},
+		{Every: EveryWeek, Time: "08:00", Weekday: 7},
+		{Every: EveryMonth, Time: "08:00", Day: 29},
+		{Every: EveryMonth, Time: "08:00"},
+		{Every: "yearly", Time: "08:00"},
+	}
+	for _, s := range invalid {
+		if _, err := s.Next(now); err == nil {
+			t.Errorf("Next(%+v) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestScheduledScanBaseline(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.pdf", testPDF(0))
+	writeTestFile(t, dir, "broken.pdf", []byte("%PDF-1.4 truncated"))
+
+	saved, err := SaveScheduledScan(ScheduledScan{
+		Name:     "每日检查",
+		Enabled:  true,
+		Schedule: Schedule{Every: EveryDay, Time: "02:00"},
+		Options:  ScanOptions{RootPath: dir, ValidateFiles: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alerts []ScanAlert
+	scheduler := NewScheduler(func(alert ScanAlert) { alerts = append(alerts, alert) })
+	reload := func() ScheduledScan {
+		t.Helper()
+		schedules, err := ListScheduledScans()
+		if err != nil || len(schedules) != 1 {
+			t.Fatalf("ListScheduledScans = %+v, %v", schedules, err)
+		}
+		return schedules[0]
+	}
+
+	// 首次扫描只记录基准，已有的无效文件不提醒
+	scheduler.run(*saved, true)
+	sc := reload()
+	if len(alerts) != 0 {
+		t.Fatalf("first run alerted %+v", alerts)
+	}
+	if sc.LastRecordID == "" || sc.LastError != "" || !sc.NextRun.After(time.Now()) {
+		t.Fatalf("after the first run: %+v", sc)
+	}
+
+	// 之后的扫描只提醒新出现的问题
+	writeTestFile(t, dir, "b.pdf", testPDF(8))
+	scheduler.run(sc, false)
+	if len(alerts) != 0 {
+		t.Fatalf("run without new problems alerted %+v", alerts)
+	}
+	newBroken := writeTestFile(t, dir, "new.pdf", []byte("%PDF-1.4 truncated too"))
+	scheduler.run(reload(), false)
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	if alert := alerts[0]; alert.Counts[AlertInvalid] != 1 || len(alert.Samples) != 1 || alert.Samples[0].Path != newBroken {
+		t.Errorf("alert = %+v, want only %s", alert, newBroken)
+	}
+}
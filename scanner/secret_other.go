@@ -0,0 +1,103 @@
+//go:build !windows
+
+package scanner
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 凭据加密密钥的长度（AES-256）
+const secretKeySize = 32
+
+// secretKey 读取凭据加密密钥，不存在时生成。密钥与凭据分开保存且只有当前用户可读，
+// 凭据文件被单独复制（备份、同步）时其中的密码无法还原
+func secretKey() ([]byte, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "credentials.key")
+	key, err := os.ReadFile(path)
+	if err == nil && len(key) == secretKeySize {
+		return key, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("无法读取凭据密钥: %w", err)
+	}
+	if err == nil {
+		return nil, errors.New("凭据密钥文件已损坏")
+	}
+
+	key = make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("无法生成凭据密钥: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("无法保存凭据密钥: %w", err)
+	}
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("无法保存凭据密钥: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("无法保存凭据密钥: %w", err)
+	}
+	return key, nil
+}
+
+// secretAEAD 返回使用凭据密钥的 AES-GCM
+func secretAEAD() (cipher.AEAD, error) {
+	key, err := secretKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// protectSecret 使用 AES-256-GCM 加密，结果为随机 nonce 加密文
+func protectSecret(plain []byte) ([]byte, error) {
+	if len(plain) == 0 {
+		return nil, nil
+	}
+	aead, err := secretAEAD()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("无法加密凭据: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// unprotectSecret 解密 protectSecret 的结果
+func unprotectSecret(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	aead, err := secretAEAD()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("无法解密凭据: 数据不完整")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("无法解密凭据: %w", err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,271 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// BackendSFTP SFTP 后端
+const BackendSFTP = "sftp"
+
+const sftpDialTimeout = 15 * time.Second
+
+func init() {
+	RegisterBackend(BackendSFTP, mountSFTP)
+}
+
+// sftpFS 基于 SFTP 连接的只读文件系统，fs 路径对应服务器上的绝对路径
+type sftpFS struct {
+	client *sftp.Client
+	conn   *ssh.Client
+}
+
+// mountSFTP 挂载 sftp://[user[:password]@]host[:port]/path
+func mountSFTP(root string) (*Mount, error) {
+	u, err := url.Parse(root)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("无效的 SFTP 地址: %s", root)
+	}
+	cred, _ := findCredential(u)
+
+	username := cred.Username
+	if u.User != nil && u.User.Username() != "" {
+		username = u.User.Username()
+	}
+	if username == "" {
+		if current, err := user.Current(); err == nil {
+			username = current.Username
+		}
+	}
+
+	var auth []ssh.AuthMethod
+	password := cred.Password
+	if u.User != nil {
+		if p, ok := u.User.Password(); ok {
+			password = p
+		}
+		u.User = url.User(u.User.Username()) // 结果路径中不保留密码
+	}
+	if signers := sftpSigners(cred.KeyFile); len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+	if password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("没有可用的 SFTP 凭据，请先保存密码或私钥")
+	}
+
+	conn, err := ssh.Dial("tcp", sftpAddr(u), &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: sftpHostKeyCallback(cred.HostKey),
+		Timeout:         sftpDialTimeout,
+	})
+	if err != nil {
+		if errors.Is(err, ErrUnknownHostKey) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("无法连接 SFTP 服务器: %w", err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("无法启动 SFTP 会话: %w", err)
+	}
+
+	fsys := &sftpFS{client: client, conn: conn}
+	rootDir := strings.Trim(u.Path, "/")
+	if rootDir == "" {
+		rootDir = "."
+	}
+	info, err := fsys.Stat(rootDir)
+	if err != nil {
+		fsys.Close()
+		return nil, err
+	}
+	if !info.IsDir() {
+		fsys.Close()
+		return nil, fmt.Errorf("SFTP 路径不是目录: %s", root)
+	}
+
+	prefix := (&url.URL{Scheme: BackendSFTP, User: u.User, Host: u.Host}).String() + "/"
+	return &Mount{FS: fsys, Root: rootDir, Prefix: prefix, Closer: fsys}, nil
+}
+
+// sftpSigners 加载私钥：优先使用凭据中指定的文件，否则尝试 ~/.ssh 下的默认私钥（跳过有口令的私钥）
+func sftpSigners(keyFile string) []ssh.Signer {
+	files := []string{keyFile}
+	if keyFile == "" {
+		files = nil
+		if home, err := os.UserHomeDir(); err == nil {
+			for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+				files = append(files, filepath.Join(home, ".ssh", name))
+			}
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if signer, err := ssh.ParsePrivateKey(data); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	return signers
+}
+
+// ErrUnknownHostKey SFTP 服务器的主机公钥还没有被用户确认
+var ErrUnknownHostKey = errors.New("尚未确认 SFTP 服务器的主机公钥，请先核对指纹")
+
+// HostKeyInfo SFTP 服务器的主机公钥，供界面在首次连接时让用户核对
+type HostKeyInfo struct {
+	URL         string `json:"url"`
+	KeyType     string `json:"keyType"`     // 公钥类型，如 ssh-ed25519
+	Fingerprint string `json:"fingerprint"` // SHA256 指纹
+	Known       string `json:"known"`       // 已确认的指纹，为空表示首次连接
+}
+
+// Trusted 服务器的公钥是否与已确认的一致
+func (h *HostKeyInfo) Trusted() bool {
+	return h.Known != "" && h.Known == h.Fingerprint
+}
+
+// errHostKeyProbed 获取到主机公钥后中止连接
+var errHostKeyProbed = errors.New("已获取主机公钥")
+
+// sftpAddr 返回连接地址，未指定端口时使用 22
+func sftpAddr(u *url.URL) string {
+	if u.Port() == "" {
+		return net.JoinHostPort(u.Hostname(), "22")
+	}
+	return u.Host
+}
+
+// ProbeHostKey 连接 SFTP 服务器获取主机公钥（不登录），界面据此让用户在首次连接或公钥变化时确认
+func ProbeHostKey(rawURL string) (*HostKeyInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || !strings.EqualFold(u.Scheme, BackendSFTP) {
+		return nil, fmt.Errorf("无效的 SFTP 地址: %s", rawURL)
+	}
+	info := &HostKeyInfo{URL: rawURL}
+	if cred, ok := findCredential(u); ok {
+		info.Known = cred.HostKey
+	}
+
+	conn, err := ssh.Dial("tcp", sftpAddr(u), &ssh.ClientConfig{
+		User: "probe",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			info.KeyType = key.Type()
+			info.Fingerprint = ssh.FingerprintSHA256(key)
+			return errHostKeyProbed
+		},
+		Timeout: sftpDialTimeout,
+	})
+	if err == nil {
+		conn.Close()
+	}
+	if info.Fingerprint == "" {
+		return nil, fmt.Errorf("无法连接 SFTP 服务器: %w", err)
+	}
+	return info, nil
+}
+
+// TrustHostKey 记录用户确认的主机公钥指纹，之后的连接只接受该公钥
+func TrustHostKey(rawURL, fingerprint string) error {
+	if !strings.HasPrefix(fingerprint, "SHA256:") {
+		return fmt.Errorf("无效的主机公钥指纹: %s", fingerprint)
+	}
+	return recordHostKey(rawURL, fingerprint)
+}
+
+// sftpHostKeyCallback 校验主机公钥：必须与用户确认过的指纹一致，未确认时拒绝连接
+func sftpHostKeyCallback(known string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+		if known == "" {
+			return fmt.Errorf("%w（%s %s）", ErrUnknownHostKey, key.Type(), fingerprint)
+		}
+		if known != fingerprint {
+			return fmt.Errorf("主机公钥与记录不一致（%s），可能存在中间人攻击", fingerprint)
+		}
+		return nil
+	}
+}
+
+// remotePath 把 fs 路径转换为服务器上的绝对路径
+func (s *sftpFS) remotePath(name string) string {
+	if name == "." {
+		return "/"
+	}
+	return "/" + name
+}
+
+// Open 实现 fs.FS，返回的文件支持 ReadAt 和 Seek
+func (s *sftpFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &remoteDir{name: name, info: info, list: func() ([]fs.DirEntry, error) { return s.ReadDir(name) }}, nil
+	}
+	f, err := s.client.Open(s.remotePath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f, nil
+}
+
+// Stat 实现 fs.StatFS
+func (s *sftpFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := s.client.Stat(s.remotePath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return info, nil
+}
+
+// ReadDir 实现 fs.ReadDirFS，结果按名称排序
+func (s *sftpFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	infos, err := s.client.ReadDir(s.remotePath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Close 关闭 SFTP 会话和 SSH 连接
+func (s *sftpFS) Close() error {
+	s.client.Close()
+	return s.conn.Close()
+}
@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// startSFTP 启动使用内存文件系统的 SFTP 服务器（用户 alice，密码 open-sesame），返回地址和主机密钥
+func startSFTP(t *testing.T, files map[string][]byte) (string, ssh.Signer) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "alice" && string(pass) == "open-sesame" {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	handlers := sftp.InMemHandler()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTPConn(conn, config, handlers)
+		}
+	}()
+
+	// 通过 SFTP 写入测试文件
+	conn, err := ssh.Dial("tcp", ln.Addr().String(), &ssh.ClientConfig{
+		User:            "alice",
+		Auth:            []ssh.AuthMethod{ssh.Password("open-sesame")},
+		HostKeyCallback: ssh.FixedHostKey(hostKey.PublicKey()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for name, data := range files {
+		if err := client.MkdirAll(path.Dir("/" + name)); err != nil {
+			t.Fatal(err)
+		}
+		f, err := client.Create("/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	return ln.Addr().String(), hostKey
+}
+
+// serveSFTPConn 处理一个 SSH 连接，只接受 sftp 子系统
+func serveSFTPConn(conn net.Conn, config *ssh.ServerConfig, handlers sftp.Handlers) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		ch, requests, err := newChan.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 &&
+					string(req.Payload[4:4+binary.BigEndian.Uint32(req.Payload)]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server := sftp.NewRequestServer(ch, handlers)
+					server.Serve()
+					server.Close()
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestSFTPScan(t *testing.T) {
+	isolateConfig(t)
+	addr, hostKey := startSFTP(t, map[string][]byte{
+		"docs/a.pdf":          testPDF(32 << 10),
+		"docs/2024/b.csv":     []byte("name,amount\nx,1\n"),
+		"docs/2024/bad.pdf":   []byte("%PDF-1.4 truncated"),
+		"docs/readme.txt":     []byte("skip"),
+		"elsewhere/c.pdf":     testPDF(0),
+		"docs/2024/empty.xls": nil,
+	})
+	root := "sftp://alice@" + addr + "/docs"
+	fingerprint := ssh.FingerprintSHA256(hostKey.PublicKey())
+
+	if err := SaveCredential(Credential{URL: root, Password: "open-sesame"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未确认主机公钥时拒绝连接，也不自动记录
+	if _, err := mountSFTP(root); !errors.Is(err, ErrUnknownHostKey) || !strings.Contains(err.Error(), fingerprint) {
+		t.Fatalf("mount with unknown host key: err = %v, want ErrUnknownHostKey with %s", err, fingerprint)
+	}
+	info, err := ProbeHostKey(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Fingerprint != fingerprint || info.KeyType != ssh.KeyAlgoED25519 || info.Known != "" || info.Trusted() {
+		t.Fatalf("ProbeHostKey = %+v, want unconfirmed %s", info, fingerprint)
+	}
+
+	if err := TrustHostKey(root, info.Fingerprint); err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewScanner().Scan(ScanOptions{RootPath: root, ValidateFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Release()
+	got := map[string]bool{}
+	for _, f := range result.Files {
+		got[strings.TrimPrefix(f.Path, "sftp://alice@"+addr+"/")] = f.IsValid
+	}
+	want := map[string]bool{
+		"docs/a.pdf":          true,
+		"docs/2024/b.csv":     true,
+		"docs/2024/bad.pdf":   false,
+		"docs/2024/empty.xls": false,
+	}
+	if len(got) != len(want) {
+		t.Errorf("scanned %v, want %v", got, want)
+	}
+	for name, valid := range want {
+		if v, ok := got[name]; !ok || v != valid {
+			t.Errorf("%s: found %v, valid %v, want valid %v", name, ok, v, valid)
+		}
+	}
+	if info, err := ProbeHostKey(root); err != nil || !info.Trusted() {
+		t.Errorf("ProbeHostKey after trust = %+v, %v", info, err)
+	}
+
+	// 公钥与确认的不一致时拒绝连接
+	if err := TrustHostKey(root, "SHA256:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mountSFTP(root); err == nil || errors.Is(err, ErrUnknownHostKey) {
+		t.Errorf("mount with changed host key: err = %v, want mismatch error", err)
+	}
+	if err := TrustHostKey(root, "not-a-fingerprint"); err == nil {
+		t.Error("TrustHostKey accepted an invalid fingerprint")
+	}
+}
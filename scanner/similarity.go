@@ -0,0 +1,352 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 相似度算法
+const (
+	SimilarityMinHash = "minhash" // MinHash 估算文本分片集合的 Jaccard 相似度
+	SimilaritySimHash = "simhash" // SimHash 指纹的汉明距离，对少量分散的修改更宽容
+)
+
+// MinHash 参数：签名长度 = lshBands * lshRows
+const (
+	minHashSize = 128
+	lshBands    = 32
+	lshRows     = minHashSize / lshBands
+	simHashBits = 64
+
+	defaultShingleSize = 5
+	defaultThreshold   = 0.8
+	minTextRunes       = 20        // 文本过短时无法可靠比较
+	similarityTextSize = 256 << 10 // 每个文件最多使用 256KB 文本计算签名
+)
+
+// SimilarityOptions 近似重复检测选项
+type SimilarityOptions struct {
+	Method      string  `json:"method"`      // 相似度算法：minhash（默认）或 simhash
+	Threshold   float64 `json:"threshold"`   // 相似度阈值（0-1），默认0.8
+	ShingleSize int     `json:"shingleSize"` // 文本分片长度（字符数），默认5
+}
+
+// SimilarFile 相似分组中的文件
+type SimilarFile struct {
+	FileInfo
+	Similarity float64 `json:"similarity"` // 与分组代表文件的相似度
+}
+
+// SimilarGroup 相似文件分组
+type SimilarGroup struct {
+	Files []SimilarFile `json:"files"` // 第一个文件为代表文件（最近修改的版本），其他文件都与它达到相似度阈值
+	Score float64       `json:"score"` // 其他文件与代表文件的平均相似度
+}
+
+// SimilarityResult 近似重复检测结果
+type SimilarityResult struct {
+	Groups       []SimilarGroup `json:"groups"`
+	Analyzed     int            `json:"analyzed"`     // 成功提取文本的文件数
+	SkippedFiles []string       `json:"skippedFiles"` // 无法提取文本或文本过短的文件
+	ScanTime     float64        `json:"scanTime"`     // 耗时（秒）
+}
+
+// docSignature 文档的签名，只计算所选算法使用的一种
+type docSignature struct {
+	file    FileInfo
+	minHash [minHashSize]uint64
+	simHash uint64
+}
+
+// minHashSeeds 每个哈希函数使用的随机种子（固定值，保证结果可复现）
+var minHashSeeds = func() [minHashSize]uint64 {
+	var seeds [minHashSize]uint64
+	state := uint64(0x9E3779B97F4A7C15)
+	for i := range seeds {
+		state = mix64(state + uint64(i))
+		seeds[i] = state
+	}
+	return seeds
+}()
+
+// FindSimilar 基于文本分片和 MinHash（或 SimHash）查找内容相似的文档
+// 不同格式的文件（如 .docx 与导出的 .pdf）会按提取出的文本进行比较
+func FindSimilar(files []FileInfo, options SimilarityOptions) (*SimilarityResult, error) {
+	startTime := time.Now()
+
+	switch options.Method {
+	case "":
+		options.Method = SimilarityMinHash
+	case SimilarityMinHash, SimilaritySimHash:
+	default:
+		return nil, fmt.Errorf("未知的相似度算法: %s", options.Method)
+	}
+
+	if options.Threshold <= 0 || options.Threshold > 1 {
+		options.Threshold = defaultThreshold
+	}
+	if options.ShingleSize <= 0 {
+		options.ShingleSize = defaultShingleSize
+	}
+
+	result := &SimilarityResult{
+		Groups:       make([]SimilarGroup, 0),
+		SkippedFiles: make([]string, 0),
+	}
+
+	// 使用工作池并发提取文本并计算签名
+	workerCount := 4
+	fileChan := make(chan FileInfo)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var docs []docSignature
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range fileChan {
+				doc, ok := computeSignature(file, options.ShingleSize, options.Method)
+				mu.Lock()
+				if ok {
+					docs = append(docs, doc)
+				} else {
+					result.SkippedFiles = append(result.SkippedFiles, file.Path)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, file := range files {
+		if !file.IsValid {
+			mu.Lock()
+			result.SkippedFiles = append(result.SkippedFiles, file.Path)
+			mu.Unlock()
+			continue
+		}
+		fileChan <- file
+	}
+	close(fileChan)
+	wg.Wait()
+
+	// 按路径排序，保证分组结果稳定
+	sort.Slice(docs, func(i, j int) bool { return docs[i].file.Path < docs[j].file.Path })
+	result.Analyzed = len(docs)
+	result.Groups = groupSimilar(docs, options.Threshold, options.Method)
+	result.ScanTime = time.Since(startTime).Seconds()
+
+	return result, nil
+}
+
+// computeSignature 提取文件文本并计算签名，压缩包内的条目和已挂载存储后端上的文件同样可以读取
+func computeSignature(file FileInfo, shingleSize int, method string) (docSignature, bool) {
+	doc := docSignature{file: file}
+
+	r, err := openDocument(file.Path)
+	if err != nil {
+		return doc, false
+	}
+	text, err := extractDocumentText(r, file.Path, similarityTextSize)
+	r.Close()
+	if err != nil {
+		return doc, false
+	}
+
+	runes := normalizeText(text)
+	if len(runes) < minTextRunes || len(runes) < shingleSize {
+		return doc, false
+	}
+
+	shingles := make([]uint64, 0, len(runes)-shingleSize+1)
+	buf := make([]byte, 4*shingleSize)
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		for j, r := range runes[i : i+shingleSize] {
+			binary.LittleEndian.PutUint32(buf[4*j:], uint32(r))
+		}
+		h := fnv.New64a()
+		h.Write(buf)
+		shingles = append(shingles, h.Sum64())
+	}
+
+	if method == SimilaritySimHash {
+		doc.simHash = simHash(shingles)
+	} else {
+		doc.minHash = minHash(shingles)
+	}
+	return doc, true
+}
+
+// minHash 计算文本分片的 MinHash 签名
+func minHash(shingles []uint64) [minHashSize]uint64 {
+	var sig [minHashSize]uint64
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, shingle := range shingles {
+		for k, seed := range minHashSeeds {
+			if v := mix64(shingle ^ seed); v < sig[k] {
+				sig[k] = v
+			}
+		}
+	}
+	return sig
+}
+
+// simHash 计算文本分片的 SimHash 指纹：每一位取所有分片哈希在该位上的多数值
+// 重复出现的分片按出现次数计入权重
+func simHash(shingles []uint64) uint64 {
+	var weights [simHashBits]int
+	for _, shingle := range shingles {
+		h := mix64(shingle)
+		for b := range weights {
+			if h&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	var fingerprint uint64
+	for b, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << b
+		}
+	}
+	return fingerprint
+}
+
+// normalizeText 规范化文本：转小写并去除空白和标点，使不同格式导出的同一文档得到相同的字符序列
+func normalizeText(text string) []rune {
+	runes := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, unicode.ToLower(r))
+		}
+	}
+	return runes
+}
+
+// mix64 64位整数混淆函数（SplitMix64）
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
+
+// estimateSimilarity 根据 MinHash 签名估算 Jaccard 相似度
+func estimateSimilarity(a, b *[minHashSize]uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / minHashSize
+}
+
+// simHashSimilarity 根据 SimHash 指纹的汉明距离计算相似度
+func simHashSimilarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/simHashBits
+}
+
+// similarity 按所选算法计算两个文档的相似度
+func (d *docSignature) similarity(other *docSignature, method string) float64 {
+	if method == SimilaritySimHash {
+		return simHashSimilarity(d.simHash, other.simHash)
+	}
+	return estimateSimilarity(&d.minHash, &other.minHash)
+}
+
+// groupSimilar 按修改时间从新到旧依次选出代表文件，尚未分组且与代表文件相似度达到阈值的文档加入该组。
+// 每个文件只与代表文件比较，不会因为 A 像 B、B 像 C 就把并不相似的 A 和 C 连成一组
+func groupSimilar(docs []docSignature, threshold float64, method string) []SimilarGroup {
+	order := make([]int, len(docs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return docs[order[i]].file.ModTime.After(docs[order[j]].file.ModTime)
+	})
+
+	candidates := similarCandidates(docs, method)
+	grouped := make([]bool, len(docs))
+	groups := make([]SimilarGroup, 0)
+	for _, rep := range order {
+		if grouped[rep] {
+			continue
+		}
+		group := SimilarGroup{Files: []SimilarFile{{FileInfo: docs[rep].file, Similarity: 1}}}
+		total := 0.0
+		for _, idx := range order {
+			if idx == rep || grouped[idx] || (candidates != nil && !candidates[rep][idx]) {
+				continue
+			}
+			if similarity := docs[rep].similarity(&docs[idx], method); similarity >= threshold {
+				grouped[idx] = true
+				group.Files = append(group.Files, SimilarFile{FileInfo: docs[idx].file, Similarity: similarity})
+				total += similarity
+			}
+		}
+		if len(group.Files) < 2 {
+			continue
+		}
+		grouped[rep] = true
+		group.Score = total / float64(len(group.Files)-1)
+		groups = append(groups, group)
+	}
+
+	// 文件多的分组在前，相同时按相似度降序
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Files) != len(groups[j].Files) {
+			return len(groups[i].Files) > len(groups[j].Files)
+		}
+		if groups[i].Score != groups[j].Score {
+			return groups[i].Score > groups[j].Score
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+
+	return groups
+}
+
+// similarCandidates 通过 LSH 分桶找出 MinHash 签名任一分段完全相同的候选对。
+// SimHash 指纹只有 64 位，直接与每个代表文件比较汉明距离，返回 nil 表示所有文档都是候选
+func similarCandidates(docs []docSignature, method string) []map[int]bool {
+	if method == SimilaritySimHash {
+		return nil
+	}
+	candidates := make([]map[int]bool, len(docs))
+	for i := range candidates {
+		candidates[i] = make(map[int]bool)
+	}
+	for band := 0; band < lshBands; band++ {
+		buckets := make(map[uint64][]int)
+		for i := range docs {
+			h := uint64(band)
+			for _, v := range docs[i].minHash[band*lshRows : (band+1)*lshRows] {
+				h = mix64(h ^ v)
+			}
+			buckets[h] = append(buckets[h], i)
+		}
+		for _, members := range buckets {
+			for _, x := range members {
+				for _, y := range members {
+					if x != y {
+						candidates[x][y] = true
+					}
+				}
+			}
+		}
+	}
+	return candidates
+}
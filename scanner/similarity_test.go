@@ -0,0 +1,169 @@
+package scanner
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// randomText 生成由常用词随机组成的文本
+func randomText(seed int64, words int) string {
+	vocabulary := []string{"合同", "变更", "付款", "甲方", "乙方", "期限", "违约", "责任", "条款", "通知", "金额", "交付", "验收", "保密", "争议", "解决"}
+	rng := rand.New(rand.NewSource(seed))
+	var b strings.Builder
+	for i := 0; i < words; i++ {
+		b.WriteString(vocabulary[rng.Intn(len(vocabulary))])
+		if i%12 == 11 {
+			b.WriteString("。\n")
+		}
+	}
+	return b.String()
+}
+
+func TestFindSimilar(t *testing.T) {
+	dir := t.TempDir()
+	text := randomText(1, 600)
+	// 修改正文中间的几个词
+	runes := []rune(text)
+	edited := string(runes[:300]) + "补充协议" + string(runes[306:])
+
+	now := time.Now()
+	file := func(name string, data []byte, age time.Duration) FileInfo {
+		return FileInfo{Path: writeTestFile(t, dir, name, data), Name: name, IsValid: true, ModTime: now.Add(-age)}
+	}
+	files := []FileInfo{
+		file("合同.docx", zipBytes(t, map[string][]byte{"word/document.xml": []byte(wordXML(text))}), 3*time.Hour),
+		file("合同.csv", []byte(text), 2*time.Hour),
+		file("合同_final.csv", []byte(edited), time.Hour),
+		file("其他.csv", []byte(randomText(2, 600)), 0),
+		file("短.csv", []byte("合同"), 0),
+	}
+
+	for _, method := range []string{SimilarityMinHash, SimilaritySimHash} {
+		result, err := FindSimilar(files, SimilarityOptions{Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Analyzed != 4 || len(result.SkippedFiles) != 1 {
+			t.Errorf("%s: analyzed %d, skipped %v", method, result.Analyzed, result.SkippedFiles)
+		}
+		if len(result.Groups) != 1 || len(result.Groups[0].Files) != 3 {
+			t.Fatalf("%s: groups = %+v, want one group of three versions", method, result.Groups)
+		}
+		group := result.Groups[0]
+		if group.Files[0].Name != "合同_final.csv" || group.Files[0].Similarity != 1 {
+			t.Errorf("%s: representative = %s, want the newest version", method, group.Files[0].Name)
+		}
+		for _, f := range group.Files[1:] {
+			if f.Similarity < defaultThreshold || f.Similarity > 1 {
+				t.Errorf("%s: %s similarity %.2f", method, f.Name, f.Similarity)
+			}
+		}
+	}
+
+	if _, err := FindSimilar(files, SimilarityOptions{Method: "lsh"}); err == nil {
+		t.Error("FindSimilar accepted an unknown method")
+	}
+}
+
+func TestFindSimilarMountedFiles(t *testing.T) {
+	isolateConfig(t)
+	text := randomText(3, 600)
+	runes := []rune(text)
+	edited := string(runes[:300]) + "补充协议" + string(runes[306:])
+
+	// 压缩包内的条目和内存文件系统上的文件都只能通过挂载读取
+	dir := t.TempDir()
+	writeTestFile(t, dir, "归档.zip", zipBytes(t, map[string][]byte{"合同.csv": []byte(text)}))
+	archived, err := NewScanner().Scan(ScanOptions{RootPath: dir, ScanArchives: true, ValidateFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archived.Release()
+	mem, err := NewScanner().Scan(ScanOptions{FS: fstest.MapFS{"合同_final.csv": {Data: []byte(edited)}}, RootPath: ".", ValidateFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Release()
+
+	var files []FileInfo
+	for _, f := range append(archived.Files, mem.Files...) {
+		if strings.HasSuffix(f.Name, ".csv") {
+			files = append(files, f)
+		}
+	}
+	if len(files) != 2 {
+		t.Fatalf("scanned files = %+v", files)
+	}
+	result, err := FindSimilar(files, SimilarityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Analyzed != 2 || len(result.SkippedFiles) != 0 || len(result.Groups) != 1 {
+		t.Errorf("analyzed %d, skipped %v, groups %+v", result.Analyzed, result.SkippedFiles, result.Groups)
+	}
+}
+
+// wordXML 把文本按行放入 Word 段落
+func wordXML(text string) string {
+	var b strings.Builder
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString("<w:p><w:r><w:t>" + line + "</w:t></w:r></w:p>")
+	}
+	b.WriteString("</w:body></w:document>")
+	return b.String()
+}
+
+func TestGroupSimilarIsNotTransitive(t *testing.T) {
+	// B 与 A、C 都相似，A 与 C 不相似：A 最新，是代表文件
+	now := time.Now()
+	a := docSignature{file: FileInfo{Path: "a", ModTime: now}}
+	b := docSignature{file: FileInfo{Path: "b", ModTime: now.Add(-time.Hour)}}
+	c := docSignature{file: FileInfo{Path: "c", ModTime: now.Add(-2 * time.Hour)}}
+
+	for i := range a.minHash {
+		a.minHash[i], b.minHash[i], c.minHash[i] = uint64(i), uint64(i), uint64(i)
+	}
+	for i := 0; i < 20; i++ {
+		b.minHash[i] += 1000
+		c.minHash[i] += 1000
+		c.minHash[20+i] += 1000
+	}
+	a.simHash, b.simHash, c.simHash = 0, 1<<10-1, 1<<20-1
+
+	tests := []struct {
+		method string
+		want   float64 // B 与 A 的相似度
+	}{
+		{SimilarityMinHash, 108.0 / 128},
+		{SimilaritySimHash, 54.0 / 64},
+	}
+	for _, tt := range tests {
+		if sim := b.similarity(&c, tt.method); sim < 0.8 {
+			t.Fatalf("%s: B and C similarity %.2f, fixture broken", tt.method, sim)
+		}
+		if sim := a.similarity(&c, tt.method); sim >= 0.8 {
+			t.Fatalf("%s: A and C similarity %.2f, fixture broken", tt.method, sim)
+		}
+
+		groups := groupSimilar([]docSignature{a, b, c}, 0.8, tt.method)
+		if len(groups) != 1 || len(groups[0].Files) != 2 {
+			t.Fatalf("%s: groups = %+v, want only A and B", tt.method, groups)
+		}
+		files := groups[0].Files
+		if files[0].Path != "a" || files[1].Path != "b" || files[1].Similarity != tt.want || groups[0].Score != tt.want {
+			t.Errorf("%s: group = %+v, score %.3f", tt.method, files, groups[0].Score)
+		}
+
+		// B 最新时，A 和 C 都与代表文件 B 相似
+		newest := b
+		newest.file.ModTime = now.Add(time.Hour)
+		groups = groupSimilar([]docSignature{a, newest, c}, 0.8, tt.method)
+		if len(groups) != 1 || len(groups[0].Files) != 3 || groups[0].Files[0].Path != "b" {
+			t.Errorf("%s: groups with B newest = %+v", tt.method, groups)
+		}
+	}
+}
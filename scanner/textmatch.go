@@ -0,0 +1,257 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// 文件名搜索模式
+const (
+	SearchContains = ""      // 包含（默认），同时支持拼音和拼音首字母
+	SearchFuzzy    = "fuzzy" // 模糊：允许字符不连续和少量错字，按匹配程度排序
+)
+
+// 匹配得分，越高越靠前
+const (
+	scoreExact       = 1000
+	scorePrefix      = 900
+	scoreSubstring   = 800
+	scorePinyin      = 700
+	scoreSubsequence = 500
+	scoreTypo        = 300
+)
+
+// FoldText 规范化文本用于比较：全角转半角、去掉重音符号、Unicode 大小写折叠
+func FoldText(s string) string {
+	t := transform.Chain(width.Fold, norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC, cases.Fold())
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		return strings.ToLower(s)
+	}
+	return folded
+}
+
+// NameMatcher 文件名匹配器，同一查询可并发用于多个文件名
+type NameMatcher struct {
+	query  []rune
+	text   string
+	fuzzy  bool
+	pinyin bool // 查询只含字母时尝试拼音匹配
+}
+
+// NewNameMatcher 创建文件名匹配器，查询为空时返回 nil（匹配所有文件）
+func NewNameMatcher(query, mode string) *NameMatcher {
+	folded := FoldText(strings.TrimSpace(query))
+	if folded == "" {
+		return nil
+	}
+	m := &NameMatcher{query: []rune(folded), text: folded, fuzzy: mode == SearchFuzzy, pinyin: true}
+	for _, r := range m.query {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			m.pinyin = false
+			break
+		}
+	}
+	return m
+}
+
+// Score 返回文件名的匹配得分，0 表示不匹配
+func (m *NameMatcher) Score(name string) int {
+	if m == nil {
+		return scoreExact
+	}
+	folded := FoldText(name)
+
+	// 去掉扩展名后完全相同也算精确匹配
+	base := folded
+	if i := strings.LastIndex(base, "."); i > 0 {
+		base = base[:i]
+	}
+	switch {
+	case folded == m.text || base == m.text:
+		return scoreExact
+	case strings.HasPrefix(folded, m.text):
+		return scorePrefix
+	}
+	if i := strings.Index(folded, m.text); i >= 0 {
+		// 越靠前得分越高
+		return scoreSubstring - min(len([]rune(folded[:i])), 99)
+	}
+
+	nameRunes := []rune(folded)
+	if m.pinyin && hasHan(nameRunes) {
+		if start, ok := matchPinyin(nameRunes, m.query); ok {
+			return scorePinyin - min(start, 99)
+		}
+	}
+
+	if !m.fuzzy {
+		return 0
+	}
+	if gaps, ok := subsequenceGaps(nameRunes, m.query); ok && len(m.query) >= 2 {
+		return scoreSubsequence - min(gaps, 99)
+	}
+	if maxTypos := typoBudget(len(m.query)); maxTypos > 0 {
+		if d := substringDistance(nameRunes, m.query); d <= maxTypos {
+			return scoreTypo - d*50
+		}
+	}
+	return 0
+}
+
+// Fuzzy 是否为模糊模式（结果需要按得分排序）
+func (m *NameMatcher) Fuzzy() bool {
+	return m != nil && m.fuzzy
+}
+
+// SortByScore 按得分从高到低排序，得分相同时保持原顺序
+func SortByScore(indexes []int, scores []int) {
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+}
+
+// typoBudget 允许的错字数：查询越长允许越多
+func typoBudget(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// subsequenceGaps 判断 query 的字符是否按顺序出现在 text 中，返回跨度中多出的字符数
+func subsequenceGaps(text, query []rune) (int, bool) {
+	best, found := 0, false
+	for start := range text {
+		if text[start] != query[0] {
+			continue
+		}
+		k := 0
+		end := start
+		for j := start; j < len(text) && k < len(query); j++ {
+			if text[j] == query[k] {
+				k++
+				end = j
+			}
+		}
+		if k < len(query) {
+			break
+		}
+		gaps := end - start + 1 - len(query)
+		if !found || gaps < best {
+			best, found = gaps, true
+		}
+	}
+	return best, found
+}
+
+// substringDistance 计算 query 与 text 中最接近的子串之间的编辑距离
+func substringDistance(text, query []rune) int {
+	prev := make([]int, len(text)+1) // 子串可以从任意位置开始，第一行全为 0
+	cur := make([]int, len(text)+1)
+	for i := 1; i <= len(query); i++ {
+		cur[0] = i
+		for j := 1; j <= len(text); j++ {
+			cost := 1
+			if query[i-1] == text[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j-1]+cost, prev[j]+1, cur[j-1]+1)
+		}
+		prev, cur = cur, prev
+	}
+	best := prev[0]
+	for _, d := range prev {
+		best = min(best, d)
+	}
+	return best
+}
+
+func hasHan(text []rune) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	pinyinMu    sync.RWMutex
+	pinyinCache = map[rune][]string{}
+	pinyinArgs  = func() pinyin.Args {
+		args := pinyin.NewArgs()
+		args.Heteronym = true // 多音字的所有读音都参与匹配
+		return args
+	}()
+)
+
+// syllables 返回汉字的所有拼音（不带声调）
+func syllables(r rune) []string {
+	pinyinMu.RLock()
+	s, ok := pinyinCache[r]
+	pinyinMu.RUnlock()
+	if ok {
+		return s
+	}
+	if result := pinyin.Pinyin(string(r), pinyinArgs); len(result) > 0 {
+		s = result[0]
+	}
+	pinyinMu.Lock()
+	pinyinCache[r] = s
+	pinyinMu.Unlock()
+	return s
+}
+
+// matchPinyin 判断 query 是否匹配 text 中连续的一段：汉字可以用完整拼音或拼音开头的若干字母（如首字母）匹配，
+// 其他字符需要原样匹配。htbg、hetongbg 都能匹配“合同变更”。返回匹配开始的位置
+func matchPinyin(text, query []rune) (int, bool) {
+	q := string(query)
+	failed := make(map[[2]int]bool)
+
+	var match func(u, k int) bool
+	match = func(u, k int) bool {
+		if k == len(q) {
+			return true
+		}
+		if u == len(text) || failed[[2]int{u, k}] {
+			return false
+		}
+		r := text[u]
+		if unicode.Is(unicode.Han, r) {
+			for _, syl := range syllables(r) {
+				for l := min(len(syl), len(q)-k); l >= 1; l-- {
+					if q[k:k+l] == syl[:l] && match(u+1, k+l) {
+						return true
+					}
+				}
+			}
+		} else if r < unicode.MaxASCII && byte(r) == q[k] {
+			if match(u+1, k+1) {
+				return true
+			}
+		}
+		failed[[2]int{u, k}] = true
+		return false
+	}
+
+	for start := range text {
+		if match(start, 0) {
+			return start, true
+		}
+	}
+	return 0, false
+}
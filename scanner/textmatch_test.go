@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPinyin(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		start int
+		ok    bool
+	}{
+		{"合同变更", "htbg", 0, true},
+		{"合同变更", "hetongbg", 0, true},
+		{"合同变更", "hetongbiangeng", 0, true},
+		{"合同变更", "htbiang", 0, true},
+		{"2024合同变更.docx", "bg", 6, true},
+		{"2024合同变更.docx", "bgdocx", -1, false}, // 中间的 . 需要原样匹配
+		{"2024合同变更.docx", "bg.docx", 6, true},
+		{"v2合同", "v2ht", 0, true},
+		{"银行流水", "yhls", 0, true},
+		{"银行流水", "xing", 1, true}, // 多音字的其他读音
+		{"合同变更", "htgb", -1, false},
+		{"合同变更", "hb", -1, false}, // 只能匹配连续的汉字
+		{"合同变更", "htbgx", -1, false},
+	}
+	for _, tt := range tests {
+		start, ok := matchPinyin([]rune(tt.text), []rune(tt.query))
+		if ok != tt.ok || ok && start != tt.start {
+			t.Errorf("matchPinyin(%q, %q) = %d, %v; want %d, %v", tt.text, tt.query, start, ok, tt.start, tt.ok)
+		}
+	}
+}
+
+func TestNameMatcher(t *testing.T) {
+	tests := []struct {
+		query string
+		mode  string
+		name  string
+		score int
+	}{
+		{"合同", SearchContains, "合同.docx", scoreExact},
+		{"合同变更", SearchContains, "合同变更通知.pdf", scorePrefix},
+		{"变更", SearchContains, "合同变更.pdf", scoreSubstring - 2},
+		{"htbg", SearchContains, "合同变更.docx", scorePinyin},
+		{"HTBG", SearchContains, "合同变更.docx", scorePinyin},
+		{"ｈｔｂｇ", SearchContains, "合同变更.docx", scorePinyin}, // 全角字母
+		{"bg", SearchContains, "合同变更.docx", scorePinyin - 2},
+		{"htbg", SearchContains, "采购清单.xlsx", 0},
+		{"ht2", SearchContains, "合同2.docx", 0}, // 含数字的查询不按拼音匹配
+		{"resume", SearchContains, "Résumé.docx", scoreExact},
+		{"REPORT", SearchContains, "ｒｅｐｏｒｔ_2024.pdf", scorePrefix},
+		{"rprt", SearchContains, "report.pdf", 0},
+		{"rprt", SearchFuzzy, "report.pdf", scoreSubsequence - 2},
+		{"reprot", SearchFuzzy, "report.pdf", scoreTypo - 100},
+		{"reprot", SearchContains, "report.pdf", 0},
+		{"xyz", SearchFuzzy, "report.pdf", 0},
+		{"htbg", SearchFuzzy, "合同变更.docx", scorePinyin},
+	}
+	for _, tt := range tests {
+		m := NewNameMatcher(tt.query, tt.mode)
+		if score := m.Score(tt.name); score != tt.score {
+			t.Errorf("NewNameMatcher(%q, %q).Score(%q) = %d, want %d", tt.query, tt.mode, tt.name, score, tt.score)
+		}
+		if m.Fuzzy() != (tt.mode == SearchFuzzy) {
+			t.Errorf("%q: Fuzzy() = %v", tt.query, m.Fuzzy())
+		}
+	}
+
+	// 空查询匹配所有文件
+	m := NewNameMatcher("  ", SearchFuzzy)
+	if m != nil || m.Score("任意.pdf") != scoreExact || m.Fuzzy() {
+		t.Errorf("empty query matcher = %+v", m)
+	}
+}
+
+func TestSortByScore(t *testing.T) {
+	scores := []int{scoreSubstring, scoreExact, scorePinyin, scoreExact, scoreSubstring}
+	indexes := []int{0, 1, 2, 3, 4}
+	SortByScore(indexes, scores)
+	if want := []int{1, 3, 0, 4, 2}; !reflect.DeepEqual(indexes, want) {
+		t.Errorf("SortByScore = %v, want %v", indexes, want)
+	}
+}
@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Windows 上通过 VisualBasic 的 FileSystem 把文件移到回收站，路径通过环境变量传入
+const windowsRecycleScript = `
+Add-Type -AssemblyName Microsoft.VisualBasic
+[Microsoft.VisualBasic.FileIO.FileSystem]::DeleteFile($env:DOCRADAR_PATH, 'OnlyErrorDialogs', 'SendToRecycleBin')
+`
+
+// MoveToTrash 把文件移到系统回收站（废纸篓），之后可以在系统中恢复。
+// 压缩包内和远程存储中的文件不能移到回收站
+func MoveToTrash(files []FileInfo) *FileActionResult {
+	result := &FileActionResult{FailedFiles: make([]string, 0)}
+	for _, file := range files {
+		p, err := filepath.Abs(file.Path)
+		if err == nil && !isLocalPath(file.Path) {
+			err = errors.New("只能删除本地文件")
+		}
+		if err == nil {
+			err = trashFile(p)
+		}
+		if err != nil {
+			result.Failed++
+			result.FailedFiles = append(result.FailedFiles, file.Path)
+			continue
+		}
+		result.Success++
+	}
+	return result
+}
+
+// trashFile 把单个文件移到回收站
+func trashFile(p string) error {
+	if _, err := os.Lstat(p); err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "windows":
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-WindowStyle", "Hidden", "-Command", windowsRecycleScript)
+		cmd.Env = append(os.Environ(), "DOCRADAR_PATH="+p)
+		return cmd.Run()
+	case "darwin":
+		// 路径作为参数传入，不需要转义
+		return exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", `tell application "Finder" to delete (POSIX file (item 1 of argv))`,
+			"-e", "end run",
+			p).Run()
+	default:
+		return freedesktopTrash(p)
+	}
+}
+
+// freedesktopTrash 按 freedesktop.org 回收站规范移动文件：与主目录在同一文件系统时放入
+// $XDG_DATA_HOME/Trash，否则放入该文件系统根目录下的 .Trash/$uid 或 .Trash-$uid
+func freedesktopTrash(p string) error {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("无法获取主目录: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	homeTrash := filepath.Join(dataHome, "Trash")
+
+	// 解析所在目录的符号链接（文件本身是符号链接时移动的是链接）
+	dir, err := filepath.EvalSymlinks(filepath.Dir(p))
+	if err != nil {
+		return err
+	}
+	p = filepath.Join(dir, filepath.Base(p))
+
+	mounts := readMountPoints()
+	top := mountPointOf(mounts, p)
+	if top == mountPointOf(mounts, homeTrash) {
+		// 主目录回收站的 trashinfo 使用绝对路径
+		return putInTrash(homeTrash, p, p)
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	trashDir := filepath.Join(top, ".Trash-"+uid)
+	// 管理员创建的共享 .Trash 必须是设置了粘滞位的目录（不能是符号链接）
+	if info, err := os.Lstat(filepath.Join(top, ".Trash")); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		trashDir = filepath.Join(top, ".Trash", uid)
+	}
+	// 其他文件系统的回收站使用相对于根目录的路径
+	rel, err := filepath.Rel(top, p)
+	if err != nil {
+		return err
+	}
+	return putInTrash(trashDir, p, rel)
+}
+
+// putInTrash 先写入 info/<名称>.trashinfo 占用名称，再把文件移到 files/<名称>
+func putInTrash(trashDir, p, infoPath string) error {
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("无法创建回收站目录: %w", err)
+		}
+	}
+
+	info := "[Trash Info]\nPath=" + (&url.URL{Path: filepath.ToSlash(infoPath)}).EscapedPath() +
+		"\nDeletionDate=" + time.Now().Format("2006-01-02T15:04:05") + "\n"
+	base := filepath.Base(p)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = stem + "." + strconv.Itoa(n) + ext
+		}
+		infoFile := filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("无法写入回收站信息: %w", err)
+		}
+		_, err = f.WriteString(info)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(p, filepath.Join(filesDir, name))
+		}
+		if err != nil {
+			os.Remove(infoFile)
+			return err
+		}
+		return nil
+	}
+}
+
+// readMountPoints 读取 /proc/self/mountinfo 中的挂载点
+func readMountPoints() []string {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var mounts []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		// 挂载点中的空格等字符以 \040 形式转义
+		mounts = append(mounts, unescapeMountPath(fields[4]))
+	}
+	return mounts
+}
+
+// unescapeMountPath 还原 mountinfo 中八进制转义的字符
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// mountPointOf 返回路径所在的挂载点（最长的前缀），所在目录的符号链接会先解析
+func mountPointOf(mounts []string, p string) string {
+	// 路径可能尚不存在（如还没创建的回收站），解析已存在的最上层目录
+	resolved := p
+	for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			rest, _ := filepath.Rel(dir, p)
+			resolved = filepath.Join(real, rest)
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	best := "/"
+	for _, m := range mounts {
+		if len(m) > len(best) && rootContains(m, resolved) {
+			best = m
+		}
+	}
+	return best
+}
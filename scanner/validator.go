@@ -3,7 +3,9 @@ package scanner
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -19,6 +21,12 @@ var (
 	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
 )
 
+// 流式验证时需要保留的头部和尾部长度（覆盖各验证函数读取的范围）
+const (
+	validateHeadSize = 8192
+	validateTailSize = 1024
+)
+
 // ValidateFile 验证文件是否有效
 func ValidateFile(path string, fileType string) (bool, string) {
 	file, err := os.Open(path)
@@ -33,14 +41,57 @@ func ValidateFile(path string, fileType string) (bool, string) {
 		return false, "无法获取文件信息: " + err.Error()
 	}
 
+	return ValidateReaderAt(file, stat.Size(), fileType)
+}
+
+// ValidateFS 验证文件系统中的文件，文件不支持随机读取时按流验证
+func ValidateFS(fsys fs.FS, name string, fileType string) (bool, string) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return false, "无法打开文件: " + err.Error()
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return false, "无法获取文件信息: " + err.Error()
+	}
+
+	if r, ok := file.(io.ReaderAt); ok {
+		return ValidateReaderAt(r, stat.Size(), fileType)
+	}
+	return ValidateStream(file, stat.Size(), fileType)
+}
+
+// ValidateStream 验证只能顺序读取的数据（如压缩包内的条目），只保留头部和尾部用于验证
+func ValidateStream(r io.Reader, size int64, fileType string) (bool, string) {
+	head := make([]byte, min(size, validateHeadSize))
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false, "无法读取文件头: " + err.Error()
+	}
+	head = head[:n]
+
+	// 读取剩余数据，只保留最后一段
+	tail := newTailBuffer(validateTailSize)
+	rest, err := io.Copy(tail, r)
+	if err != nil {
+		return false, "无法读取文件内容: " + err.Error()
+	}
+
+	return ValidateReaderAt(&headTailReader{head: head, tail: tail.bytes(), size: int64(n) + rest}, int64(n)+rest, fileType)
+}
+
+// ValidateReaderAt 验证可随机读取的数据
+func ValidateReaderAt(r io.ReaderAt, size int64, fileType string) (bool, string) {
 	// 空文件检查
-	if stat.Size() == 0 {
+	if size == 0 {
 		return false, "文件为空"
 	}
 
 	// 读取文件头部用于验证
 	header := make([]byte, 8)
-	n, err := file.Read(header)
+	n, err := r.ReadAt(header, 0)
 	if err != nil && err != io.EOF {
 		return false, "无法读取文件头: " + err.Error()
 	}
@@ -50,16 +101,76 @@ func ValidateFile(path string, fileType string) (bool, string) {
 
 	switch fileType {
 	case "pdf":
-		return validatePDF(file, header, stat.Size())
+		return validatePDF(r, header, size)
 	case "word", "excel", "ppt":
-		return validateOffice(file, header, stat.Size(), fileType)
+		return validateOffice(r, header, size, fileType)
 	}
 
 	return true, ""
 }
 
+// tailBuffer 只保留最后 n 个字节的写入缓冲
+type tailBuffer struct {
+	buf []byte
+	n   int
+}
+
+func newTailBuffer(n int) *tailBuffer {
+	return &tailBuffer{buf: make([]byte, 0, 2*n), n: n}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	if len(p) >= t.n {
+		t.buf = append(t.buf[:0], p[len(p)-t.n:]...)
+		return len(p), nil
+	}
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.n {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.n:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) bytes() []byte {
+	return t.buf
+}
+
+// headTailReader 只包含头部和尾部数据的 ReaderAt，读取中间部分时返回错误
+type headTailReader struct {
+	head []byte
+	tail []byte
+	size int64
+}
+
+func (h *headTailReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= h.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	var err error
+	if end > h.size {
+		end = h.size
+		err = io.EOF
+	}
+
+	headEnd := int64(len(h.head))
+	tailStart := h.size - int64(len(h.tail))
+	n := 0
+	for pos := off; pos < end; pos = off + int64(n) {
+		switch {
+		case pos < headEnd:
+			n += copy(p[n:end-off], h.head[pos:min(end, headEnd)])
+		case pos >= tailStart:
+			n += copy(p[n:end-off], h.tail[pos-tailStart:end-tailStart])
+		default:
+			return n, errors.New("数据不在已读取的范围内")
+		}
+	}
+	return n, err
+}
+
 // validatePDF 验证PDF文件
-func validatePDF(file *os.File, header []byte, size int64) (bool, string) {
+func validatePDF(file io.ReaderAt, header []byte, size int64) (bool, string) {
 	// 检查PDF魔数
 	if !bytes.HasPrefix(header, pdfMagic) {
 		return false, "不是有效的PDF文件（文件头不匹配）"
@@ -76,14 +187,9 @@ func validatePDF(file *os.File, header []byte, size int64) (bool, string) {
 		tailSize = size
 	}
 
-	_, err := file.Seek(-tailSize, io.SeekEnd)
-	if err != nil {
-		return false, "无法读取文件尾部"
-	}
-
 	tail := make([]byte, tailSize)
-	_, err = file.Read(tail)
-	if err != nil {
+	_, err := file.ReadAt(tail, size-tailSize)
+	if err != nil && err != io.EOF {
 		return false, "无法读取文件尾部"
 	}
 
@@ -93,9 +199,8 @@ func validatePDF(file *os.File, header []byte, size int64) (bool, string) {
 	}
 
 	// 检查是否包含基本的PDF结构
-	file.Seek(0, io.SeekStart)
 	content := make([]byte, min(size, 4096))
-	file.Read(content)
+	file.ReadAt(content, 0)
 
 	// 检查是否有对象定义
 	if !bytes.Contains(content, []byte("obj")) {
@@ -106,7 +211,7 @@ func validatePDF(file *os.File, header []byte, size int64) (bool, string) {
 }
 
 // validateOffice 验证Office文件
-func validateOffice(file *os.File, header []byte, size int64, fileType string) (bool, string) {
+func validateOffice(file io.ReaderAt, header []byte, size int64, fileType string) (bool, string) {
 	// 检查是否是OOXML格式（.docx, .xlsx, .pptx等）
 	if bytes.HasPrefix(header, zipMagic) {
 		return validateOOXML(file, size, fileType)
@@ -119,9 +224,8 @@ func validateOffice(file *os.File, header []byte, size int64, fileType string) (
 
 	// CSV文件特殊处理
 	if fileType == "excel" {
-		file.Seek(0, io.SeekStart)
 		content := make([]byte, min(size, 4096))
-		n, _ := file.Read(content)
+		n, _ := file.ReadAt(content, 0)
 		if n > 0 && isValidCSV(content[:n]) {
 			return true, ""
 		}
@@ -131,13 +235,11 @@ func validateOffice(file *os.File, header []byte, size int64, fileType string) (
 }
 
 // validateOOXML 验证OOXML格式文件
-func validateOOXML(file *os.File, size int64, fileType string) (bool, string) {
+func validateOOXML(file io.ReaderAt, size int64, fileType string) (bool, string) {
 	// OOXML是ZIP格式，检查ZIP结构
-	file.Seek(0, io.SeekStart)
-
 	// 读取更多内容来验证ZIP结构
 	content := make([]byte, min(size, 8192))
-	n, err := file.Read(content)
+	n, err := file.ReadAt(content, 0)
 	if err != nil && err != io.EOF {
 		return false, "无法读取文件内容"
 	}
@@ -182,13 +284,11 @@ func validateOOXML(file *os.File, size int64, fileType string) (bool, string) {
 }
 
 // validateOLE2 验证OLE2格式文件
-func validateOLE2(file *os.File, size int64, fileType string) (bool, string) {
+func validateOLE2(file io.ReaderAt, size int64, fileType string) (bool, string) {
 	// OLE2格式验证
-	file.Seek(0, io.SeekStart)
-
 	// 读取OLE2头部
 	header := make([]byte, 512)
-	n, err := file.Read(header)
+	n, err := file.ReadAt(header, 0)
 	if err != nil || n < 512 {
 		return false, "无法读取OLE2头部"
 	}
@@ -232,11 +332,3 @@ func isValidCSV(content []byte) bool {
 	// 如果超过90%是可打印字符，认为是有效的CSV
 	return float64(printableCount)/float64(totalCount) > 0.9
 }
-
-// min 返回两个int64中的较小值
-func min(a, b int64) int64 {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,375 @@
+package scanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebDAV 后端：webdav:// 和 webdavs:// 使用 HTTPS；
+// webdav+http:// 使用不加密的 HTTP（密码以 Basic 认证明文发送，只应在可信的内网中明确选择）
+const (
+	BackendWebDAV     = "webdav"
+	BackendWebDAVS    = "webdavs"
+	BackendWebDAVHTTP = "webdav+http"
+)
+
+// PROPFIND 只请求扫描需要的属性
+const davPropfindBody = `<?xml version="1.0" encoding="utf-8"?>` +
+	`<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/></d:prop></d:propfind>`
+
+func init() {
+	RegisterBackend(BackendWebDAV, mountWebDAV)
+	RegisterBackend(BackendWebDAVS, mountWebDAV)
+	RegisterBackend(BackendWebDAVHTTP, mountWebDAV)
+}
+
+// davMultistatus PROPFIND 响应
+type davMultistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href     string        `xml:"DAV: href"`
+	Propstat []davPropstat `xml:"DAV: propstat"`
+}
+
+type davPropstat struct {
+	Status string `xml:"DAV: status"`
+	Prop   struct {
+		ResourceType struct {
+			Collection *struct{} `xml:"DAV: collection"`
+		} `xml:"DAV: resourcetype"`
+		ContentLength string `xml:"DAV: getcontentlength"`
+		LastModified  string `xml:"DAV: getlastmodified"`
+	} `xml:"DAV: prop"`
+}
+
+// davFileInfo WebDAV 资源信息
+type davFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *davFileInfo) Name() string               { return i.name }
+func (i *davFileInfo) Size() int64                { return i.size }
+func (i *davFileInfo) ModTime() time.Time         { return i.modTime }
+func (i *davFileInfo) IsDir() bool                { return i.dir }
+func (i *davFileInfo) Sys() any                   { return nil }
+func (i *davFileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i *davFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func (i *davFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// webdavFS 通过 PROPFIND 列目录、通过 Range 请求读取内容的只读文件系统
+type webdavFS struct {
+	client   *http.Client
+	base     *url.URL // http(s)://host/
+	username string
+	password string
+}
+
+// mountWebDAV 挂载 webdav://[user@]host[:port]/path（webdav+http:// 使用 HTTP）
+func mountWebDAV(root string) (*Mount, error) {
+	u, err := url.Parse(root)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("无效的 WebDAV 地址: %s", root)
+	}
+
+	scheme := "https"
+	if strings.EqualFold(u.Scheme, BackendWebDAVHTTP) {
+		scheme = "http"
+	}
+	fsys := &webdavFS{
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 30 * time.Second,
+		}},
+		base: &url.URL{Scheme: scheme, Host: u.Host, Path: "/"},
+	}
+	if cred, ok := findCredential(u); ok {
+		fsys.username, fsys.password = cred.Username, cred.Password
+	}
+	if u.User != nil {
+		fsys.username = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			fsys.password = password
+		}
+		u.User = url.User(u.User.Username()) // 结果路径中不保留密码
+	}
+
+	rootDir := strings.Trim(u.Path, "/")
+	if rootDir == "" {
+		rootDir = "."
+	}
+	info, err := fsys.Stat(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("WebDAV 路径不是目录: %s", root)
+	}
+
+	prefix := (&url.URL{Scheme: strings.ToLower(u.Scheme), User: u.User, Host: u.Host}).String() + "/"
+	return &Mount{FS: fsys, Root: rootDir, Prefix: prefix}, nil
+}
+
+// resourceURL 返回 fs 路径对应的 URL，目录以 / 结尾
+func (w *webdavFS) resourceURL(name string, dir bool) string {
+	u := *w.base
+	if name != "." {
+		u.Path = "/" + name
+	}
+	if dir && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.String()
+}
+
+// do 发送请求，附带认证信息
+func (w *webdavFS) do(req *http.Request) (*http.Response, error) {
+	if w.username != "" || w.password != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("无法连接 WebDAV 服务器: %w", err)
+	}
+	return resp, nil
+}
+
+// statusError 把 HTTP 错误状态转换为文件系统错误
+func (w *webdavFS) statusError(op, name string, resp *http.Response) error {
+	var err error
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		err = fs.ErrNotExist
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err = fs.ErrPermission
+	default:
+		err = fmt.Errorf("WebDAV 服务器返回 %s", resp.Status)
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// propfind 查询资源属性，depth 为 0 时只返回资源本身，为 1 时同时返回子项
+func (w *webdavFS) propfind(name string, depth int) ([]davResponse, error) {
+	req, err := http.NewRequest("PROPFIND", w.resourceURL(name, depth > 0), strings.NewReader(davPropfindBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", strconv.Itoa(depth))
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	resp, err := w.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, w.statusError("propfind", name, resp)
+	}
+
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("无法解析 WebDAV 响应: %w", err)
+	}
+	return ms.Responses, nil
+}
+
+// fileInfo 把 PROPFIND 响应转换为文件信息
+func (r *davResponse) fileInfo() (*davFileInfo, string) {
+	href := r.Href
+	if u, err := url.Parse(href); err == nil {
+		href = u.Path
+	}
+	href = path.Clean("/" + href)
+
+	info := &davFileInfo{name: path.Base(href)}
+	for _, ps := range r.Propstat {
+		if !strings.Contains(ps.Status, " 200") {
+			continue
+		}
+		info.dir = ps.Prop.ResourceType.Collection != nil
+		info.size, _ = strconv.ParseInt(strings.TrimSpace(ps.Prop.ContentLength), 10, 64)
+		info.modTime, _ = http.ParseTime(strings.TrimSpace(ps.Prop.LastModified))
+	}
+	return info, href
+}
+
+// Stat 实现 fs.StatFS
+func (w *webdavFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	responses, err := w.propfind(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) == 0 {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	info, _ := responses[0].fileInfo()
+	if name == "." {
+		info.name = "."
+	}
+	return info, nil
+}
+
+// ReadDir 实现 fs.ReadDirFS，结果按名称排序
+func (w *webdavFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	responses, err := w.propfind(name, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	self := path.Clean("/" + name)
+	var entries []fs.DirEntry
+	for i := range responses {
+		info, href := responses[i].fileInfo()
+		if href == self || path.Dir(href) != self {
+			continue
+		}
+		entries = append(entries, info)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Open 实现 fs.FS
+func (w *webdavFS) Open(name string) (fs.File, error) {
+	info, err := w.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &remoteDir{name: name, info: info, list: func() ([]fs.DirEntry, error) { return w.ReadDir(name) }}, nil
+	}
+	return &webdavFile{fsys: w, name: name, info: info}, nil
+}
+
+// webdavFile WebDAV 文件，随机读取使用 Range 请求，顺序读取复用同一个响应
+type webdavFile struct {
+	fsys   *webdavFS
+	name   string
+	info   fs.FileInfo
+	offset int64
+	body   io.ReadCloser // 顺序读取的响应，Seek 后重新请求
+}
+
+func (f *webdavFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *webdavFile) Close() error {
+	if f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	return nil
+}
+
+// get 请求从 offset 开始的内容，length<=0 表示到文件末尾
+func (f *webdavFile) get(offset, length int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, f.fsys.resourceURL(f.name, false), nil)
+	if err != nil {
+		return nil, err
+	}
+	if length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := f.fsys.do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusOK:
+		// 服务器不支持 Range 时跳过前面的内容
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp.Body, nil
+	}
+	resp.Body.Close()
+	return nil, f.fsys.statusError("read", f.name, resp)
+}
+
+// Read 实现 io.Reader
+func (f *webdavFile) Read(p []byte) (int, error) {
+	if f.offset >= f.info.Size() {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		body, err := f.get(f.offset, 0)
+		if err != nil {
+			return 0, err
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+// ReadAt 实现 io.ReaderAt，每次调用发送一个 Range 请求
+func (f *webdavFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.info.Size() {
+		return 0, io.EOF
+	}
+	length := min(int64(len(p)), f.info.Size()-off)
+	body, err := f.get(off, length)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:length])
+	if err == nil && length < int64(len(p)) {
+		err = io.EOF
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Seek 实现 io.Seeker
+func (f *webdavFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+// startWebDAV 启动需要 Basic 认证的内存 WebDAV 服务器，返回服务器地址（host:port）和 Range 请求计数
+func startWebDAV(t *testing.T, files map[string][]byte) (string, *atomic.Int64) {
+	t.Helper()
+	memFS := webdav.NewMemFS()
+	ctx := context.Background()
+	for name, data := range files {
+		dir := "/"
+		for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(name)), "/") {
+			if part == "." {
+				continue
+			}
+			dir += part + "/"
+			memFS.Mkdir(ctx, dir, 0755)
+		}
+		f, err := memFS.OpenFile(ctx, "/"+name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+		f.Close()
+	}
+
+	var ranges atomic.Int64
+	handler := &webdav.Handler{FileSystem: memFS, LockSystem: webdav.NewMemLS()}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "open-sesame" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), &ranges
+}
+
+func TestWebDAVScan(t *testing.T) {
+	dir := isolateConfig(t)
+	host, ranges := startWebDAV(t, map[string][]byte{
+		"share/contracts/a.pdf":  testPDF(64 << 10),
+		"share/contracts/b.csv":  []byte("name,amount\nx,1\n"),
+		"share/notes.txt":        []byte("skip"),
+		"share/broken/bad.pdf":   []byte("%PDF-1.4 truncated"),
+		"other/not-scanned.pdf":  testPDF(0),
+		"share/contracts/c.docx": []byte("not a zip"),
+	})
+	root := "webdav+http://" + host + "/share"
+
+	if _, err := mountWebDAV(root); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("mount without credentials: err = %v, want fs.ErrPermission", err)
+	}
+
+	if err := SaveCredential(Credential{URL: root, Username: "alice", Password: "open-sesame"}); err != nil {
+		t.Fatal(err)
+	}
+	// 凭据文件中不应出现明文密码
+	data, err := os.ReadFile(filepath.Join(dir, "DocRadar", "credentials.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "open-sesame") {
+		t.Errorf("credentials file contains the plaintext password:\n%s", data)
+	}
+
+	result, err := NewScanner().Scan(ScanOptions{RootPath: root, ValidateFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Release()
+	got := map[string]bool{}
+	for _, f := range result.Files {
+		got[strings.TrimPrefix(f.Path, "webdav+http://"+host+"/")] = f.IsValid
+	}
+	want := map[string]bool{
+		"share/contracts/a.pdf":  true,
+		"share/contracts/b.csv":  true,
+		"share/contracts/c.docx": false,
+		"share/broken/bad.pdf":   false,
+	}
+	if len(got) != len(want) {
+		t.Errorf("scanned %v, want %v", got, want)
+	}
+	for name, valid := range want {
+		if v, ok := got[name]; !ok || v != valid {
+			t.Errorf("%s: found %v, valid %v, want valid %v", name, ok, v, valid)
+		}
+	}
+	if ranges.Load() == 0 {
+		t.Error("validation did not use range requests")
+	}
+
+	// 扫描后可以按结果路径重新读取（预览、导出）
+	rc, err := openSource(root + "/contracts/b.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	buf := make([]byte, 4)
+	if _, err := rc.Read(buf); err != nil || string(buf) != "name" {
+		t.Errorf("read = %q, %v", buf, err)
+	}
+}
+
+func TestWebDAVDefaultsToHTTPS(t *testing.T) {
+	isolateConfig(t)
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	// 测试服务器使用自签名证书：webdav:// 必须走 HTTPS 并校验证书
+	_, err := mountWebDAV("webdav://" + host + "/share")
+	var certErr *tls.CertificateVerificationError
+	if !errors.As(err, &certErr) {
+		t.Errorf("webdav:// error = %v, want a TLS certificate error", err)
+	}
+
+	plain := httptest.NewServer(http.NotFoundHandler())
+	defer plain.Close()
+	_, err = mountWebDAV("webdavs://" + strings.TrimPrefix(plain.URL, "http://") + "/share")
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("webdavs:// against a plain HTTP server: err = %v, want a connection error", err)
+	}
+}
+
+func TestCredentialsKeepUndecryptableSecret(t *testing.T) {
+	dir := isolateConfig(t)
+	path := filepath.Join(dir, "DocRadar", "credentials.json")
+	// 用其他密钥加密的密码（如密钥文件丢失后）无法解密
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 64))
+	writeTestFile(t, filepath.Dir(path), "credentials.json", []byte(`{"version":1,"credentials":[`+
+		`{"url":"sftp://nas.local","username":"bob","secret":"`+secret+`"}]}`))
+
+	if cred, ok := findCredential(mustParseURL(t, "sftp://nas.local/share")); !ok || cred.Password != "" {
+		t.Fatalf("findCredential = %+v, %v", cred, ok)
+	}
+	// 保存其他凭据和记录主机公钥时原密文保持不变
+	if err := SaveCredential(Credential{URL: "webdav://nas.local", Password: "open-sesame"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordHostKey("sftp://nas.local", "SHA256:abc"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), secret) {
+		t.Errorf("undecryptable secret was not kept:\n%s", data)
+	}
+}
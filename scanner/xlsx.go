@@ -0,0 +1,269 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// 生成 XLSX 报告：只使用内联字符串、不依赖第三方库的最小 SpreadsheetML 包，
+// 工作表边生成边写入压缩包，文件很多时也不需要把整个表格放在内存中
+
+const (
+	xlsxMaxRows     = 1048576 // 工作表最多的行数
+	xlsxMaxCellText = 32000   // 单元格最多约 32767 个字符，超出时截断
+)
+
+// 单元格样式，对应 styles.xml 中 cellXfs 的序号
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleHeader  = 1 // 粗体
+	xlsxStyleTime    = 2 // yyyy-mm-dd hh:mm:ss
+)
+
+// Excel 日期序列号的起点
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// 各列的宽度（字符数），未列出的列使用默认宽度
+var xlsxColumnWidths = map[string]float64{
+	"name":          32,
+	"path":          64,
+	"modTime":       20,
+	"invalidReason": 28,
+	"findings":      24,
+	"labels":        20,
+	"hash":          66,
+	"root":          32,
+	"title":         28,
+}
+
+const xlsxDefaultWidth = 12
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const xlsxContentTypes = xlsxHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRels = xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const xlsxStyles = xlsxHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// writeReportXLSX 写出 XLSX 报告：“文件”工作表为文件列表（首行冻结并带筛选），“统计”工作表为统计
+func writeReportXLSX(w io.Writer, files []FileInfo, columns []reportColumn, stats ReportStats) error {
+	if len(files)+1 > xlsxMaxRows {
+		return fmt.Errorf("文件数超过 XLSX 的行数上限（%d），请使用 CSV 格式", xlsxMaxRows-1)
+	}
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, data string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		if err := writeZipPart(zw, part.name, part.data); err != nil {
+			return err
+		}
+	}
+
+	// 文件列表
+	widths := make([]float64, len(columns))
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c.Title
+		if widths[i] = xlsxColumnWidths[c.Key]; widths[i] == 0 {
+			widths[i] = xlsxDefaultWidth
+		}
+	}
+	sheet, err := newXLSXSheet(zw, "xl/worksheets/sheet1.xml", widths)
+	if err != nil {
+		return err
+	}
+	sheet.writeRow(header, xlsxStyleHeader)
+	values := make([]any, len(columns))
+	for i := range files {
+		for j, c := range columns {
+			values[j] = c.value(&files[i])
+		}
+		sheet.writeRow(values, xlsxStyleDefault)
+	}
+	filterRef := "A1:" + xlsxCellRef(len(columns)-1, sheet.rows)
+	if err := sheet.close(filterRef); err != nil {
+		return err
+	}
+
+	// 统计
+	sheet, err = newXLSXSheet(zw, "xl/worksheets/sheet2.xml", []float64{12, 40, 10, 16})
+	if err != nil {
+		return err
+	}
+	sheet.writeRow([]any{"统计", "项目", "文件数", "大小（字节）"}, xlsxStyleHeader)
+	for _, row := range stats.statRows() {
+		var size any = ""
+		if row.Size > 0 {
+			size = row.Size
+		}
+		sheet.writeRow([]any{row.Section, row.Name, int64(row.Count), size}, xlsxStyleDefault)
+	}
+	if err := sheet.close(""); err != nil {
+		return err
+	}
+
+	workbook := xlsxHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="文件" sheetId="1" r:id="rId1"/><sheet name="统计" sheetId="2" r:id="rId2"/></sheets>` +
+		`<definedNames><definedName name="_xlnm._FilterDatabase" localSheetId="0" hidden="1">` +
+		`'文件'!` + xlsxAbsRef(filterRef) + `</definedName></definedNames>` +
+		`</workbook>`
+	if err := writeZipPart(zw, "xl/workbook.xml", workbook); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeZipPart 向压缩包写入一个部件
+func writeZipPart(zw *zip.Writer, name, data string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, data)
+	return err
+}
+
+// xlsxSheet 正在写入的工作表
+type xlsxSheet struct {
+	w    *bufio.Writer
+	rows int
+}
+
+// newXLSXSheet 创建工作表并写入列宽，首行冻结
+func newXLSXSheet(zw *zip.Writer, name string, widths []float64) (*xlsxSheet, error) {
+	w, err := zw.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	s := &xlsxSheet{w: bufio.NewWriter(w)}
+	s.w.WriteString(xlsxHeader)
+	s.w.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	s.w.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`</sheetView></sheetViews>`)
+	s.w.WriteString("<cols>")
+	for i, width := range widths {
+		fmt.Fprintf(s.w, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, width)
+	}
+	s.w.WriteString("</cols><sheetData>")
+	return s, nil
+}
+
+// writeRow 写入一行，值为空字符串、零值时间或 0 页数的单元格留空
+func (s *xlsxSheet) writeRow(values []any, style int) {
+	s.rows++
+	fmt.Fprintf(s.w, `<row r="%d">`, s.rows)
+	for col, v := range values {
+		ref := xlsxCellRef(col, s.rows)
+		switch v := v.(type) {
+		case int64:
+			fmt.Fprintf(s.w, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int:
+			if v != 0 {
+				fmt.Fprintf(s.w, `<c r="%s"><v>%d</v></c>`, ref, v)
+			}
+		case time.Time:
+			if !v.IsZero() {
+				fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleTime, strconv.FormatFloat(xlsxSerial(v), 'f', -1, 64))
+			}
+		default:
+			text := formatReportValue(v)
+			if text == "" {
+				continue
+			}
+			if utf8.RuneCountInString(text) > xlsxMaxCellText {
+				text = string([]rune(text)[:xlsxMaxCellText])
+			}
+			if style != xlsxStyleDefault {
+				fmt.Fprintf(s.w, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			} else {
+				fmt.Fprintf(s.w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			}
+			// EscapeText 把 XML 中不允许的控制字符替换为 U+FFFD
+			xml.EscapeText(s.w, []byte(text))
+			s.w.WriteString("</t></is></c>")
+		}
+	}
+	s.w.WriteString("</row>")
+}
+
+// close 结束工作表，filterRef 不为空时在该区域上添加筛选
+func (s *xlsxSheet) close(filterRef string) error {
+	s.w.WriteString("</sheetData>")
+	if filterRef != "" {
+		fmt.Fprintf(s.w, `<autoFilter ref="%s"/>`, filterRef)
+	}
+	s.w.WriteString("</worksheet>")
+	return s.w.Flush()
+}
+
+// xlsxSerial 把时间转换为 Excel 日期序列号（本地时间，Excel 不保存时区）
+func xlsxSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(xlsxEpoch).Seconds() / 86400
+}
+
+// xlsxCellRef 返回单元格引用，col 从 0 开始，row 从 1 开始：(0, 1) → A1，(27, 3) → AB3
+func xlsxCellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// xlsxAbsRef 把 A1:H10 转换为绝对引用 $A$1:$H$10
+func xlsxAbsRef(ref string) string {
+	out := make([]byte, 0, len(ref)+4)
+	prevLetter := false
+	for i := 0; i < len(ref); i++ {
+		c := ref[i]
+		isLetter := c >= 'A' && c <= 'Z'
+		isDigit := c >= '0' && c <= '9'
+		if (isLetter && !prevLetter) || (isDigit && prevLetter) {
+			out = append(out, '$')
+		}
+		out = append(out, c)
+		prevLetter = isLetter
+	}
+	return string(out)
+}
@@ -0,0 +1,603 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 打包导出：读取协程按顺序把每个文件切成 1MB 的块，工作协程并行压缩，写入协程按原顺序写入压缩包。
+// 同一文件的块以同步标记结尾，并以前一块末尾的 32KB 作为字典，拼接后是一个完整的 Deflate 流（与 pigz 相同），
+// 所以大文件和大量小文件都能用上多个 CPU。每个文件压缩后的数据先暂存（较大时写入临时文件），整个文件读取成功后
+// 才以已知的 CRC 和大小写入压缩包，读取中途失败的文件不会留下不完整的条目。文件大小或压缩包超过 4GB 时由 archive/zip 使用 ZIP64。
+// 设置分卷大小时拆分为多个可以单独解压的压缩包（不使用需要专门工具合并的跨卷格式）
+
+const (
+	zipBlockSize   = 1 << 20  // 每块未压缩数据的大小
+	zipDictSize    = 32 << 10 // Deflate 的窗口大小
+	zipMaxWorkers  = 8
+	zipStageMemory = 16 << 20 // 压缩后的数据小于此大小时暂存在内存中，否则写入临时文件
+
+	zipEntryOverhead = 192  // 每个文件的本地文件头和中央目录记录（含 ZIP64 扩展）的最大长度，不含文件名
+	zipDirOverhead   = 83   // 每个文件在中央目录中的最大长度，不含文件名
+	zipEndOverhead   = 128  // 中央目录结束记录（含 ZIP64）的最大长度
+	zipFlushSlack    = 4096 // archive/zip 内部缓冲区中尚未写入文件的字节
+
+	// MinZipVolumeSize 分卷的最小大小
+	MinZipVolumeSize = 1 << 20
+)
+
+// 本身已经是压缩格式的扩展名，打包时直接存储，不再压缩（OOXML 文档本身就是 zip）
+var storedExtensions = map[string]bool{
+	".docx": true, ".docm": true, ".dotx": true,
+	".xlsx": true, ".xlsm": true, ".xltx": true,
+	".pptx": true, ".pptm": true, ".potx": true,
+	".zip": true, ".gz": true, ".tgz": true, ".7z": true, ".rar": true,
+	".jpg": true, ".jpeg": true, ".png": true,
+}
+
+var errZipEntryTooLarge = errors.New("文件压缩后大于分卷大小")
+
+// zipBlock 一个文件中的一块数据
+type zipBlock struct {
+	item  int  // 在导出计划中的序号
+	start bool // 文件的第一块
+	end   bool // 文件的最后一块
+
+	header *zip.FileHeader // 第一块：文件头
+	size   int64           // 第一块：文件大小，用于分卷
+	hash   string          // 最后一块：内容的 SHA-256
+
+	data []byte // 未压缩的数据
+	dict []byte // 前面的数据末尾，作为压缩字典
+	out  []byte // 要写入压缩包的数据
+	err  error  // 打开或读取失败（此时 end 为 true）
+
+	done chan struct{} // 压缩完成后关闭
+}
+
+// ExportAsZip 导出为压缩包，ctx 取消时停止导出并删除未完成的压缩包
+func (e *Exporter) ExportAsZip(ctx context.Context, options ExportOptions) (*ExportResult, error) {
+	if len(options.FindingTypes) > 0 {
+		options.Files = FilterByFindingTypes(options.Files, options.FindingTypes)
+	}
+
+	if IsS3Path(options.DestPath) {
+		return nil, errS3ZipUnsupported
+	}
+	if options.ZipVolumeSize < 0 || (options.ZipVolumeSize > 0 && options.ZipVolumeSize < MinZipVolumeSize) {
+		return nil, errors.New("分卷大小不能小于 1MB")
+	}
+
+	result := &ExportResult{
+		FailedFiles:  make([]string, 0),
+		SkippedFiles: make([]string, 0),
+	}
+
+	items, err := planExport(options, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// 确保目标目录存在
+	if err := os.MkdirAll(options.DestPath, 0755); err != nil {
+		return nil, fmt.Errorf("无法创建目标目录: %w", err)
+	}
+
+	// 生成压缩包文件名，分卷时为 office-files-xxx.part1.zip、office-files-xxx.part2.zip……
+	base := "office-files-" + time.Now().Format("20060102-150405")
+	volumes := &zipVolumes{dir: options.DestPath, base: base, limit: options.ZipVolumeSize}
+	if err := volumes.next(); err != nil {
+		return nil, err
+	}
+
+	tracker := e.newTracker(items)
+	defer tracker.finish()
+	manifest := newManifest(options.DestPath, items, result.SkippedFiles)
+	volumeOf := make([]int, len(items)) // 每个文件所在的分卷
+
+	// 读取和压缩协程，写入结束（完成、取消或出错）后停止
+	pipeCtx, stop := context.WithCancel(ctx)
+	workers := min(runtime.NumCPU(), zipMaxWorkers)
+	jobs := make(chan *zipBlock)
+	ordered := make(chan *zipBlock, 2*workers)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		readZipBlocks(pipeCtx, items, jobs, ordered)
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				b.out, b.err = deflateBlock(b.data, b.dict, b.end)
+				close(b.done)
+			}
+		}()
+	}
+
+	// 按顺序写入：暂存当前文件压缩后的数据，读完最后一块后写入压缩包
+	stage := &zipStage{dir: options.DestPath}
+	defer stage.reset()
+	var (
+		fh      *zip.FileHeader
+		crc     uint32
+		written int64 // 当前文件已读取的未压缩字节数
+		skip    = -1  // 无法放入分卷而跳过其余块的文件
+	)
+	fail := func(i int, err error) {
+		file := items[i].file
+		result.Failed++
+		result.FailedFiles = append(result.FailedFiles, file.Path)
+		manifest.set(i, ManifestFailed, "", err)
+		tracker.fileDone(file, written, true)
+	}
+	err = func() error {
+		for b := range ordered {
+			select {
+			case <-b.done:
+			case <-ctx.Done():
+				return nil
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			if b.item == skip {
+				continue
+			}
+			item := items[b.item]
+
+			if b.start {
+				tracker.setCurrent(item.file.Name)
+				written, crc = 0, 0
+				stage.reset()
+				if b.err != nil {
+					// 无法打开或第一块就读取失败的文件不写入压缩包
+					fail(b.item, b.err)
+					continue
+				}
+				// 直接存储的文件大小已知，放不进分卷时不再读取其余的块
+				if b.header.Method == zip.Store && !volumes.fits(len(b.header.Name), b.size) {
+					skip = b.item
+					fail(b.item, errZipEntryTooLarge)
+					continue
+				}
+				fh = b.header
+			}
+
+			if _, err := stage.Write(b.out); err != nil {
+				return err
+			}
+			crc = crc32.Update(crc, crc32.IEEETable, b.data)
+			written += int64(len(b.data))
+			tracker.addBytes(int64(len(b.data)))
+			if !b.end {
+				continue
+			}
+
+			// 读取中途失败的文件丢弃已暂存的数据，不写入压缩包
+			if b.err != nil {
+				fail(b.item, b.err)
+				continue
+			}
+			fh.CRC32 = crc
+			fh.UncompressedSize64 = uint64(written)
+			fh.CompressedSize64 = uint64(stage.n)
+			if err := volumes.reserve(len(fh.Name), stage.n); err != nil {
+				if errors.Is(err, errZipEntryTooLarge) {
+					fail(b.item, err)
+					continue
+				}
+				return err
+			}
+			volumeOf[b.item] = len(volumes.paths) - 1
+			w, err := volumes.zw.CreateRaw(fh)
+			if err != nil {
+				return err
+			}
+			if err := stage.copyTo(w); err != nil {
+				return err
+			}
+			result.Success++
+			manifest.set(b.item, ManifestExported, b.hash, nil)
+			tracker.fileDone(item.file, written, false)
+		}
+		return nil
+	}()
+
+	// 停止读取和压缩，等待协程退出后再关闭或删除压缩包
+	stop()
+	for range ordered {
+	}
+	wg.Wait()
+
+	if err == nil {
+		err = volumes.close()
+	} else {
+		volumes.abort()
+	}
+	if ctx.Err() != nil {
+		// 压缩包已删除，已添加的文件也不算导出成功
+		volumes.remove()
+		return &ExportResult{FailedFiles: make([]string, 0), SkippedFiles: make([]string, 0), Canceled: true}, nil
+	}
+	if err != nil {
+		volumes.remove()
+		return nil, fmt.Errorf("无法写入压缩文件: %w", err)
+	}
+	if err := volumes.finish(); err != nil {
+		return nil, err
+	}
+	result.ZipFiles = volumes.paths
+
+	// 导出清单写在压缩包旁边，分卷时记录每个文件所在的分卷
+	manifest.Archive = filepath.Base(volumes.paths[0])
+	if len(volumes.paths) > 1 {
+		manifest.Archive = ""
+		for i, v := range volumeOf {
+			if manifest.Files[i].Status == ManifestExported {
+				manifest.Files[i].Volume = filepath.Base(volumes.paths[v])
+			}
+		}
+	}
+	manifestPath := zipManifestPath(filepath.Join(volumes.dir, base+".zip"))
+	if err := manifest.writeFile(manifestPath, false); err != nil {
+		result.ManifestErr = err.Error()
+	} else {
+		result.ManifestPath = manifestPath
+	}
+	return result, nil
+}
+
+// readZipBlocks 按导出计划的顺序读取文件，把需要压缩的块发送给压缩协程（jobs），
+// 所有块按顺序发送给写入协程（ordered）。ordered 的缓冲区限制了内存中的块数
+func readZipBlocks(ctx context.Context, items []exportItem, jobs, ordered chan<- *zipBlock) {
+	defer close(ordered)
+	defer close(jobs)
+
+	send := func(b *zipBlock, compress bool) bool {
+		if compress {
+			select {
+			case jobs <- b:
+			case <-ctx.Done():
+				return false
+			}
+		} else {
+			b.out = b.data
+			close(b.done)
+		}
+		select {
+		case ordered <- b:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for i, item := range items {
+		rc, header, size, err := openZipSource(item)
+		if err != nil {
+			if !send(&zipBlock{item: i, start: true, end: true, err: err, done: make(chan struct{})}, false) {
+				return
+			}
+			continue
+		}
+
+		h := sha256.New()
+		var dict []byte
+		remaining := size
+		for first := true; ; first = false {
+			// 小文件只分配文件大小加一个字节的缓冲区（多一个字节用于读到结尾）
+			bufSize := int64(zipBlockSize)
+			if remaining >= 0 && remaining < bufSize {
+				bufSize = remaining + 1
+			}
+			buf := make([]byte, bufSize)
+			n, rerr := io.ReadFull(rc, buf)
+			remaining -= int64(n)
+			b := &zipBlock{item: i, start: first, data: buf[:n], dict: dict, done: make(chan struct{})}
+			if first {
+				b.header, b.size = header, size
+			}
+			switch rerr {
+			case nil:
+			case io.EOF, io.ErrUnexpectedEOF:
+				b.end = true
+			default:
+				// 丢弃读取失败的块，写入协程结束这个文件
+				b.end, b.err, b.data = true, rerr, nil
+			}
+			h.Write(b.data)
+			if b.end && b.err == nil {
+				b.hash = hex.EncodeToString(h.Sum(nil))
+			}
+
+			if !send(b, header.Method == zip.Deflate && b.err == nil) {
+				rc.Close()
+				return
+			}
+			if b.end {
+				break
+			}
+			dict = deflateDict(dict, b.data)
+		}
+		rc.Close()
+	}
+}
+
+// deflateDict 返回下一块的压缩字典：前面数据的最后 32KB
+func deflateDict(dict, data []byte) []byte {
+	if len(data) >= zipDictSize {
+		return data[len(data)-zipDictSize:]
+	}
+	next := append(append([]byte(nil), dict...), data...)
+	if len(next) > zipDictSize {
+		next = next[len(next)-zipDictSize:]
+	}
+	return next
+}
+
+// deflateBlock 压缩一块数据，不是最后一块时以同步标记结尾（字节对齐、不设置结束标志），
+// 所以各块的输出可以直接拼接
+func deflateBlock(data, dict []byte, last bool) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data)/2 + 64)
+	fw, err := flate.NewWriterDict(&buf, flate.DefaultCompression, dict)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if last {
+		err = fw.Close()
+	} else {
+		err = fw.Flush()
+	}
+	return buf.Bytes(), err
+}
+
+// openZipSource 打开要打包的文件，返回文件头（已压缩的格式直接存储）和文件大小。
+// 压缩包内和远程的文件没有对应的 os.FileInfo，根据扫描结果构造文件头
+func openZipSource(item exportItem) (io.ReadCloser, *zip.FileHeader, int64, error) {
+	file := item.file
+	method := zip.Deflate
+	if storedExtensions[strings.ToLower(filepath.Ext(file.Name))] {
+		method = zip.Store
+	}
+
+	rc, err := openSource(file.Path)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	size, modTime, mode := file.Size, file.ModTime, os.FileMode(0644)
+	if f, ok := rc.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			rc.Close()
+			return nil, nil, 0, err
+		}
+		size, modTime, mode = info.Size(), info.ModTime(), info.Mode()
+	}
+	return rc, zipRawHeader(filepath.ToSlash(item.rel), method, modTime, mode), size, nil
+}
+
+// zipRawHeader 构造 CreateRaw 使用的文件头。CreateRaw 不像 CreateHeader 那样设置编码标志、
+// 版本和修改时间，这里按 CreateHeader 的方式设置；CRC 和大小在调用 CreateRaw 前填入，不使用数据描述符
+func zipRawHeader(name string, method uint16, modTime time.Time, mode os.FileMode) *zip.FileHeader {
+	fh := &zip.FileHeader{Name: name, Method: method}
+	for i := 0; i < len(name); i++ {
+		if name[i] >= 0x80 {
+			fh.Flags |= 0x800 // 文件名使用 UTF-8
+			break
+		}
+	}
+	fh.SetMode(mode)
+	fh.CreatorVersion = fh.CreatorVersion&0xff00 | 20
+	fh.ReaderVersion = 20
+
+	if !modTime.IsZero() {
+		// 旧的 MS-DOS 时间使用本地时间，另外写入 Info-ZIP 的扩展时间戳（UTC）
+		t := modTime.Local()
+		if t.Year() < 1980 {
+			t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.Local)
+		}
+		fh.ModifiedDate = uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+		fh.ModifiedTime = uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+		mt := uint32(modTime.Unix())
+		fh.Extra = []byte{0x55, 0x54, 5, 0, 1, byte(mt), byte(mt >> 8), byte(mt >> 16), byte(mt >> 24)}
+	}
+	return fh
+}
+
+// zipStage 暂存一个文件压缩后的数据，超过 zipStageMemory 时改为写入 dir 中的临时文件
+type zipStage struct {
+	dir string
+	buf bytes.Buffer
+	f   *os.File
+	n   int64 // 已暂存的字节数
+}
+
+func (s *zipStage) Write(p []byte) (int, error) {
+	if s.f == nil && int64(s.buf.Len()+len(p)) > zipStageMemory {
+		f, err := os.CreateTemp(s.dir, ".docradar-*.tmp")
+		if err != nil {
+			return 0, fmt.Errorf("无法创建临时文件: %w", err)
+		}
+		s.f = f
+		if _, err := f.Write(s.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		s.buf.Reset()
+	}
+	var n int
+	var err error
+	if s.f != nil {
+		n, err = s.f.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.n += int64(n)
+	return n, err
+}
+
+// copyTo 把暂存的数据写入 w
+func (s *zipStage) copyTo(w io.Writer) error {
+	if s.f == nil {
+		_, err := w.Write(s.buf.Bytes())
+		return err
+	}
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, s.f)
+	return err
+}
+
+// reset 清空暂存的数据，删除临时文件
+func (s *zipStage) reset() {
+	s.buf.Reset()
+	if s.f != nil {
+		s.f.Close()
+		os.Remove(s.f.Name())
+		s.f = nil
+	}
+	s.n = 0
+}
+
+// zipVolumes 按分卷大小依次写入的压缩包
+type zipVolumes struct {
+	dir   string
+	base  string
+	limit int64 // 每个分卷的最大字节数，0 表示不分卷
+
+	paths   []string
+	f       *os.File
+	cw      *countWriter
+	zw      *zip.Writer
+	entries int   // 当前分卷中的文件数
+	dirSize int64 // 当前分卷中央目录的估计大小
+}
+
+// countWriter 记录写入文件的字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// volumePath 返回第 n 个分卷（从 1 开始）的路径，不分卷或 n 为 0 时为不带序号的路径
+func (v *zipVolumes) volumePath(n int) string {
+	if v.limit == 0 || n == 0 {
+		return filepath.Join(v.dir, v.base+".zip")
+	}
+	return filepath.Join(v.dir, v.base+".part"+strconv.Itoa(n)+".zip")
+}
+
+// next 创建下一个分卷
+func (v *zipVolumes) next() error {
+	path := v.volumePath(len(v.paths) + 1)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("无法创建压缩文件: %w", err)
+	}
+	v.paths = append(v.paths, path)
+	v.f = f
+	v.cw = &countWriter{w: f}
+	v.zw = zip.NewWriter(v.cw)
+	v.entries, v.dirSize = 0, 0
+	return nil
+}
+
+// fits 压缩后大小为 n 的文件能否放入一个空的分卷
+func (v *zipVolumes) fits(nameLen int, n int64) bool {
+	return v.limit == 0 || int64(2*nameLen+zipEntryOverhead)+n+zipEndOverhead+zipFlushSlack <= v.limit
+}
+
+// reserve 为下一个文件（压缩后大小为 n）预留空间，当前分卷放不下时换到新的分卷。
+// 只有一个文件也超过分卷大小时返回 errZipEntryTooLarge
+func (v *zipVolumes) reserve(nameLen int, n int64) error {
+	if v.limit > 0 {
+		if !v.fits(nameLen, n) {
+			return errZipEntryTooLarge
+		}
+		need := int64(2*nameLen+zipEntryOverhead) + n
+		// 刷新 archive/zip 的缓冲区，使已写入的字节数准确
+		if err := v.zw.Flush(); err != nil {
+			return err
+		}
+		if v.entries > 0 && v.cw.n+v.dirSize+need+zipEndOverhead+zipFlushSlack > v.limit {
+			if err := v.closeCurrent(); err != nil {
+				return err
+			}
+			if err := v.next(); err != nil {
+				return err
+			}
+		}
+	}
+	v.entries++
+	v.dirSize += int64(nameLen + zipDirOverhead)
+	return nil
+}
+
+// closeCurrent 写入当前分卷的中央目录并关闭文件
+func (v *zipVolumes) closeCurrent() error {
+	err := v.zw.Close()
+	if err == nil {
+		err = v.f.Sync()
+	}
+	if cerr := v.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// close 关闭最后一个分卷
+func (v *zipVolumes) close() error {
+	return v.closeCurrent()
+}
+
+// abort 出错时关闭文件，不写入中央目录
+func (v *zipVolumes) abort() {
+	v.f.Close()
+}
+
+// remove 删除已创建的所有分卷
+func (v *zipVolumes) remove() {
+	for _, p := range v.paths {
+		os.Remove(p)
+	}
+}
+
+// finish 全部写入后调用，分卷时只有一个分卷则去掉 .part1 后缀
+func (v *zipVolumes) finish() error {
+	if v.limit > 0 && len(v.paths) == 1 {
+		single := v.volumePath(0)
+		if err := os.Rename(v.paths[0], single); err != nil {
+			return fmt.Errorf("无法重命名压缩文件: %w", err)
+		}
+		v.paths[0] = single
+	}
+	return nil
+}
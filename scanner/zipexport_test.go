@@ -0,0 +1,229 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noise 返回不可压缩的数据
+func noise(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+// zipSources 创建要打包的文件，返回扫描结果和每个文件的内容（按导出路径）
+func zipSources(t *testing.T, files map[string][]byte) ([]FileInfo, map[string][]byte) {
+	t.Helper()
+	src := t.TempDir()
+	var infos []FileInfo
+	for name, data := range files {
+		p := writeTestFile(t, src, name, data)
+		infos = append(infos, FileInfo{Path: p, Name: filepath.Base(p), Size: int64(len(data)), ModTime: time.Now(), Root: src})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos, files
+}
+
+// readZips 读取所有压缩包中的文件，archive/zip 在读到结尾时校验 CRC
+func readZips(t *testing.T, paths []string) map[string][]byte {
+	t.Helper()
+	got := map[string][]byte{}
+	for _, p := range paths {
+		zr, err := zip.OpenReader(p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		for _, f := range zr.File {
+			if f.Flags&0x8 != 0 {
+				t.Errorf("%s: %s uses a data descriptor", p, f.Name)
+			}
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Errorf("%s: %s: %v", p, f.Name, err)
+			}
+			if _, dup := got[f.Name]; dup {
+				t.Errorf("%s stored twice", f.Name)
+			}
+			got[f.Name] = data
+		}
+		zr.Close()
+	}
+	return got
+}
+
+func TestExportAsZipRoundTrip(t *testing.T) {
+	isolateConfig(t)
+	text := bytes.Repeat([]byte("合同变更记录 contract amendment\n"), 100_000) // 约 3.5MB，跨多个压缩块
+	files, want := zipSources(t, map[string][]byte{
+		"a.pdf":          testPDF(0),
+		"报告/b.csv":       text,
+		"c.docx":         noise(1, 3<<20+17),
+		"empty/d.xlsx":   nil,
+		"deep/x/y/e.ppt": noise(2, 1000),
+		"big.pptx":       noise(3, zipStageMemory+1), // 暂存时写入临时文件
+	})
+	dst := t.TempDir()
+
+	result, err := NewExporter().ExportAsZip(context.Background(), ExportOptions{DestPath: dst, Files: files, KeepStructure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != len(want) || result.Failed != 0 || len(result.ZipFiles) != 1 {
+		t.Fatalf("ExportAsZip = success %d, failed %v, zips %v", result.Success, result.FailedFiles, result.ZipFiles)
+	}
+	got := readZips(t, result.ZipFiles)
+	if len(got) != len(want) {
+		t.Errorf("zip has %d files, want %d", len(got), len(want))
+	}
+	for name, data := range want {
+		if !bytes.Equal(got[name], data) {
+			t.Errorf("%s: %d bytes in zip, want %d", name, len(got[name]), len(data))
+		}
+	}
+	if result.ManifestPath == "" {
+		t.Errorf("manifest not written: %s", result.ManifestErr)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dst, ".docradar-*")); len(tmp) != 0 {
+		t.Errorf("temporary files left: %v", tmp)
+	}
+}
+
+func TestExportAsZipVolumes(t *testing.T) {
+	isolateConfig(t)
+	files, want := zipSources(t, map[string][]byte{
+		"a.pdf":        noise(1, 400<<10),
+		"b.pdf":        noise(2, 400<<10),
+		"c.pdf":        noise(3, 400<<10),
+		"d.csv":        bytes.Repeat([]byte("1,2,3\n"), 500_000), // 3MB，压缩后可以放入分卷
+		"too-big.pdf":  noise(4, 2<<20),
+		"too-big.docx": noise(5, 2<<20),
+	})
+	dst := t.TempDir()
+
+	result, err := NewExporter().ExportAsZip(context.Background(), ExportOptions{DestPath: dst, Files: files, ZipVolumeSize: MinZipVolumeSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 4 || result.Failed != 2 {
+		t.Fatalf("ExportAsZip = success %d, failed %v; want 4 and the two large files", result.Success, result.FailedFiles)
+	}
+	// 每个分卷最多放下两个 400KB 的文件
+	if len(result.ZipFiles) != 2 {
+		t.Errorf("zips = %v, want 2 volumes", result.ZipFiles)
+	}
+	for i, p := range result.ZipFiles {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > MinZipVolumeSize {
+			t.Errorf("%s is %d bytes, larger than the volume size", p, info.Size())
+		}
+		if !strings.HasSuffix(p, ".part"+string(rune('1'+i))+".zip") {
+			t.Errorf("volume %d named %s", i+1, p)
+		}
+	}
+	got := readZips(t, result.ZipFiles)
+	for name, data := range want {
+		if strings.HasPrefix(name, "too-big") {
+			if _, ok := got[name]; ok {
+				t.Errorf("%s written although it does not fit", name)
+			}
+			continue
+		}
+		if !bytes.Equal(got[name], data) {
+			t.Errorf("%s: %d bytes in zip, want %d", name, len(got[name]), len(data))
+		}
+	}
+}
+
+// failingFS 读取 limit 字节后出错的文件系统
+type failingFS struct {
+	data  []byte
+	limit int
+}
+
+var errFlaky = errors.New("connection reset")
+
+func (f failingFS) Open(name string) (fs.File, error) {
+	return &failingFile{r: bytes.NewReader(f.data[:f.limit]), size: int64(len(f.data)), name: name}, nil
+}
+
+type failingFile struct {
+	r    *bytes.Reader
+	size int64
+	name string
+}
+
+func (f *failingFile) Stat() (fs.FileInfo, error) {
+	return fstestInfo{name: f.name, size: f.size}, nil
+}
+
+func (f *failingFile) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errFlaky
+	}
+	return n, err
+}
+
+func (f *failingFile) Close() error { return nil }
+
+type fstestInfo struct {
+	name string
+	size int64
+}
+
+func (i fstestInfo) Name() string       { return i.name }
+func (i fstestInfo) Size() int64        { return i.size }
+func (i fstestInfo) Mode() fs.FileMode  { return 0644 }
+func (i fstestInfo) ModTime() time.Time { return time.Time{} }
+func (i fstestInfo) IsDir() bool        { return false }
+func (i fstestInfo) Sys() any           { return nil }
+
+func TestExportAsZipReadErrorLeavesNoEntry(t *testing.T) {
+	isolateConfig(t)
+	data := bytes.Repeat([]byte("0123456789"), 500_000)
+	mount := &Mount{FS: failingFS{data: data, limit: 3 << 20}, Root: ".", Prefix: "flaky://host/"}
+	mount.register()
+	defer mount.release()
+
+	files, want := zipSources(t, map[string][]byte{"a.pdf": testPDF(0), "c.pdf": testPDF(10)})
+	flaky := FileInfo{Path: "flaky://host/b.pdf", Name: "b.pdf", Size: int64(len(data))}
+	files = []FileInfo{files[0], flaky, files[1]}
+	dst := t.TempDir()
+
+	result, err := NewExporter().ExportAsZip(context.Background(), ExportOptions{DestPath: dst, Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success != 2 || result.Failed != 1 || result.FailedFiles[0] != flaky.Path {
+		t.Fatalf("ExportAsZip = success %d, failed %v", result.Success, result.FailedFiles)
+	}
+	got := readZips(t, result.ZipFiles)
+	if _, ok := got["b.pdf"]; ok || len(got) != 2 {
+		t.Errorf("zip entries = %d (b.pdf present: %v), want only a.pdf and c.pdf", len(got), ok)
+	}
+	for name, data := range want {
+		if !bytes.Equal(got[name], data) {
+			t.Errorf("%s: content differs", name)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"doc-radar/scanner"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 最多保留的结果会话数，超出时丢弃最久未使用的会话
+const maxSessions = 4
+
+var errSessionNotFound = errors.New("扫描结果已过期，请重新扫描")
+
+// resultSession 保存在后端的一次扫描结果，前端只按页获取
+type resultSession struct {
+	mu       sync.Mutex
+	files    []scanner.FileInfo
+	lastUsed time.Time
+	release  func() // 释放扫描结果引用的远程挂载
+
+	// 最近一次过滤排序的结果（files 的下标），过滤条件不变时翻页直接复用
+	viewKey string
+	view    []int
+	viewLen int // 生成 view 时的文件数，扫描中有新文件时需要重新过滤
+}
+
+// sessionStore 结果会话
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*resultSession
+	nextID   int
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{sessions: make(map[string]*resultSession)}
+}
+
+// create 创建新的结果会话
+func (st *sessionStore) create() (string, *resultSession) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for len(st.sessions) >= maxSessions {
+		var oldestID string
+		var oldest time.Time
+		for id, sess := range st.sessions {
+			sess.mu.Lock()
+			used := sess.lastUsed
+			sess.mu.Unlock()
+			if oldestID == "" || used.Before(oldest) {
+				oldestID, oldest = id, used
+			}
+		}
+		st.sessions[oldestID].close()
+		delete(st.sessions, oldestID)
+	}
+
+	st.nextID++
+	id := strconv.FormatInt(time.Now().Unix(), 36) + "-" + strconv.Itoa(st.nextID)
+	sess := &resultSession{lastUsed: time.Now()}
+	st.sessions[id] = sess
+	return id, sess
+}
+
+// get 按 ID 查找结果会话
+func (st *sessionStore) get(id string) (*resultSession, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess, ok := st.sessions[id]
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	return sess, nil
+}
+
+// delete 丢弃结果会话并释放它引用的挂载
+func (st *sessionStore) delete(id string) {
+	st.mu.Lock()
+	sess, ok := st.sessions[id]
+	delete(st.sessions, id)
+	st.mu.Unlock()
+	if ok {
+		sess.close()
+	}
+}
+
+// append 追加扫描到的文件
+func (sess *resultSession) append(files []scanner.FileInfo) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.files = append(sess.files, files...)
+}
+
+// reset 用完整的扫描结果替换会话中的文件，会话被丢弃时释放结果引用的挂载
+func (sess *resultSession) reset(result *scanner.ScanResult) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.files = result.Files
+	sess.view = nil
+	sess.viewKey = ""
+	sess.release = result.Release
+}
+
+// close 丢弃会话时释放扫描结果引用的挂载
+func (sess *resultSession) close() {
+	sess.mu.Lock()
+	release := sess.release
+	sess.release = nil
+	sess.mu.Unlock()
+	if release != nil {
+		release()
+	}
+}
+
+// lookup 按 paths 的顺序取出会话中对应的文件，会话中没有的路径被忽略
+func (sess *resultSession) lookup(paths []string) []scanner.FileInfo {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.lastUsed = time.Now()
+
+	index := make(map[string]int, len(sess.files))
+	for i := range sess.files {
+		index[sess.files[i].Path] = i
+	}
+	files := make([]scanner.FileInfo, 0, len(paths))
+	for _, p := range paths {
+		if i, ok := index[p]; ok {
+			files = append(files, sess.files[i])
+		}
+	}
+	return files
+}
+
+// snapshot 返回会话中全部文件的副本
+func (sess *resultSession) snapshot() []scanner.FileInfo {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.lastUsed = time.Now()
+	return append([]scanner.FileInfo(nil), sess.files...)
+}
+
+// setLabels 用重新分类后的文件标签替换会话中同一路径文件的标签
+func (sess *resultSession) setLabels(files []scanner.FileInfo) {
+	labels := make(map[string][]string, len(files))
+	for _, f := range files {
+		labels[f.Path] = f.Labels
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	for i := range sess.files {
+		if l, ok := labels[sess.files[i].Path]; ok {
+			sess.files[i].Labels = l
+		}
+	}
+	// 标签变化后按标签过滤的结果不再有效
+	sess.view = nil
+	sess.viewKey = ""
+}
+
+// remove 从会话中移除指定路径的文件（文件已被隔离或删除）
+func (sess *resultSession) remove(paths []string) {
+	removed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		removed[p] = true
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	kept := make([]scanner.FileInfo, 0, len(sess.files))
+	for _, f := range sess.files {
+		if !removed[f.Path] {
+			kept = append(kept, f)
+		}
+	}
+	sess.files = kept
+	sess.view = nil
+	sess.viewKey = ""
+}
+
+// filter 返回符合过滤条件并排序后的文件下标，调用方需持有 sess.mu
+func (sess *resultSession) filter(filter FilterOptions) ([]int, error) {
+	sess.lastUsed = time.Now()
+
+	data, _ := json.Marshal(filter)
+	key := string(data)
+	if sess.view != nil && sess.viewKey == key && sess.viewLen == len(sess.files) {
+		return sess.view, nil
+	}
+
+	query, err := scanner.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+	matcher := scanner.NewNameMatcher(filter.SearchText, filter.SearchMode)
+	var scores []int
+	if matcher.Fuzzy() {
+		scores = make([]int, len(sess.files))
+	}
+	view := make([]int, 0, len(sess.files))
+	for i := range sess.files {
+		file := &sess.files[i]
+		if !matchFilter(file, filter) || !query.Match(file) {
+			continue
+		}
+		score := matcher.Score(file.Name)
+		if score == 0 {
+			continue
+		}
+		if scores != nil {
+			scores[i] = score
+		}
+		view = append(view, i)
+	}
+	if scores != nil && filter.SortBy == "" {
+		// 模糊搜索未指定排序时按匹配程度排序
+		scanner.SortByScore(view, scores)
+	} else {
+		sortView(sess.files, view, filter.SortBy, filter.SortDesc)
+	}
+
+	sess.view, sess.viewKey, sess.viewLen = view, key, len(sess.files)
+	return view, nil
+}
+
+// paths 返回符合过滤条件的全部文件路径
+func (sess *resultSession) paths(filter FilterOptions) ([]string, error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	view, err := sess.filter(filter)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(view))
+	for i, idx := range view {
+		paths[i] = sess.files[idx].Path
+	}
+	return paths, nil
+}
+
+// page 返回过滤结果中的一页，pageSize 不大于 0 时返回全部
+func (sess *resultSession) page(filter FilterOptions, page, pageSize int) (*FilterResult, error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	view, err := sess.filter(filter)
+	if err != nil {
+		return nil, err
+	}
+	start, end := 0, len(view)
+	if pageSize > 0 {
+		if page < 1 {
+			page = 1
+		}
+		start = (page - 1) * pageSize
+		if start > len(view) {
+			start = len(view)
+		}
+		if end = start + pageSize; end > len(view) {
+			end = len(view)
+		}
+	}
+
+	files := make([]scanner.FileInfo, 0, end-start)
+	for _, i := range view[start:end] {
+		files = append(files, sess.files[i])
+	}
+	return &FilterResult{
+		Files:      files,
+		TotalCount: len(view),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}